@@ -0,0 +1,236 @@
+// Package client is a typed client of the vehicles api, for the Go services that call it and for vehiclectl.
+// The module path app can not be fetched by the go command, so until the module is published under a public path
+// the other modules import the client with a replace directive, e.g. replace app => ../vehicles
+package client
+
+import (
+	"app/internal/tracing"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config is a struct that represents the configuration for Client
+type Config struct {
+	// BaseURL is the url of the api, e.g. http://localhost:8080
+	BaseURL string
+	// APIKey is sent in the header X-API-Key, if it is not empty
+	APIKey string
+	// Token is sent as a bearer token, if it is not empty and there is no APIKey
+	Token string
+	// HTTPClient is the client of the requests. By default a client with Timeout
+	HTTPClient *http.Client
+	// Timeout is the timeout of each attempt of a request. By default 10s
+	Timeout time.Duration
+	// MaxRetries is the number of retries of a failed request, a negative value disables them. By default 3.
+	// The reads are retried when they fail transiently. The writes are retried only when they did not reach the api,
+	// e.g. the connection was refused or the api answered 429 or 503
+	MaxRetries int
+	// RetryWait is the wait before the first retry, doubled on each retry. By default 100ms
+	RetryWait time.Duration
+}
+
+// New is a function that returns a new instance of Client
+func New(cfg Config) (c *Client, err error) {
+	base, err := url.Parse(strings.TrimSuffix(cfg.BaseURL, "/"))
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("client: invalid base url %q", cfg.BaseURL)
+	}
+
+	// default values
+	c = &Client{
+		base:       base,
+		apiKey:     cfg.APIKey,
+		token:      cfg.Token,
+		timeout:    10 * time.Second,
+		maxRetries: 3,
+		retryWait:  100 * time.Millisecond,
+	}
+	if cfg.Timeout > 0 {
+		c.timeout = cfg.Timeout
+	}
+	if cfg.MaxRetries != 0 {
+		c.maxRetries = max(cfg.MaxRetries, 0)
+	}
+	if cfg.RetryWait > 0 {
+		c.retryWait = cfg.RetryWait
+	}
+	c.http = cfg.HTTPClient
+	if c.http == nil {
+		c.http = &http.Client{}
+	}
+	return
+}
+
+// Client is a struct with typed methods to call the vehicles api
+type Client struct {
+	base       *url.URL
+	apiKey     string
+	token      string
+	http       *http.Client
+	timeout    time.Duration
+	maxRetries int
+	retryWait  time.Duration
+}
+
+// request is a request to the api
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   any
+	header http.Header
+}
+
+// safe returns true if the request only reads, so it can be retried even if it reached the api.
+// The writes are not, e.g. an update retried after it was applied would fail with a version mismatch
+func (r request) safe() bool {
+	return r.method == http.MethodGet || r.method == http.MethodHead
+}
+
+// do sends a request, retrying it when it fails transiently, and decodes the data of the response into out.
+// It returns the response of the last attempt. A write that failed after it may have reached the api
+// returns an error that wraps ErrUnknownOutcome
+func (c *Client) do(ctx context.Context, req request, out any) (res *http.Response, err error) {
+	// encode the body once, it is sent again on each retry
+	var body []byte
+	if req.body != nil {
+		if body, err = json.Marshal(req.body); err != nil {
+			return
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		var retryAfter time.Duration
+		res, retryAfter, err = c.attempt(ctx, req, body, out)
+		if err == nil {
+			return
+		}
+		if attempt >= c.maxRetries || !retryable(req, err) {
+			if !req.safe() && reached(err) {
+				err = fmt.Errorf("%w: %w", ErrUnknownOutcome, err)
+			}
+			return
+		}
+
+		// wait before the retry, the api may ask for a longer wait
+		wait := c.retryWait << attempt
+		wait += time.Duration(rand.Int63n(int64(wait)/2 + 1))
+		wait = max(wait, retryAfter)
+		select {
+		case <-ctx.Done():
+			return res, errors.Join(err, ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+}
+
+// attempt sends a request once
+func (c *Client) attempt(ctx context.Context, req request, body []byte, out any) (res *http.Response, retryAfter time.Duration, err error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	// request
+	u := *c.base
+	u.Path += req.path
+	u.RawQuery = req.query.Encode()
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	hr, err := http.NewRequestWithContext(ctx, req.method, u.String(), reader)
+	if err != nil {
+		return
+	}
+	for key, values := range req.header {
+		hr.Header[key] = values
+	}
+	hr.Header.Set("Accept", "application/json")
+	if body != nil {
+		hr.Header.Set("Content-Type", "application/json")
+	}
+	switch {
+	case c.apiKey != "":
+		hr.Header.Set("X-API-Key", c.apiKey)
+	case c.token != "":
+		hr.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	tracing.Inject(ctx, hr.Header)
+
+	// response
+	res, err = c.http.Do(hr)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		var errRes response[any]
+		json.NewDecoder(io.LimitReader(res.Body, 1<<16)).Decode(&errRes)
+		if seconds, convErr := strconv.Atoi(res.Header.Get("Retry-After")); convErr == nil {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		return res, retryAfter, &Error{StatusCode: res.StatusCode, Message: errRes.Message}
+	}
+
+	if out != nil && res.StatusCode != http.StatusNoContent {
+		data := response[any]{Data: out}
+		if err = json.NewDecoder(res.Body).Decode(&data); err != nil {
+			return res, 0, fmt.Errorf("client: invalid response: %w", err)
+		}
+	}
+	return
+}
+
+// retryable returns true if a failed attempt of a request can be retried
+func retryable(req request, err error) bool {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		// the request may have reached the api, unless the connection was not established
+		return req.safe() || !reached(err)
+	}
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		// e.g. an invalid response, it fails again
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		// rejected before being processed
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return req.safe()
+	}
+	return false
+}
+
+// reached returns true if the request may have reached the api despite the error of the attempt,
+// so its outcome is unknown: the connection failed after it was established, or a gateway gave up waiting
+func reached(err error) bool {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusBadGateway || apiErr.StatusCode == http.StatusGatewayTimeout
+	}
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return false
+	}
+	var opErr *net.OpError
+	return !(errors.As(err, &opErr) && opErr.Op == "dial")
+}
+
+// ifMatch returns the header If-Match of a version of a vehicle
+func ifMatch(version int) http.Header {
+	return http.Header{"If-Match": {strconv.Quote(strconv.Itoa(version))}}
+}
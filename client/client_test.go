@@ -0,0 +1,297 @@
+package client_test
+
+import (
+	"app/client"
+	"app/internal"
+	"app/internal/application"
+	"app/internal/audit"
+	"app/internal/auth"
+	"app/internal/health"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// newServer returns a server of the api with a small fleet, closed at the end of the test
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	db := map[int]internal.Vehicle{
+		1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{
+			Brand: "Ford", Model: "Fiesta", Registration: "AA100AA", Color: "Red", FabricationYear: 2010, Capacity: 5,
+			FuelType: "gasoline", Transmission: "manual", Weight: 1100,
+			Dimensions: internal.Dimensions{Length: 4.0, Width: 1.7, Height: 1.5},
+		}},
+		2: {Id: 2, VehicleAttributes: internal.VehicleAttributes{
+			Brand: "Ford", Model: "Ranger", Registration: "AA200AA", Color: "Black", FabricationYear: 2020, Capacity: 3,
+			FuelType: "diesel", Transmission: "manual", Weight: 2000,
+			Dimensions: internal.Dimensions{Length: 5.3, Width: 1.85, Height: 1.8},
+		}},
+		3: {Id: 3, VehicleAttributes: internal.VehicleAttributes{
+			Brand: "Citroën", Model: "C3", Registration: "AA300AA", Color: "Blue", FabricationYear: 2015, Capacity: 5,
+			FuelType: "diesel", Transmission: "automatic", Weight: 1200,
+			Dimensions: internal.Dimensions{Length: 3.9, Width: 1.75, Height: 1.5},
+		}},
+	}
+	sv := service.NewVehicleDefault(repository.NewVehicleMap(db, nil), audit.NewSinkMemory(), nil, nil)
+	st := health.NewStatus()
+	st.EndLoad(nil)
+	an, err := auth.NewAuthenticator(auth.ConfigAuthenticator{APIKeys: map[string]auth.Principal{
+		"reader-key": {Subject: "ana", Role: auth.RoleReader},
+		"editor-key": {Subject: "eva", Role: auth.RoleEditor},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(application.NewRouter(application.ConfigRouter{
+		Service:       sv,
+		Status:        st,
+		Authenticator: an,
+		Registry:      prometheus.NewRegistry(),
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newClient returns a client of the server with an api key
+func newClient(t *testing.T, baseURL, apiKey string) *client.Client {
+	t.Helper()
+	c, err := client.New(client.Config{BaseURL: baseURL, APIKey: apiKey, MaxRetries: 2, RetryWait: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestClient_Reads(t *testing.T) {
+	srv := newServer(t)
+	c := newClient(t, srv.URL, "reader-key")
+	ctx := context.Background()
+
+	// - listings
+	all, err := c.FindAll(ctx)
+	if err != nil || len(all) != 3 {
+		t.Fatalf("FindAll() = %d vehicles, %v, want 3", len(all), err)
+	}
+	fords, err := c.FindAllEqualTo(ctx, client.Filter{Brand: "ford", YearMin: ptr(2015)})
+	if err != nil || len(fords) != 1 || fords[0].Id != 2 {
+		t.Errorf("FindAllEqualTo() = %v, %v, want the vehicle 2", fords, err)
+	}
+	matching, err := c.FindAllMatching(ctx, "fuel_type=diesel and passengers>=5")
+	if err != nil || len(matching) != 1 || matching[0].Id != 3 {
+		t.Errorf("FindAllMatching() = %v, %v, want the vehicle 3", matching, err)
+	}
+	if _, err := c.FindAllMatching(ctx, "year>>2000"); !errors.Is(err, client.ErrBadRequest) {
+		t.Errorf("FindAllMatching() with an invalid expression error = %v, want %v", err, client.ErrBadRequest)
+	}
+
+	// - a vehicle
+	v, err := c.FindById(ctx, 1)
+	if err != nil || v.Model != "Fiesta" || v.FabricationYear != 2010 || v.Length != 4.0 || v.Version == 0 {
+		t.Errorf("FindById() = %+v, %v, want the Fiesta", v, err)
+	}
+	if _, err := c.FindById(ctx, 99); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("FindById() of a missing vehicle error = %v, want %v", err, client.ErrNotFound)
+	}
+
+	// - aggregates
+	avg, err := c.GetAvgCapacity(ctx, "Ford")
+	if err != nil || avg != 4 {
+		t.Errorf("GetAvgCapacity() = %v, %v, want 4", avg, err)
+	}
+	results, err := c.Search(ctx, "ranger", 5)
+	if err != nil || len(results) == 0 || results[0].Id != 2 {
+		t.Errorf("Search() = %v, %v, want the vehicle 2 first", results, err)
+	}
+}
+
+func TestClient_Writes(t *testing.T) {
+	srv := newServer(t)
+	c := newClient(t, srv.URL, "editor-key")
+	ctx := context.Background()
+
+	// - add
+	attrs := client.VehicleAttributes{
+		Brand: "Fiat", Model: "Uno", Registration: "AA400AA", Color: "White", FabricationYear: 2005, Capacity: 5,
+		FuelType: "gasoline", Transmission: "manual", Weight: 800, Height: 1.4, Length: 3.7, Width: 1.5,
+	}
+	added, err := c.Add(ctx, attrs)
+	if err != nil || added.Id == 0 || added.Brand != "Fiat" {
+		t.Fatalf("Add() = %+v, %v, want the new vehicle", added, err)
+	}
+	if _, err := c.Add(ctx, attrs); !errors.Is(err, client.ErrConflict) {
+		t.Errorf("Add() of an existent registration error = %v, want %v", err, client.ErrConflict)
+	}
+
+	// - update, with the version as If-Match
+	changed := added
+	changed.Color = "Black"
+	updated, err := c.Update(ctx, changed)
+	if err != nil || updated.Color != "Black" || updated.Version == added.Version {
+		t.Fatalf("Update() = %+v, %v, want a new version in black", updated, err)
+	}
+	if _, err := c.Update(ctx, changed); !errors.Is(err, client.ErrVersionMismatch) {
+		t.Errorf("Update() of a previous version error = %v, want %v", err, client.ErrVersionMismatch)
+	}
+
+	// - delete and restore
+	if err := c.Delete(ctx, added.Id, added.Version); !errors.Is(err, client.ErrVersionMismatch) {
+		t.Errorf("Delete() of a previous version error = %v, want %v", err, client.ErrVersionMismatch)
+	}
+	if err := c.Delete(ctx, updated.Id, updated.Version); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := c.FindById(ctx, updated.Id); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("FindById() of a deleted vehicle error = %v, want %v", err, client.ErrNotFound)
+	}
+	deleted, err := c.FindAllEqualTo(ctx, client.Filter{Brand: "Fiat", IncludeDeleted: true})
+	if err != nil || len(deleted) != 1 || deleted[0].DeletedAt == nil {
+		t.Fatalf("FindAllEqualTo() with the deleted = %v, %v, want the deleted vehicle", deleted, err)
+	}
+	restored, err := c.Restore(ctx, deleted[0].Id, deleted[0].Version)
+	if err != nil || restored.DeletedAt != nil {
+		t.Errorf("Restore() = %+v, %v, want the vehicle not deleted", restored, err)
+	}
+
+	// - the role of the credentials
+	reader := newClient(t, srv.URL, "reader-key")
+	if _, err := reader.Add(ctx, attrs); !errors.Is(err, client.ErrForbidden) {
+		t.Errorf("Add() as reader error = %v, want %v", err, client.ErrForbidden)
+	}
+	anonymous := newClient(t, srv.URL, "")
+	if _, err := anonymous.FindAll(ctx); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("FindAll() without credentials error = %v, want %v", err, client.ErrUnauthorized)
+	}
+}
+
+// drop is a status of the fake api that closes the connection without a response
+const drop = -1
+
+// newFakeAPI returns a server that answers each attempt with the next status, 200 once they run out,
+// and the number of attempts it received
+func newFakeAPI(t *testing.T, statuses ...int) (srv *httptest.Server, attempts *atomic.Int32) {
+	t.Helper()
+	attempts = &atomic.Int32{}
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(attempts.Add(1))
+		status := http.StatusOK
+		if n <= len(statuses) {
+			status = statuses[n-1]
+		}
+
+		if status == drop {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			conn.Close()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"message":"","data":{"id":1,"version":2,"brand":"Ford"}}`))
+	}))
+	t.Cleanup(srv.Close)
+	return
+}
+
+func TestClient_Retries(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		call     func(c *client.Client) error
+		// wantAttempts is the number of requests received by the api
+		wantAttempts int
+		wantErr      []error
+		// wantUnknown is true if the error must say that the outcome is unknown
+		wantUnknown bool
+	}{
+		// - reads are retried when they fail transiently
+		{name: "read after 503", statuses: []int{503}, call: findById, wantAttempts: 2},
+		{name: "read after 502 and 504", statuses: []int{502, 504}, call: findById, wantAttempts: 3},
+		{name: "read after a dropped connection", statuses: []int{drop}, call: findById, wantAttempts: 2},
+		{name: "read out of retries", statuses: []int{503, 503, 503}, call: findById, wantAttempts: 3, wantErr: []error{client.ErrUnavailable}},
+		{name: "read not found", statuses: []int{404}, call: findById, wantAttempts: 1, wantErr: []error{client.ErrNotFound}},
+
+		// - writes are retried only when they were rejected before being processed
+		{name: "update after 429", statuses: []int{429}, call: update, wantAttempts: 2},
+		{name: "restore after 503", statuses: []int{503}, call: restore, wantAttempts: 2},
+		{name: "update after 502", statuses: []int{502}, call: update, wantAttempts: 1, wantErr: []error{client.ErrUnavailable}, wantUnknown: true},
+		{name: "update after a dropped connection", statuses: []int{drop}, call: update, wantAttempts: 1, wantUnknown: true},
+		{name: "restore after 504", statuses: []int{504}, call: restore, wantAttempts: 1, wantErr: []error{client.ErrUnavailable}, wantUnknown: true},
+		{name: "restore after a dropped connection", statuses: []int{drop}, call: restore, wantAttempts: 1, wantUnknown: true},
+		{name: "add after a dropped connection", statuses: []int{drop}, call: add, wantAttempts: 1, wantUnknown: true},
+		{name: "delete after 500", statuses: []int{500}, call: remove, wantAttempts: 1, wantErr: []error{client.ErrServer}},
+		{name: "update version mismatch", statuses: []int{412}, call: update, wantAttempts: 1, wantErr: []error{client.ErrVersionMismatch}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, attempts := newFakeAPI(t, tt.statuses...)
+			err := tt.call(newClient(t, srv.URL, "key"))
+
+			if got := int(attempts.Load()); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+			if tt.wantErr == nil && !tt.wantUnknown && err != nil {
+				t.Errorf("error = %v, want nil", err)
+			}
+			for _, want := range tt.wantErr {
+				if !errors.Is(err, want) {
+					t.Errorf("error = %v, want %v", err, want)
+				}
+			}
+			if errors.Is(err, client.ErrUnknownOutcome) != tt.wantUnknown {
+				t.Errorf("error = %v, want unknown outcome %t", err, tt.wantUnknown)
+			}
+		})
+	}
+}
+
+func TestClient_Retries_ConnectionRefused(t *testing.T) {
+	// the connection is never established, so a write is retried and its outcome is known
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	_, err := newClient(t, srv.URL, "key").Add(context.Background(), client.VehicleAttributes{Brand: "Ford"})
+	if err == nil || errors.Is(err, client.ErrUnknownOutcome) {
+		t.Errorf("Add() error = %v, want a known failure", err)
+	}
+}
+
+func findById(c *client.Client) (err error) {
+	_, err = c.FindById(context.Background(), 1)
+	return
+}
+
+func add(c *client.Client) (err error) {
+	_, err = c.Add(context.Background(), client.VehicleAttributes{Brand: "Ford"})
+	return
+}
+
+func update(c *client.Client) (err error) {
+	_, err = c.Update(context.Background(), client.Vehicle{Id: 1, Version: 1})
+	return
+}
+
+func remove(c *client.Client) error {
+	return c.Delete(context.Background(), 1, 1)
+}
+
+func restore(c *client.Client) (err error) {
+	_, err = c.Restore(context.Background(), 1, 1)
+	return
+}
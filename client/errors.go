@@ -0,0 +1,72 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrBadRequest is returned when the api rejects the parameters or the body of a request
+	ErrBadRequest = errors.New("client: bad request")
+	// ErrUnauthorized is returned when the credentials are missing or invalid
+	ErrUnauthorized = errors.New("client: unauthorized")
+	// ErrForbidden is returned when the role of the credentials does not allow the operation
+	ErrForbidden = errors.New("client: forbidden")
+	// ErrNotFound is returned when the vehicle does not exist
+	ErrNotFound = errors.New("client: not found")
+	// ErrConflict is returned when the registration already exists or the state of the vehicle does not allow the operation
+	ErrConflict = errors.New("client: conflict")
+	// ErrVersionMismatch is returned when the vehicle was modified since the version sent
+	ErrVersionMismatch = errors.New("client: version mismatch")
+	// ErrTooLarge is returned when the body of a request is too large
+	ErrTooLarge = errors.New("client: request too large")
+	// ErrRateLimited is returned when the rate limit was exceeded, after the retries
+	ErrRateLimited = errors.New("client: rate limited")
+	// ErrUnavailable is returned when the api is not available, after the retries
+	ErrUnavailable = errors.New("client: unavailable")
+	// ErrServer is returned when the api fails
+	ErrServer = errors.New("client: server error")
+	// ErrUnknownOutcome is returned when a write failed after it may have reached the api, so it may have been applied.
+	// It is not retried, the caller can check the vehicle, e.g. with FindById, before trying again
+	ErrUnknownOutcome = errors.New("client: unknown outcome")
+)
+
+// Error is an error response of the api. It wraps the error of its status code, e.g. errors.Is(err, ErrNotFound)
+type Error struct {
+	// StatusCode is the status code of the response
+	StatusCode int
+	// Message is the message of the response
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("client: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Unwrap returns the error of the status code
+func (e *Error) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusBadRequest:
+		return ErrBadRequest
+	case e.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusForbidden:
+		return ErrForbidden
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusConflict:
+		return ErrConflict
+	case e.StatusCode == http.StatusPreconditionFailed, e.StatusCode == http.StatusPreconditionRequired:
+		return ErrVersionMismatch
+	case e.StatusCode == http.StatusRequestEntityTooLarge:
+		return ErrTooLarge
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode == http.StatusServiceUnavailable, e.StatusCode == http.StatusBadGateway, e.StatusCode == http.StatusGatewayTimeout:
+		return ErrUnavailable
+	case e.StatusCode >= 500:
+		return ErrServer
+	}
+	return nil
+}
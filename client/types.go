@@ -0,0 +1,69 @@
+package client
+
+import "time"
+
+// VehicleAttributes are the attributes of a vehicle that can be written
+type VehicleAttributes struct {
+	Brand           string  `json:"brand"`
+	Model           string  `json:"model"`
+	Registration    string  `json:"registration"`
+	Country         string  `json:"country,omitempty"`
+	Vin             string  `json:"vin,omitempty"`
+	Color           string  `json:"color"`
+	FabricationYear int     `json:"year"`
+	Capacity        int     `json:"passengers"`
+	MaxSpeed        float64 `json:"max_speed"`
+	FuelType        string  `json:"fuel_type"`
+	Transmission    string  `json:"transmission"`
+	Weight          float64 `json:"weight"`
+	Height          float64 `json:"height"`
+	Length          float64 `json:"length"`
+	Width           float64 `json:"width"`
+}
+
+// Vehicle is a vehicle stored by the api
+type Vehicle struct {
+	Id int `json:"id"`
+	// Version is the version of the vehicle, it must be sent back to update or delete it
+	Version int `json:"version"`
+	// DeletedAt is the time the vehicle was deleted, nil if it is not deleted
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	VehicleAttributes
+}
+
+// SearchResult is a vehicle found by a search with its relevance
+type SearchResult struct {
+	Vehicle
+	Score float64 `json:"score"`
+}
+
+// Filter is a filter of the vehicles by field. A nil or empty field means that the vehicles are not filtered by that field
+type Filter struct {
+	Brand        string
+	Model        string
+	Color        string
+	FuelType     string
+	Transmission string
+	// FabricationYear is the exact year of the vehicles
+	FabricationYear *int
+	// Capacity is the exact number of passengers of the vehicles
+	Capacity *int
+	// YearMin and YearMax bound the fabrication year, any of them can be nil
+	YearMin, YearMax *int
+	// WeightMin and WeightMax bound the weight, any of them can be nil
+	WeightMin, WeightMax *float64
+	// LengthMin and LengthMax bound the length, any of them can be nil
+	LengthMin, LengthMax *float64
+	// WidthMin and WidthMax bound the width, any of them can be nil
+	WidthMin, WidthMax *float64
+	// Fuzzy tolerates misspellings in the text fields
+	Fuzzy bool
+	// IncludeDeleted includes the deleted vehicles
+	IncludeDeleted bool
+}
+
+// response is the body of every response of the api
+type response[T any] struct {
+	Message string `json:"message"`
+	Data    T      `json:"data"`
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// FindAll returns all the vehicles, except the deleted ones
+func (c *Client) FindAll(ctx context.Context) (v []Vehicle, err error) {
+	_, err = c.do(ctx, request{method: http.MethodGet, path: "/vehicles"}, &v)
+	return
+}
+
+// FindAllEqualTo returns the vehicles that pass the filter
+func (c *Client) FindAllEqualTo(ctx context.Context, filter Filter) (v []Vehicle, err error) {
+	_, err = c.do(ctx, request{method: http.MethodGet, path: "/vehicles", query: filter.values()}, &v)
+	return
+}
+
+// FindAllMatching returns the vehicles that satisfy a filter expression, e.g. year>=2000 and (brand=Ford or brand=GMC)
+func (c *Client) FindAllMatching(ctx context.Context, expr string) (v []Vehicle, err error) {
+	query := url.Values{"filter": {expr}}
+	_, err = c.do(ctx, request{method: http.MethodGet, path: "/vehicles", query: query}, &v)
+	return
+}
+
+// FindById returns the vehicle with the given id
+func (c *Client) FindById(ctx context.Context, id int) (v Vehicle, err error) {
+	_, err = c.do(ctx, request{method: http.MethodGet, path: vehiclePath(id)}, &v)
+	return
+}
+
+// FindByVin returns the vehicle with the given VIN
+func (c *Client) FindByVin(ctx context.Context, vin string) (v Vehicle, err error) {
+	_, err = c.do(ctx, request{method: http.MethodGet, path: "/vehicles/vin/" + url.PathEscape(vin)}, &v)
+	return
+}
+
+// Add adds a new vehicle
+func (c *Client) Add(ctx context.Context, attrs VehicleAttributes) (v Vehicle, err error) {
+	_, err = c.do(ctx, request{method: http.MethodPost, path: "/vehicles", body: attrs}, &v)
+	return
+}
+
+// Update updates the attributes of a vehicle, if vehicle.Version is its current version
+func (c *Client) Update(ctx context.Context, vehicle Vehicle) (v Vehicle, err error) {
+	req := request{
+		method: http.MethodPut,
+		path:   vehiclePath(vehicle.Id),
+		body:   vehicle.VehicleAttributes,
+		header: ifMatch(vehicle.Version),
+	}
+	_, err = c.do(ctx, req, &v)
+	return
+}
+
+// Delete soft deletes a vehicle, if version is its current version
+func (c *Client) Delete(ctx context.Context, id int, version int) (err error) {
+	req := request{method: http.MethodDelete, path: vehiclePath(id), header: ifMatch(version)}
+	_, err = c.do(ctx, req, nil)
+	return
+}
+
+// Restore restores a deleted vehicle, if version is its current version
+func (c *Client) Restore(ctx context.Context, id int, version int) (v Vehicle, err error) {
+	req := request{method: http.MethodPost, path: vehiclePath(id) + "/restore", header: ifMatch(version)}
+	_, err = c.do(ctx, req, &v)
+	return
+}
+
+// Search returns up to limit vehicles that match the free text query, sorted by relevance. A limit of 0 has no limit
+func (c *Client) Search(ctx context.Context, query string, limit int) (results []SearchResult, err error) {
+	values := url.Values{"q": {query}}
+	if limit > 0 {
+		values.Set("limit", strconv.Itoa(limit))
+	}
+	_, err = c.do(ctx, request{method: http.MethodGet, path: "/vehicles/search", query: values}, &results)
+	return
+}
+
+// GetAvgCapacity returns the average number of passengers of the vehicles of a brand
+func (c *Client) GetAvgCapacity(ctx context.Context, brand string) (avg float64, err error) {
+	path := "/vehicles/average_capacity/brand/" + url.PathEscape(brand)
+	_, err = c.do(ctx, request{method: http.MethodGet, path: path}, &avg)
+	return
+}
+
+// vehiclePath returns the path of a vehicle
+func vehiclePath(id int) string {
+	return "/vehicles/" + strconv.Itoa(id)
+}
+
+// values returns the query params of the filter
+func (f Filter) values() url.Values {
+	values := url.Values{}
+	setString := func(key, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	setInt := func(key string, value *int) {
+		if value != nil {
+			values.Set(key, strconv.Itoa(*value))
+		}
+	}
+	setFloat := func(key string, value *float64) {
+		if value != nil {
+			values.Set(key, strconv.FormatFloat(*value, 'f', -1, 64))
+		}
+	}
+
+	setString("brand", f.Brand)
+	setString("model", f.Model)
+	setString("color", f.Color)
+	setString("fuel_type", f.FuelType)
+	setString("transmission", f.Transmission)
+	setInt("year", f.FabricationYear)
+	setInt("passengers", f.Capacity)
+	setInt("year_min", f.YearMin)
+	setInt("year_max", f.YearMax)
+	setFloat("weight_min", f.WeightMin)
+	setFloat("weight_max", f.WeightMax)
+	setFloat("length_min", f.LengthMin)
+	setFloat("length_max", f.LengthMax)
+	setFloat("width_min", f.WidthMin)
+	setFloat("width_max", f.WidthMax)
+	if f.Fuzzy {
+		values.Set("fuzzy", "true")
+	}
+	if f.IncludeDeleted {
+		values.Set("include_deleted", "true")
+	}
+	return values
+}
@@ -2,18 +2,57 @@ package main
 
 import (
 	"app/internal/application"
+	"app/internal/auth"
+	"app/internal/ratelimit"
+	"flag"
 	"fmt"
+	"os"
+	"time"
 )
 
 func main() {
+	// flags
+	// - insecure allows the requests without credentials, for development
+	insecure := flag.Bool("insecure", false, "allow the requests without credentials as admin, for development only")
+	flag.Parse()
+
 	// env
-	// ...
+	// - api keys with the format key:subject:role, separated by commas
+	apiKeys, err := auth.ParseAPIKeys(os.Getenv("VEHICLES_API_KEYS"))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	// - secret of the HS256 tokens
+	jwtSecret := []byte(os.Getenv("VEHICLES_JWT_SECRET"))
+	// - logs: level debug, info, warn or error and format text or json
+	logLevel := os.Getenv("VEHICLES_LOG_LEVEL")
+	logFormat := os.Getenv("VEHICLES_LOG_FORMAT")
+	// - url of the OTLP/HTTP receiver of the traces
+	tracingEndpoint := os.Getenv("VEHICLES_OTLP_ENDPOINT")
+	// - country of the registrations of the vehicles without one, e.g. AR
+	registrationCountry := os.Getenv("VEHICLES_REGISTRATION_COUNTRY")
 
 	// app
 	// - config
 	cfg := &application.ConfigServerChi{
-		ServerAddress: ":8080",
+		ServerAddress:  ":8080",
 		LoaderFilePath: "docs/db/vehicles_100.json",
+		CacheResponses: true,
+		// deleted vehicles are kept 90 days for finance
+		DeletedRetention: 90 * 24 * time.Hour,
+		APIKeys:          apiKeys,
+		JWTSecret:        jwtSecret,
+		Insecure:         *insecure,
+		// requests per second of each client
+		ReadLimit:  ratelimit.Limit{Rate: 20, Burst: 40},
+		WriteLimit: ratelimit.Limit{Rate: 2, Burst: 10},
+		LogLevel:   logLevel,
+		LogFormat:  logFormat,
+		// traces
+		TracingEndpoint: tracingEndpoint,
+		// registrations
+		RegistrationCountry: registrationCountry,
 	}
 	app := application.NewServerChi(cfg)
 	// - run
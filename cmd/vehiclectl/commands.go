@@ -0,0 +1,299 @@
+package main
+
+import (
+	"app/client"
+	"app/internal"
+	"app/internal/loader"
+	"app/internal/vin"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// env is the environment of a command
+type env struct {
+	// st is the store of the vehicles
+	st store
+	// rv validates the registrations by country
+	rv internal.RegistrationValidator
+	// format is the output format
+	format string
+	// stdin, stdout and stderr are the standard streams
+	stdin          io.Reader
+	stdout, stderr io.Writer
+}
+
+// command runs a subcommand with its arguments
+type command func(ctx context.Context, e *env, args []string) (err error)
+
+// commands are the subcommands by name
+var commands = map[string]command{
+	"list":   list,
+	"get":    get,
+	"add":    add,
+	"update": update,
+	"delete": remove,
+	"import": importFile,
+	"export": export,
+}
+
+// tools are the subcommands by name that work on files, without the store
+var tools = map[string]command{
+	"lint":     lintFile,
+	"generate": generate,
+}
+
+// flags returns the flag set of a subcommand
+func (e *env) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	return fs
+}
+
+// parse parses the flags of a subcommand, that can be before or after its nargs positional arguments
+func (e *env) parse(fs *flag.FlagSet, args []string, nargs int) (positional []string, err error) {
+	for {
+		if err = fs.Parse(args); err != nil {
+			if !errors.Is(err, flag.ErrHelp) {
+				err = fmt.Errorf("%w: %s", ErrUsage, err)
+			}
+			return
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) != nargs {
+		return nil, fmt.Errorf("%w: %s expects %d arguments", ErrUsage, fs.Name(), nargs)
+	}
+	return
+}
+
+// readData reads the data argument, inline JSON, @PATH or - for the standard input
+func (e *env) readData(data string) (b []byte, err error) {
+	switch {
+	case data == "":
+		return nil, fmt.Errorf("%w: -data is required", ErrUsage)
+	case data == "-":
+		return io.ReadAll(e.stdin)
+	case strings.HasPrefix(data, "@"):
+		return os.ReadFile(data[1:])
+	default:
+		return []byte(data), nil
+	}
+}
+
+// list lists the vehicles
+func list(ctx context.Context, e *env, args []string) (err error) {
+	fs := e.flags("list")
+	expr := fs.String("filter", "", "filter expression, e.g. year>=2000 and (brand=Ford or brand=GMC)")
+	if _, err = e.parse(fs, args, 0); err != nil {
+		return
+	}
+
+	vehicles, err := e.st.List(ctx, *expr)
+	if err != nil {
+		return
+	}
+	return writeVehicles(e.stdout, e.format, vehicles)
+}
+
+// get shows a vehicle, by its id or by its VIN if the argument has the length of a VIN
+func get(ctx context.Context, e *env, args []string) (err error) {
+	positional, err := e.parse(e.flags("get"), args, 1)
+	if err != nil {
+		return
+	}
+
+	var v internal.Vehicle
+	if number := vin.Normalize(positional[0]); len(number) == vin.Length {
+		v, err = e.st.GetByVin(ctx, number)
+	} else {
+		var id int
+		if id, err = parseId(positional[0]); err != nil {
+			return
+		}
+		v, err = e.st.Get(ctx, id)
+	}
+	if err != nil {
+		return
+	}
+	return writeVehicles(e.stdout, e.format, []internal.Vehicle{v})
+}
+
+// add adds a vehicle from its attributes in JSON
+func add(ctx context.Context, e *env, args []string) (err error) {
+	fs := e.flags("add")
+	data := fs.String("data", "", "attributes of the vehicle in JSON, @PATH or -")
+	if _, err = e.parse(fs, args, 0); err != nil {
+		return
+	}
+
+	// attributes
+	b, err := e.readData(*data)
+	if err != nil {
+		return
+	}
+	var attrs client.Vehicle
+	if err = decodeAttributes(b, &attrs.VehicleAttributes); err != nil {
+		return
+	}
+
+	v, err := e.st.Add(ctx, vehicleFromClient(attrs))
+	if err != nil {
+		return
+	}
+	return writeVehicles(e.stdout, e.format, []internal.Vehicle{v})
+}
+
+// update updates the attributes of a vehicle given in JSON, the others keep their values
+func update(ctx context.Context, e *env, args []string) (err error) {
+	fs := e.flags("update")
+	data := fs.String("data", "", "attributes to update in JSON, @PATH or -")
+	version := fs.Int("version", 0, "expected version of the vehicle, by default its current version")
+	positional, err := e.parse(fs, args, 1)
+	if err != nil {
+		return
+	}
+	id, err := parseId(positional[0])
+	if err != nil {
+		return
+	}
+	b, err := e.readData(*data)
+	if err != nil {
+		return
+	}
+
+	// merge the attributes over the current ones
+	current, err := e.st.Get(ctx, id)
+	if err != nil {
+		return
+	}
+	vehicle := vehicleToClient(current)
+	if err = decodeAttributes(b, &vehicle.VehicleAttributes); err != nil {
+		return
+	}
+	if *version != 0 {
+		vehicle.Version = *version
+	}
+
+	v, err := e.st.Update(ctx, vehicleFromClient(vehicle))
+	if err != nil {
+		return
+	}
+	return writeVehicles(e.stdout, e.format, []internal.Vehicle{v})
+}
+
+// remove deletes a vehicle
+func remove(ctx context.Context, e *env, args []string) (err error) {
+	fs := e.flags("delete")
+	version := fs.Int("version", 0, "expected version of the vehicle, by default its current version")
+	positional, err := e.parse(fs, args, 1)
+	if err != nil {
+		return
+	}
+	id, err := parseId(positional[0])
+	if err != nil {
+		return
+	}
+
+	if *version == 0 {
+		var current internal.Vehicle
+		if current, err = e.st.Get(ctx, id); err != nil {
+			return
+		}
+		*version = current.Version
+	}
+	if err = e.st.Delete(ctx, id, *version); err != nil {
+		return
+	}
+	fmt.Fprintf(e.stdout, "vehicle %d deleted\n", id)
+	return
+}
+
+// importFile adds the vehicles of a data file, reporting the ones that could not be added
+func importFile(ctx context.Context, e *env, args []string) (err error) {
+	positional, err := e.parse(e.flags("import"), args, 1)
+	if err != nil {
+		return
+	}
+
+	db, err := loader.NewVehicleFile(positional[0]).Load()
+	if err != nil {
+		return
+	}
+
+	// add the vehicles in the order of the file
+	imported, failed := 0, 0
+	for _, vh := range sortedVehicles(db) {
+		source := vh.Id
+		if _, err = e.st.Add(ctx, vh); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			fmt.Fprintf(e.stderr, "vehicle %d (registration %q): %s\n", source, vh.Registration, err)
+			failed++
+			continue
+		}
+		imported++
+	}
+	fmt.Fprintf(e.stdout, "%d vehicles imported, %d failed\n", imported, failed)
+
+	if failed > 0 {
+		return fmt.Errorf("%d vehicles could not be imported", failed)
+	}
+	return nil
+}
+
+// export writes the vehicles in a format the loaders accept
+func export(ctx context.Context, e *env, args []string) (err error) {
+	fs := e.flags("export")
+	out := fs.String("out", "", "output file, by default the standard output")
+	if _, err = e.parse(fs, args, 0); err != nil {
+		return
+	}
+
+	// format, by the extension of the file or the output format
+	format := e.format
+	if *out != "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*out)), ".")
+	}
+	if format == "table" {
+		format = "json"
+	}
+
+	vehicles, err := e.st.List(ctx, "")
+	if err != nil {
+		return
+	}
+
+	// write to a buffer so a failed export does not leave the file truncated
+	var buf bytes.Buffer
+	if err = writeExport(&buf, format, vehicles); err != nil {
+		return
+	}
+	if *out == "" {
+		_, err = buf.WriteTo(e.stdout)
+		return
+	}
+	return os.WriteFile(*out, buf.Bytes(), 0o644)
+}
+
+// decodeAttributes decodes attributes in JSON over the ones of attrs, rejecting the unknown fields
+func decodeAttributes(b []byte, attrs *client.VehicleAttributes) (err error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(attrs); err != nil {
+		return fmt.Errorf("invalid vehicle data: %w", err)
+	}
+	return
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"app/internal/generator"
+	"app/internal/loader"
+	"context"
+	"fmt"
+)
+
+// generate writes a synthetic fleet, the same for the same seed
+func generate(ctx context.Context, e *env, args []string) (err error) {
+	fs := e.flags("generate")
+	n := fs.Int("n", 1000, "number of vehicles")
+	seed := fs.Int64("seed", 1, "seed of the random values")
+	out := fs.String("out", "", "output file, JSON or CSV by its extension, by default the standard output in the -o format")
+	if _, err = e.parse(fs, args, 0); err != nil {
+		return
+	}
+	if *n < 0 {
+		return fmt.Errorf("%w: -n must not be negative", ErrUsage)
+	}
+
+	v := generator.NewGenerator(*seed).Generate(*n)
+	if *out != "" {
+		return loader.NewVehicleFile(*out).Save(v)
+	}
+
+	format := e.format
+	if format == "table" {
+		format = "json"
+	}
+	return writeExport(e.stdout, format, sortedVehicles(v))
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"app/internal/lint"
+	"app/internal/loader"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+// lintFile checks a data file and optionally writes it repaired
+func lintFile(ctx context.Context, e *env, args []string) (err error) {
+	fs := e.flags("lint")
+	repair := fs.String("repair", "", "writes the repaired vehicles to this file, JSON or CSV by its extension")
+	drop := fs.Bool("drop-duplicates", false, "removes the records with a duplicated registration instead of renaming it")
+	positional, err := e.parse(fs, args, 1)
+	if err != nil {
+		return
+	}
+
+	records, err := loader.ReadVehicleRecords(positional[0])
+	if err != nil {
+		return
+	}
+
+	// report
+	problems := lint.Check(records, e.rv)
+	if err = writeProblems(e.stdout, e.format, problems); err != nil {
+		return
+	}
+	errs, warnings := lint.Count(problems)
+	fmt.Fprintf(e.stderr, "%d records, %d errors, %d warnings\n", len(records), errs, warnings)
+
+	if *repair == "" {
+		if errs > 0 {
+			return fmt.Errorf("%s has %d errors", positional[0], errs)
+		}
+		return
+	}
+
+	// repair
+	v, changes := lint.Repair(records, lint.Options{DropDuplicates: *drop})
+	for _, c := range changes {
+		fmt.Fprintf(e.stderr, "record %d (id %d): %s\n", c.Position, c.Id, c.Message)
+	}
+	if err = loader.NewVehicleFile(*repair).Save(v); err != nil {
+		return
+	}
+	fmt.Fprintf(e.stderr, "%d changes, %d vehicles written to %s\n", len(changes), len(v), *repair)
+	return
+}
+
+// writeProblems writes the problems of a file in the format table, json or csv
+func writeProblems(w io.Writer, format string, problems []lint.Problem) (err error) {
+	switch format {
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "RECORD\tID\tFIELD\tSEVERITY\tPROBLEM")
+		for _, p := range problems {
+			fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%s\n", p.Position, p.Id, p.Field, p.Severity, p.Message)
+		}
+		return tw.Flush()
+	case "json":
+		if problems == nil {
+			problems = []lint.Problem{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(problems)
+	case "csv":
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"record", "id", "field", "severity", "problem"})
+		for _, p := range problems {
+			writer.Write([]string{strconv.Itoa(p.Position), strconv.Itoa(p.Id), p.Field, string(p.Severity), p.Message})
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		return ErrUnknownFormat
+	}
+}
@@ -0,0 +1,124 @@
+// vehiclectl is the admin tool of the vehicle store. It works on a running server through its api,
+// or directly on a data file with the same validations as the server.
+//
+// Usage:
+//
+//	vehiclectl [-server URL | -file PATH] [-o table|json|csv] [-country CODE] COMMAND [ARGS]
+//
+// Commands:
+//
+//	list [-filter EXPR]           lists the vehicles, e.g. -filter 'year>=2000 and brand=Ford'
+//	get ID|VIN                    shows a vehicle, by its id or its VIN
+//	add -data JSON                adds a vehicle
+//	update ID -data JSON          updates the given attributes of a vehicle
+//	delete ID                     deletes a vehicle
+//	import PATH                   adds the vehicles of a JSON or CSV file, with new ids
+//	export [-out PATH]            writes the vehicles in JSON or CSV, by the extension of PATH or -o
+//
+// Tools, that work on files without -server nor -file:
+//
+//	lint [-repair OUT] PATH       checks a JSON or CSV file with the rules of the service, and repairs it to OUT
+//	generate [-n N] [-seed S]     writes N plausible vehicles, the same for the same seed
+//
+// JSON can be @PATH to read it from a file or - to read it from the standard input.
+package main
+
+import (
+	"app/client"
+	"app/internal/registration"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+)
+
+var (
+	// ErrUsage is returned when the command line is invalid
+	ErrUsage = errors.New("invalid usage")
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		return
+	case errors.Is(err, ErrUsage):
+		fmt.Fprintln(os.Stderr, "vehiclectl:", err)
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "vehiclectl:", err)
+		os.Exit(1)
+	}
+}
+
+// run parses the global flags, opens the store and runs the command
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) (err error) {
+	// flags
+	fs := flag.NewFlagSet("vehiclectl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	server := fs.String("server", os.Getenv("VEHICLES_URL"), "url of the server, by default $VEHICLES_URL")
+	file := fs.String("file", "", "data file in JSON or CSV, instead of a server")
+	apiKey := fs.String("api-key", os.Getenv("VEHICLES_API_KEY"), "api key of the server, by default $VEHICLES_API_KEY")
+	token := fs.String("token", os.Getenv("VEHICLES_TOKEN"), "bearer token of the server, by default $VEHICLES_TOKEN")
+	format := fs.String("o", "table", "output format: table, json or csv")
+	country := fs.String("country", os.Getenv("VEHICLES_REGISTRATION_COUNTRY"),
+		"country of the registrations of the vehicles without one with -file and lint, by default $VEHICLES_REGISTRATION_COUNTRY")
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("%w: missing command", ErrUsage)
+	}
+
+	// command, the tools do not use the store
+	name, args := fs.Arg(0), fs.Args()[1:]
+	rv, err := registration.NewValidator(registration.ConfigValidator{DefaultCountry: *country})
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrUsage, err)
+	}
+	env := &env{rv: rv, format: *format, stdin: stdin, stdout: stdout, stderr: stderr}
+	if tool, ok := tools[name]; ok {
+		return tool(ctx, env, args)
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		return fmt.Errorf("%w: unknown command %s", ErrUsage, name)
+	}
+
+	// store
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	var st store
+	switch {
+	case set["server"] && set["file"]:
+		return fmt.Errorf("%w: -server and -file are exclusive", ErrUsage)
+	case *file != "":
+		if st, err = NewStoreFile(*file, rv); err != nil {
+			return
+		}
+	case *server != "":
+		var cl *client.Client
+		cl, err = client.New(client.Config{BaseURL: *server, APIKey: *apiKey, Token: *token})
+		if err != nil {
+			return
+		}
+		st = NewStoreHTTP(cl)
+	default:
+		return fmt.Errorf("%w: one of -server or -file is required", ErrUsage)
+	}
+
+	// run the command, saving the changes even if it failed halfway, e.g. an import
+	env.st = st
+	err = cmd(ctx, env, args)
+	if closeErr := st.Close(); err == nil {
+		err = closeErr
+	}
+	return
+}
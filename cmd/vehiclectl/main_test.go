@@ -0,0 +1,326 @@
+package main
+
+import (
+	"app/client"
+	"app/internal/application"
+	"app/internal/audit"
+	"app/internal/auth"
+	"app/internal/health"
+	"app/internal/lint"
+	"app/internal/loader"
+	"app/internal/repository"
+	"app/internal/service"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// fleetJSON is a data file with a small fleet
+const fleetJSON = `[
+{"id":1,"brand":"Ford","model":"Fiesta","registration":"AA100AA","color":"Red","year":2010,"passengers":5,"max_speed":170,"fuel_type":"gasoline","transmission":"manual","weight":1100,"height":1.5,"length":4,"width":1.7},
+{"id":2,"brand":"Ford","model":"Ranger","registration":"AA200AA","color":"Black","year":2020,"passengers":3,"max_speed":180,"fuel_type":"diesel","transmission":"manual","weight":2000,"height":1.8,"length":5.3,"width":1.85},
+{"id":3,"brand":"Honda","model":"Accord","registration":"AA300AA","vin":"1HGCM82633A004352","color":"Blue","year":2003,"passengers":5,"max_speed":200,"fuel_type":"gasoline","transmission":"automatic","weight":1400,"height":1.45,"length":4.8,"width":1.8}
+]`
+
+// newVehicle are the attributes of a vehicle that is not in the fleet
+const newVehicle = `{"brand":"Fiat","model":"Uno","registration":"AA400AA","color":"White","year":2005,"passengers":5,` +
+	`"max_speed":150,"fuel_type":"gasoline","transmission":"manual","weight":800,"height":1.4,"length":3.7,"width":1.5}`
+
+// writeFile writes a file in a temporary directory of the test and returns its path
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// execute runs vehiclectl with the arguments and the standard input, without the environment of the process
+func execute(t *testing.T, stdin string, args ...string) (stdout, stderr string, err error) {
+	t.Helper()
+	for _, name := range []string{"VEHICLES_URL", "VEHICLES_API_KEY", "VEHICLES_TOKEN", "VEHICLES_REGISTRATION_COUNTRY"} {
+		t.Setenv(name, "")
+	}
+
+	var out, errOut bytes.Buffer
+	err = run(context.Background(), args, strings.NewReader(stdin), &out, &errOut)
+	return out.String(), errOut.String(), err
+}
+
+// decodeVehicles decodes the output of a command with -o json
+func decodeVehicles(t *testing.T, stdout string) (v []client.Vehicle) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(stdout), &v); err != nil {
+		t.Fatalf("invalid output %q: %v", stdout, err)
+	}
+	return
+}
+
+// newServer returns a server of the api with the vehicles of a data file and an editor api key
+func newServer(t *testing.T, path string) *httptest.Server {
+	t.Helper()
+	db, err := loader.NewVehicleFile(path).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sv := service.NewVehicleDefault(repository.NewVehicleMap(db, nil), audit.NewSinkMemory(), nil, nil)
+	st := health.NewStatus()
+	st.EndLoad(nil)
+	an, err := auth.NewAuthenticator(auth.ConfigAuthenticator{APIKeys: map[string]auth.Principal{
+		"editor-key": {Subject: "eva", Role: auth.RoleEditor},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(application.NewRouter(application.ConfigRouter{
+		Service:       sv,
+		Status:        st,
+		Authenticator: an,
+		Registry:      prometheus.NewRegistry(),
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRun_Usage(t *testing.T) {
+	path := writeFile(t, "vehicles.json", fleetJSON)
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr error
+	}{
+		{name: "missing command", args: nil, wantErr: ErrUsage},
+		{name: "unknown command", args: []string{"-file", path, "frobnicate"}, wantErr: ErrUsage},
+		{name: "missing store", args: []string{"list"}, wantErr: ErrUsage},
+		{name: "server and file", args: []string{"-server", "http://localhost:8080", "-file", path, "list"}, wantErr: ErrUsage},
+		{name: "missing argument", args: []string{"-file", path, "get"}, wantErr: ErrUsage},
+		{name: "extra argument", args: []string{"-file", path, "list", "ford"}, wantErr: ErrUsage},
+		{name: "missing data", args: []string{"-file", path, "add"}, wantErr: ErrUsage},
+		{name: "unknown country", args: []string{"-country", "XX", "lint", path}, wantErr: ErrUsage},
+		{name: "negative number of vehicles", args: []string{"generate", "-n", "-1"}, wantErr: ErrUsage},
+		{name: "unknown format", args: []string{"-file", path, "-o", "yaml", "list"}, wantErr: ErrUnknownFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := execute(t, "", tt.args...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("run() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRun_Stores(t *testing.T) {
+	// the commands give the same results on a server and on a data file
+	stores := []struct {
+		name string
+		// args are the global flags of the store
+		args func(t *testing.T) []string
+	}{
+		{name: "file", args: func(t *testing.T) []string {
+			return []string{"-file", writeFile(t, "vehicles.json", fleetJSON)}
+		}},
+		{name: "server", args: func(t *testing.T) []string {
+			srv := newServer(t, writeFile(t, "vehicles.json", fleetJSON))
+			return []string{"-server", srv.URL, "-api-key", "editor-key"}
+		}},
+	}
+
+	for _, store := range stores {
+		t.Run(store.name, func(t *testing.T) {
+			global := append(store.args(t), "-o", "json")
+			cmd := func(stdin string, args ...string) (stdout string, err error) {
+				stdout, _, err = execute(t, stdin, append(append([]string{}, global...), args...)...)
+				return
+			}
+
+			// - reads
+			out, err := cmd("", "list")
+			if v := decodeVehicles(t, out); err != nil || len(v) != 3 {
+				t.Fatalf("list = %d vehicles, %v, want 3", len(v), err)
+			}
+			out, err = cmd("", "list", "-filter", "brand=Ford and year>=2015")
+			if v := decodeVehicles(t, out); err != nil || len(v) != 1 || v[0].Id != 2 {
+				t.Errorf("list -filter = %v, %v, want the vehicle 2", v, err)
+			}
+			out, err = cmd("", "get", "1")
+			if v := decodeVehicles(t, out); err != nil || len(v) != 1 || v[0].Model != "Fiesta" {
+				t.Errorf("get 1 = %v, %v, want the Fiesta", v, err)
+			}
+			out, err = cmd("", "get", "1hgcm82633a004352")
+			if v := decodeVehicles(t, out); err != nil || len(v) != 1 || v[0].Id != 3 {
+				t.Errorf("get VIN = %v, %v, want the vehicle 3", v, err)
+			}
+			if _, err = cmd("", "get", "99"); err == nil {
+				t.Error("get 99 = nil, want an error")
+			}
+
+			// - writes
+			out, err = cmd(newVehicle, "add", "-data", "-")
+			if v := decodeVehicles(t, out); err != nil || len(v) != 1 || v[0].Id != 4 || v[0].Model != "Uno" {
+				t.Fatalf("add = %v, %v, want the Uno with id 4", v, err)
+			}
+			if _, err = cmd(newVehicle, "add", "-data", "-"); err == nil {
+				t.Error("add of a duplicated registration = nil, want an error")
+			}
+			out, err = cmd("", "update", "1", "-data", `{"color":"Green"}`)
+			if v := decodeVehicles(t, out); err != nil || len(v) != 1 || v[0].Color != "Green" || v[0].Model != "Fiesta" || v[0].Version != 2 {
+				t.Errorf("update = %v, %v, want the green Fiesta at version 2", v, err)
+			}
+			if _, err = cmd("", "update", "1", "-version", "5", "-data", `{"color":"White"}`); err == nil {
+				t.Error("update of another version = nil, want an error")
+			}
+			if _, err = cmd("", "update", "1", "-data", `{"colour":"White"}`); err == nil {
+				t.Error("update of an unknown attribute = nil, want an error")
+			}
+			out, err = cmd("", "delete", "2")
+			if err != nil || out != "vehicle 2 deleted\n" {
+				t.Errorf("delete = %q, %v", out, err)
+			}
+
+			// - the changes are kept between commands
+			out, err = cmd("", "list")
+			v := decodeVehicles(t, out)
+			if err != nil || len(v) != 3 || v[0].Id != 1 || v[0].Color != "Green" || v[1].Id != 3 || v[2].Id != 4 {
+				t.Errorf("list = %v, %v, want the vehicles 1 (green), 3 and 4", v, err)
+			}
+		})
+	}
+}
+
+func TestRun_Table(t *testing.T) {
+	path := writeFile(t, "vehicles.json", fleetJSON)
+
+	out, _, err := execute(t, "", "-file", path, "list", "-filter", "brand=Honda")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "ID") || !strings.Contains(lines[1], "Accord") {
+		t.Errorf("list = %q, want a header and the Accord", out)
+	}
+}
+
+func TestRun_ImportExport(t *testing.T) {
+	path := writeFile(t, "vehicles.json", fleetJSON)
+	exported := filepath.Join(t.TempDir(), "vehicles.csv")
+
+	// - export to CSV, by the extension of the file
+	if _, _, err := execute(t, "", "-file", path, "export", "-out", exported); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(exported)
+	if err != nil || !strings.HasPrefix(string(b), "id,") {
+		t.Fatalf("exported file = %q, %v, want a CSV", b, err)
+	}
+
+	// - import into an empty file, with the same attributes
+	empty := writeFile(t, "empty.json", "[]")
+	out, _, err := execute(t, "", "-file", empty, "import", exported)
+	if err != nil || out != "3 vehicles imported, 0 failed\n" {
+		t.Fatalf("import = %q, %v", out, err)
+	}
+	want, err := loader.NewVehicleFile(path).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := loader.NewVehicleFile(empty).Load()
+	if err != nil || len(got) != len(want) {
+		t.Fatalf("imported = %d vehicles, %v, want %d", len(got), err, len(want))
+	}
+	for id, vh := range want {
+		if got[id].VehicleAttributes != vh.VehicleAttributes {
+			t.Errorf("imported vehicle %d = %+v, want %+v", id, got[id].VehicleAttributes, vh.VehicleAttributes)
+		}
+	}
+
+	// - import into the original file, every registration is duplicated
+	out, stderr, err := execute(t, "", "-file", path, "import", exported)
+	if err == nil || out != "0 vehicles imported, 3 failed\n" || strings.Count(stderr, "\n") != 3 {
+		t.Errorf("import of duplicates = %q, %q, %v, want 3 failures", out, stderr, err)
+	}
+}
+
+func TestRun_Lint(t *testing.T) {
+	// the second record repeats the registration of the first one, has a color in lowercase and no length
+	path := writeFile(t, "vehicles.json", `[
+{"id":1,"brand":"Ford","model":"Fiesta","registration":"AA100AA","color":"Red","year":2010,"passengers":5,"max_speed":170,"fuel_type":"gasoline","transmission":"manual","weight":1100,"height":1.5,"length":4,"width":1.7},
+{"id":2,"brand":"Ford","model":"Ranger","registration":"aa 100 aa","color":"black","year":2020,"passengers":3,"max_speed":180,"fuel_type":"diesel","transmission":"manual","weight":2000,"height":1.8,"width":1.85}
+]`)
+	repaired := filepath.Join(t.TempDir(), "repaired.json")
+
+	// - report
+	out, stderr, err := execute(t, "", "-o", "json", "lint", path)
+	if err == nil {
+		t.Error("lint = nil, want an error for the duplicated registration")
+	}
+	var problems []lint.Problem
+	if err := json.Unmarshal([]byte(out), &problems); err != nil {
+		t.Fatalf("invalid report %q: %v", out, err)
+	}
+	found := make(map[string]bool)
+	for _, p := range problems {
+		if p.Id == 2 {
+			found[p.Field+" "+string(p.Severity)] = true
+		}
+	}
+	for _, want := range []string{"registration error", "registration warning", "color warning", "length warning"} {
+		if !found[want] {
+			t.Errorf("problems of the record 2 = %v, want a %s", found, want)
+		}
+	}
+	if !strings.Contains(stderr, "2 records, ") {
+		t.Errorf("summary = %q", stderr)
+	}
+
+	// - repair, only the missing length remains
+	if _, _, err = execute(t, "", "lint", "-repair", repaired, path); err != nil {
+		t.Fatalf("lint -repair = %v", err)
+	}
+	_, stderr, err = execute(t, "", "lint", repaired)
+	if err != nil || !strings.Contains(stderr, "2 records, 0 errors, 1 warnings") {
+		t.Errorf("lint of the repaired file = %q, %v, want only the missing length", stderr, err)
+	}
+}
+
+func TestRun_Generate(t *testing.T) {
+	// - the same fleet for the same seed, and another one for another seed
+	first, _, err := execute(t, "", "-o", "csv", "generate", "-n", "20", "-seed", "7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _, _ := execute(t, "", "-o", "csv", "generate", "-n", "20", "-seed", "7")
+	other, _, _ := execute(t, "", "-o", "csv", "generate", "-n", "20", "-seed", "8")
+	if first != second {
+		t.Error("generate is not deterministic for the same seed")
+	}
+	if first == other {
+		t.Error("generate gives the same fleet for different seeds")
+	}
+	if lines := strings.Count(first, "\n"); lines != 21 {
+		t.Errorf("generate = %d lines, want a header and 20 vehicles", lines)
+	}
+
+	// - the generated files pass the lint
+	for _, name := range []string{"fleet.json", "fleet.csv"} {
+		path := filepath.Join(t.TempDir(), name)
+		if _, _, err := execute(t, "", "generate", "-n", "50", "-out", path); err != nil {
+			t.Fatal(err)
+		}
+		if _, stderr, err := execute(t, "", "lint", path); err != nil || !strings.Contains(stderr, "50 records, 0 errors") {
+			t.Errorf("lint %s = %q, %v, want no errors", name, stderr, err)
+		}
+	}
+}
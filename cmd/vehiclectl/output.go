@@ -0,0 +1,89 @@
+package main
+
+import (
+	"app/client"
+	"app/internal"
+	"app/internal/loader"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+)
+
+var (
+	// ErrUnknownFormat is returned when the output format is not table, json nor csv
+	ErrUnknownFormat = errors.New("unknown output format, it must be table, json or csv")
+)
+
+// writeVehicles writes the vehicles in the format table, json or csv
+func writeVehicles(w io.Writer, format string, vehicles []internal.Vehicle) (err error) {
+	switch format {
+	case "table":
+		return writeTable(w, vehicles)
+	case "json":
+		// the representation of the api, with the versions
+		res := make([]client.Vehicle, 0, len(vehicles))
+		for _, vh := range vehicles {
+			res = append(res, vehicleToClient(vh))
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	case "csv":
+		return loader.WriteVehiclesCSV(w, vehicles)
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+// writeTable writes the main attributes of the vehicles as an aligned table
+func writeTable(w io.Writer, vehicles []internal.Vehicle) (err error) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tVERSION\tBRAND\tMODEL\tREGISTRATION\tCOLOR\tYEAR\tPASSENGERS\tFUEL TYPE\tTRANSMISSION")
+	for _, vh := range vehicles {
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%s\t%s\t%d\t%d\t%s\t%s\n",
+			vh.Id, vh.Version, vh.Brand, vh.Model, vh.Registration, vh.Color,
+			vh.FabricationYear, vh.Capacity, vh.FuelType, vh.Transmission)
+	}
+	return tw.Flush()
+}
+
+// writeExport writes the vehicles in a format the loaders accept, json or csv
+func writeExport(w io.Writer, format string, vehicles []internal.Vehicle) (err error) {
+	switch format {
+	case "json":
+		res := make([]loader.VehicleJSON, 0, len(vehicles))
+		for _, vh := range vehicles {
+			res = append(res, loader.NewVehicleJSON(vh))
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	case "csv":
+		return loader.WriteVehiclesCSV(w, vehicles)
+	default:
+		return fmt.Errorf("export: %w", ErrUnknownFormat)
+	}
+}
+
+// parseId parses the id argument of a command
+func parseId(arg string) (id int, err error) {
+	id, err = strconv.Atoi(arg)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid id %q", arg)
+	}
+	return
+}
+
+// sortedVehicles returns the vehicles sorted by id
+func sortedVehicles(v map[int]internal.Vehicle) (vehicles []internal.Vehicle) {
+	vehicles = make([]internal.Vehicle, 0, len(v))
+	for _, vh := range v {
+		vehicles = append(vehicles, vh)
+	}
+	sort.Slice(vehicles, func(i, j int) bool { return vehicles[i].Id < vehicles[j].Id })
+	return
+}
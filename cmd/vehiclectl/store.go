@@ -0,0 +1,256 @@
+package main
+
+import (
+	"app/client"
+	"app/internal"
+	"app/internal/filter"
+	"app/internal/loader"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"io"
+	"log/slog"
+	"sort"
+)
+
+// store is the vehicle store the commands work on, a running server or a data file
+type store interface {
+	// List returns the vehicles sorted by id, the ones that satisfy the filter expression expr if it is not empty
+	List(ctx context.Context, expr string) (v []internal.Vehicle, err error)
+	// Get returns the vehicle with the given id
+	Get(ctx context.Context, id int) (v internal.Vehicle, err error)
+	// GetByVin returns the vehicle with the given VIN
+	GetByVin(ctx context.Context, vin string) (v internal.Vehicle, err error)
+	// Add adds a new vehicle, its id is assigned by the store
+	Add(ctx context.Context, vehicle internal.Vehicle) (v internal.Vehicle, err error)
+	// Update updates a vehicle, if vehicle.Version is its current version
+	Update(ctx context.Context, vehicle internal.Vehicle) (v internal.Vehicle, err error)
+	// Delete deletes a vehicle, if version is its current version
+	Delete(ctx context.Context, id int, version int) (err error)
+	// Close releases the store, saving the changes if it is needed
+	Close() (err error)
+}
+
+// NewStoreHTTP is a function that returns a store over the api of a running server
+func NewStoreHTTP(cl *client.Client) *StoreHTTP {
+	return &StoreHTTP{cl: cl}
+}
+
+// StoreHTTP is a store over the api of a running server
+type StoreHTTP struct {
+	// cl is the client of the api
+	cl *client.Client
+}
+
+// List returns the vehicles sorted by id
+func (s *StoreHTTP) List(ctx context.Context, expr string) (v []internal.Vehicle, err error) {
+	var vehicles []client.Vehicle
+	if expr == "" {
+		vehicles, err = s.cl.FindAll(ctx)
+	} else {
+		vehicles, err = s.cl.FindAllMatching(ctx, expr)
+	}
+	if err != nil {
+		return
+	}
+
+	for _, vh := range vehicles {
+		v = append(v, vehicleFromClient(vh))
+	}
+	sort.Slice(v, func(i, j int) bool { return v[i].Id < v[j].Id })
+	return
+}
+
+// Get returns the vehicle with the given id
+func (s *StoreHTTP) Get(ctx context.Context, id int) (v internal.Vehicle, err error) {
+	vh, err := s.cl.FindById(ctx, id)
+	if err != nil {
+		return
+	}
+	return vehicleFromClient(vh), nil
+}
+
+// GetByVin returns the vehicle with the given VIN
+func (s *StoreHTTP) GetByVin(ctx context.Context, vin string) (v internal.Vehicle, err error) {
+	vh, err := s.cl.FindByVin(ctx, vin)
+	if err != nil {
+		return
+	}
+	return vehicleFromClient(vh), nil
+}
+
+// Add adds a new vehicle
+func (s *StoreHTTP) Add(ctx context.Context, vehicle internal.Vehicle) (v internal.Vehicle, err error) {
+	vh, err := s.cl.Add(ctx, vehicleToClient(vehicle).VehicleAttributes)
+	if err != nil {
+		return
+	}
+	return vehicleFromClient(vh), nil
+}
+
+// Update updates a vehicle
+func (s *StoreHTTP) Update(ctx context.Context, vehicle internal.Vehicle) (v internal.Vehicle, err error) {
+	vh, err := s.cl.Update(ctx, vehicleToClient(vehicle))
+	if err != nil {
+		return
+	}
+	return vehicleFromClient(vh), nil
+}
+
+// Delete deletes a vehicle
+func (s *StoreHTTP) Delete(ctx context.Context, id int, version int) (err error) {
+	return s.cl.Delete(ctx, id, version)
+}
+
+// Close does nothing, the changes are stored by the server
+func (s *StoreHTTP) Close() (err error) {
+	return
+}
+
+// vehicleFromClient converts a vehicle of the api to the model
+func vehicleFromClient(v client.Vehicle) internal.Vehicle {
+	return internal.Vehicle{
+		Id:        v.Id,
+		Version:   v.Version,
+		DeletedAt: v.DeletedAt,
+		VehicleAttributes: internal.VehicleAttributes{
+			Brand:           v.Brand,
+			Model:           v.Model,
+			Registration:    v.Registration,
+			Country:         v.Country,
+			Vin:             v.Vin,
+			Color:           v.Color,
+			FabricationYear: v.FabricationYear,
+			Capacity:        v.Capacity,
+			MaxSpeed:        v.MaxSpeed,
+			FuelType:        v.FuelType,
+			Transmission:    v.Transmission,
+			Weight:          v.Weight,
+			Dimensions: internal.Dimensions{
+				Height: v.Height,
+				Length: v.Length,
+				Width:  v.Width,
+			},
+		},
+	}
+}
+
+// vehicleToClient converts a vehicle of the model to the api
+func vehicleToClient(v internal.Vehicle) client.Vehicle {
+	return client.Vehicle{
+		Id:        v.Id,
+		Version:   v.Version,
+		DeletedAt: v.DeletedAt,
+		VehicleAttributes: client.VehicleAttributes{
+			Brand:           v.Brand,
+			Model:           v.Model,
+			Registration:    v.Registration,
+			Country:         v.Country,
+			Vin:             v.Vin,
+			Color:           v.Color,
+			FabricationYear: v.FabricationYear,
+			Capacity:        v.Capacity,
+			MaxSpeed:        v.MaxSpeed,
+			FuelType:        v.FuelType,
+			Transmission:    v.Transmission,
+			Weight:          v.Weight,
+			Height:          v.Height,
+			Length:          v.Length,
+			Width:           v.Width,
+		},
+	}
+}
+
+// NewStoreFile is a function that returns a store over a data file, JSON or CSV by its extension.
+// The vehicles are loaded into a repository and written back to the file on Close if they changed,
+// rv validates the registrations of the writes like in the server
+func NewStoreFile(path string, rv internal.RegistrationValidator) (s *StoreFile, err error) {
+	fl := loader.NewVehicleFile(path)
+	db, err := fl.Load()
+	if err != nil {
+		return
+	}
+
+	// the same repository and validations as the server, without audit nor logs
+	lg := slog.New(slog.NewTextHandler(io.Discard, nil))
+	rp := repository.NewVehicleMap(db, lg)
+	sv := service.NewVehicleDefault(rp, nil, rv, lg)
+
+	s = &StoreFile{fl: fl, sv: sv}
+	return
+}
+
+// StoreFile is a store over a data file
+type StoreFile struct {
+	// fl loads and saves the vehicles of the file
+	fl interface {
+		internal.VehicleLoader
+		internal.VehicleSaver
+	}
+	// sv is the service of the vehicles loaded from the file
+	sv internal.VehicleService
+	// changed is true if the vehicles changed since they were loaded
+	changed bool
+}
+
+// List returns the vehicles sorted by id
+func (s *StoreFile) List(ctx context.Context, expr string) (v []internal.Vehicle, err error) {
+	var vehicles map[int]internal.Vehicle
+	if expr == "" {
+		vehicles, err = s.sv.FindAll(ctx)
+	} else {
+		var e filter.Expr
+		if e, err = filter.Parse(expr); err != nil {
+			return
+		}
+		vehicles, err = s.sv.FindAllMatching(ctx, e)
+	}
+	if err != nil {
+		return
+	}
+	return sortedVehicles(vehicles), nil
+}
+
+// Get returns the vehicle with the given id
+func (s *StoreFile) Get(ctx context.Context, id int) (v internal.Vehicle, err error) {
+	return s.sv.FindById(ctx, id, false)
+}
+
+// GetByVin returns the vehicle with the given VIN
+func (s *StoreFile) GetByVin(ctx context.Context, vin string) (v internal.Vehicle, err error) {
+	return s.sv.FindByVin(ctx, vin, false)
+}
+
+// Add adds a new vehicle
+func (s *StoreFile) Add(ctx context.Context, vehicle internal.Vehicle) (v internal.Vehicle, err error) {
+	v, err = s.sv.Add(ctx, vehicle)
+	s.changed = s.changed || err == nil
+	return
+}
+
+// Update updates a vehicle
+func (s *StoreFile) Update(ctx context.Context, vehicle internal.Vehicle) (v internal.Vehicle, err error) {
+	v, err = s.sv.Update(ctx, vehicle)
+	s.changed = s.changed || err == nil
+	return
+}
+
+// Delete deletes a vehicle. The files do not keep the deleted vehicles, so it is removed from the file
+func (s *StoreFile) Delete(ctx context.Context, id int, version int) (err error) {
+	err = s.sv.Delete(ctx, id, version)
+	s.changed = s.changed || err == nil
+	return
+}
+
+// Close saves the vehicles to the file, if they changed
+func (s *StoreFile) Close() (err error) {
+	if !s.changed {
+		return
+	}
+
+	v, err := s.sv.FindAll(context.Background())
+	if err != nil {
+		return
+	}
+	return s.fl.Save(v)
+}
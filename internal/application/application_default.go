@@ -1,14 +1,28 @@
 package application
 
 import (
-	"app/internal/handler"
+	"app/internal"
+	"app/internal/audit"
+	"app/internal/auth"
+	"app/internal/health"
 	"app/internal/loader"
+	"app/internal/logging"
+	"app/internal/metrics"
+	"app/internal/ratelimit"
+	"app/internal/registration"
 	"app/internal/repository"
 	"app/internal/service"
+	"app/internal/tracing"
+	"context"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
-	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // ConfigServerChi is a struct that represents the configuration for ServerChi
@@ -17,13 +31,48 @@ type ConfigServerChi struct {
 	ServerAddress string
 	// LoaderFilePath is the path to the file that contains the vehicles
 	LoaderFilePath string
+	// CacheResponses enables the cache of the encoded listings until the next modification of the vehicles
+	CacheResponses bool
+	// AuditFilePath is the path to the JSON lines file where the audit events are appended.
+	// If it is empty the events are kept in memory
+	AuditFilePath string
+	// DeletedRetention is how long the deleted vehicles are kept before they are purged, 0 keeps them forever
+	DeletedRetention time.Duration
+	// PurgeInterval is how often the deleted vehicles older than DeletedRetention are purged
+	PurgeInterval time.Duration
+	// ShutdownTimeout is how long the server waits on SIGINT or SIGTERM for the requests in flight
+	// and for the pending spans to be sent. By default 10 seconds
+	ShutdownTimeout time.Duration
+	// APIKeys are the static api keys accepted in the header X-API-Key, with the principal of each one
+	APIKeys map[string]auth.Principal
+	// JWTSecret is the secret to verify the HS256 tokens accepted in the header Authorization: Bearer.
+	// If there are no api keys nor secret the server does not start, unless it is Insecure
+	JWTSecret []byte
+	// Insecure allows the requests without credentials with the role admin, for development only
+	Insecure bool
+	// ReadLimit is the rate limit of each client on the read routes, a zero burst disables it
+	ReadLimit ratelimit.Limit
+	// WriteLimit is the rate limit of each client on the write routes, a zero burst disables it
+	WriteLimit ratelimit.Limit
+	// LogLevel is the minimum level of the logs: debug, info, warn or error. By default info
+	LogLevel string
+	// LogFormat is the format of the logs: json or text. By default text
+	LogFormat string
+	// TracingEndpoint is the url of the OTLP/HTTP receiver of the traces, e.g. http://localhost:4318/v1/traces.
+	// If it is empty the traces are propagated but not exported
+	TracingEndpoint string
+	// RegistrationCountry is the country of the registrations of the vehicles without one, e.g. AR.
+	// If it is empty only the registrations of the vehicles with a country are validated
+	RegistrationCountry string
 }
 
 // NewServerChi is a function that returns a new instance of ServerChi
 func NewServerChi(cfg *ConfigServerChi) *ServerChi {
 	// default values
 	defaultConfig := &ConfigServerChi{
-		ServerAddress: ":8080",
+		ServerAddress:   ":8080",
+		PurgeInterval:   time.Hour,
+		ShutdownTimeout: 10 * time.Second,
 	}
 	if cfg != nil {
 		if cfg.ServerAddress != "" {
@@ -32,11 +81,43 @@ func NewServerChi(cfg *ConfigServerChi) *ServerChi {
 		if cfg.LoaderFilePath != "" {
 			defaultConfig.LoaderFilePath = cfg.LoaderFilePath
 		}
+		defaultConfig.CacheResponses = cfg.CacheResponses
+		defaultConfig.AuditFilePath = cfg.AuditFilePath
+		defaultConfig.DeletedRetention = cfg.DeletedRetention
+		if cfg.PurgeInterval > 0 {
+			defaultConfig.PurgeInterval = cfg.PurgeInterval
+		}
+		if cfg.ShutdownTimeout > 0 {
+			defaultConfig.ShutdownTimeout = cfg.ShutdownTimeout
+		}
+		defaultConfig.APIKeys = cfg.APIKeys
+		defaultConfig.JWTSecret = cfg.JWTSecret
+		defaultConfig.Insecure = cfg.Insecure
+		defaultConfig.ReadLimit = cfg.ReadLimit
+		defaultConfig.WriteLimit = cfg.WriteLimit
+		defaultConfig.LogLevel = cfg.LogLevel
+		defaultConfig.LogFormat = cfg.LogFormat
+		defaultConfig.TracingEndpoint = cfg.TracingEndpoint
+		defaultConfig.RegistrationCountry = cfg.RegistrationCountry
 	}
 
 	return &ServerChi{
-		serverAddress:  defaultConfig.ServerAddress,
-		loaderFilePath: defaultConfig.LoaderFilePath,
+		serverAddress:       defaultConfig.ServerAddress,
+		loaderFilePath:      defaultConfig.LoaderFilePath,
+		cacheResponses:      defaultConfig.CacheResponses,
+		auditFilePath:       defaultConfig.AuditFilePath,
+		retention:           defaultConfig.DeletedRetention,
+		purgeInterval:       defaultConfig.PurgeInterval,
+		shutdownTimeout:     defaultConfig.ShutdownTimeout,
+		apiKeys:             defaultConfig.APIKeys,
+		jwtSecret:           defaultConfig.JWTSecret,
+		insecure:            defaultConfig.Insecure,
+		readLimit:           defaultConfig.ReadLimit,
+		writeLimit:          defaultConfig.WriteLimit,
+		logLevel:            defaultConfig.LogLevel,
+		logFormat:           defaultConfig.LogFormat,
+		tracingEndpoint:     defaultConfig.TracingEndpoint,
+		registrationCountry: defaultConfig.RegistrationCountry,
 	}
 }
 
@@ -46,43 +127,209 @@ type ServerChi struct {
 	serverAddress string
 	// loaderFilePath is the path to the file that contains the vehicles
 	loaderFilePath string
+	// cacheResponses enables the cache of the encoded listings
+	cacheResponses bool
+	// auditFilePath is the path to the file of the audit events, empty to keep them in memory
+	auditFilePath string
+	// retention is how long the deleted vehicles are kept, 0 keeps them forever
+	retention time.Duration
+	// purgeInterval is how often the deleted vehicles are purged
+	purgeInterval time.Duration
+	// shutdownTimeout is how long the shutdown waits for the requests and the spans
+	shutdownTimeout time.Duration
+	// apiKeys are the accepted api keys
+	apiKeys map[string]auth.Principal
+	// jwtSecret is the secret of the accepted tokens
+	jwtSecret []byte
+	// insecure allows the requests without credentials with the role admin
+	insecure bool
+	// readLimit is the rate limit of the read routes
+	readLimit ratelimit.Limit
+	// writeLimit is the rate limit of the write routes
+	writeLimit ratelimit.Limit
+	// logLevel is the minimum level of the logs
+	logLevel string
+	// logFormat is the format of the logs
+	logFormat string
+	// tracingEndpoint is the url of the receiver of the traces, empty to not export them
+	tracingEndpoint string
+	// registrationCountry is the country of the registrations of the vehicles without one
+	registrationCountry string
 }
 
-// Run is a method that runs the application
+// Run is a method that runs the application until the server fails or the process receives SIGINT or SIGTERM.
+// On a signal the server stops accepting requests, waits for the ones in flight and sends the pending spans
 func (a *ServerChi) Run() (err error) {
+	// signals, caught from the start so they do not kill the process before the spans are sent
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// dependencies
+	// - logger
+	lg, err := logging.NewLogger(logging.ConfigLogger{Level: a.logLevel, Format: a.logFormat})
+	if err != nil {
+		return
+	}
+	slog.SetDefault(lg)
+	// - tracer, the pending spans are sent when Run returns, after the server is shut down
+	tp, err := tracing.NewProvider(tracing.ConfigProvider{
+		Endpoint:    a.tracingEndpoint,
+		ServiceName: "vehicles",
+	})
+	if err != nil {
+		return
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+		defer cancel()
+		if err := tp.Shutdown(shutdownCtx); err != nil {
+			lg.Error("pending spans not sent", "error", err)
+		}
+	}()
+	tracing.SetDefault(tp)
+	// - health
+	st := health.NewStatus()
 	// - loader
 	ld := loader.NewVehicleJSONFile(a.loaderFilePath)
-	db, err := ld.Load()
+	// - repository, empty until the loader finishes
+	rp := repository.NewVehicleMap(nil, lg)
+	// - audit
+	var au internal.AuditSink = audit.NewSinkMemory()
+	if a.auditFilePath != "" {
+		au = audit.NewSinkJSONLines(a.auditFilePath)
+	}
+	// - registrations
+	rv, err := registration.NewValidator(registration.ConfigValidator{DefaultCountry: a.registrationCountry})
 	if err != nil {
 		return
 	}
-	// - repository
-	rp := repository.NewVehicleMap(db)
+	// - metrics
+	reg := metrics.NewRegistry()
 	// - service
-	sv := service.NewVehicleDefault(rp)
-	// - handler
-	hd := handler.NewVehicleDefault(sv)
+	var sv internal.VehicleService = service.NewVehicleDefault(rp, au, rv, lg)
+	sv = service.NewVehicleMetrics(sv, reg)
+	registerFleetMetrics(reg, sv)
+	// - retention job
+	if a.retention > 0 {
+		go a.runRetention(sv, lg)
+	}
+	// - authenticator
+	// - without credentials it fails closed, unless it is insecure for development
+	cfgAuth := auth.ConfigAuthenticator{
+		APIKeys:   a.apiKeys,
+		JWTSecret: a.jwtSecret,
+	}
+	if a.insecure {
+		cfgAuth.Anonymous = auth.RoleAdmin
+		lg.Warn("insecure mode, the requests without credentials are allowed as admin")
+	}
+	an, err := auth.NewAuthenticator(cfgAuth)
+	if err != nil {
+		return fmt.Errorf("%w: configure api keys or a jwt secret, or run insecure for development", err)
+	}
 	// router
-	rt := chi.NewRouter()
-	// - middlewares
-	rt.Use(middleware.Logger)
-	rt.Use(middleware.Recoverer)
-	// - endpoints
-	rt.Route("/vehicles", func(rt chi.Router) {
-		// - GET /vehicles
-		rt.Get("/", hd.GetAll())
-		// - POST /vehicles
-		rt.Post("/", hd.Add)
-		// - GET /vehicles/color/{color}/year/{year}
-		rt.Get("/color/{color}/year/{year}", hd.FindByColorAndYear)
-		// - PUT /vehicles/{id}
-		rt.Put("/{id}", hd.Update)
-		// - GET /vehicles/average_capacity/brand/{brand}
-		rt.Get("/average_capacity/brand/{brand}", hd.GetAvgCapacity)
+	rt := NewRouter(ConfigRouter{
+		Service:        sv,
+		Status:         st,
+		Authenticator:  an,
+		Registry:       reg,
+		Logger:         lg,
+		CacheResponses: a.cacheResponses,
+		ReadLimit:      a.readLimit,
+		WriteLimit:     a.writeLimit,
 	})
 
-	// run server
-	err = http.ListenAndServe(a.serverAddress, rt)
+	// run server, before the vehicles are loaded so the probes are answered
+	srv := &http.Server{Addr: a.serverAddress, Handler: rt}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	// load the vehicles, and reload them on SIGHUP
+	if err = a.load(ld, rp, st, lg); err != nil {
+		srv.Close()
+		return
+	}
+	go a.reloadOnSignal(ld, rp, st, lg)
+
+	select {
+	case err = <-serveErr:
+	case <-ctx.Done():
+		lg.Info("shutting down")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+		defer cancel()
+		err = srv.Shutdown(shutdownCtx)
+	}
 	return
 }
+
+// load loads the vehicles into the repository, the application is not ready meanwhile.
+// If it fails the repository keeps the previous vehicles
+func (a *ServerChi) load(ld internal.VehicleLoader, rp *repository.VehicleMap, st *health.Status, lg *slog.Logger) (err error) {
+	st.BeginLoad()
+	defer func() {
+		st.EndLoad(err)
+	}()
+
+	db, err := ld.Load()
+	if err != nil {
+		lg.Error("vehicles not loaded", "path", a.loaderFilePath, "error", err)
+		return
+	}
+	rp.Load(context.Background(), db)
+	lg.Info("vehicles loaded", "path", a.loaderFilePath, "count", len(db))
+	return
+}
+
+// reloadOnSignal reloads the vehicles each time the process receives SIGHUP
+func (a *ServerChi) reloadOnSignal(ld internal.VehicleLoader, rp *repository.VehicleMap, st *health.Status, lg *slog.Logger) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	for range signals {
+		lg.Info("reloading vehicles")
+		a.load(ld, rp, st, lg)
+	}
+}
+
+// registerFleetMetrics registers the gauges of the number of vehicles by brand and by fuel type,
+// computed from the service on each scrape
+func registerFleetMetrics(reg prometheus.Registerer, sv internal.VehicleService) {
+	countBy := func(attr func(v internal.Vehicle) string) func() []metrics.GaugeSample {
+		return func() (samples []metrics.GaugeSample) {
+			vehicles, err := sv.FindAll(context.Background())
+			if err != nil {
+				return
+			}
+			for _, v := range vehicles {
+				samples = append(samples, metrics.GaugeSample{Labels: []string{attr(v)}, Value: 1})
+			}
+			return
+		}
+	}
+
+	reg.MustRegister(
+		metrics.NewGaugeFunc("vehicles_by_brand", "Number of vehicles, except the deleted ones, by brand.",
+			[]string{"brand"}, countBy(func(v internal.Vehicle) string { return v.Brand })),
+		metrics.NewGaugeFunc("vehicles_by_fuel_type", "Number of vehicles, except the deleted ones, by fuel type.",
+			[]string{"fuel_type"}, countBy(func(v internal.Vehicle) string { return v.FuelType })),
+	)
+}
+
+// runRetention purges periodically the vehicles deleted longer than the retention ago
+func (a *ServerChi) runRetention(sv internal.VehicleService, lg *slog.Logger) {
+	ctx := internal.WithActor(context.Background(), "retention-job")
+
+	ticker := time.NewTicker(a.purgeInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		purged, err := sv.PurgeDeleted(ctx, a.retention)
+		if err != nil {
+			lg.ErrorContext(ctx, "retention purge failed", "error", err)
+			continue
+		}
+		if purged > 0 {
+			lg.InfoContext(ctx, "retention purged vehicles", "count", purged)
+		}
+	}
+}
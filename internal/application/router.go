@@ -0,0 +1,136 @@
+package application
+
+import (
+	"app/internal"
+	"app/internal/auth"
+	"app/internal/handler"
+	"app/internal/health"
+	"app/internal/logging"
+	"app/internal/metrics"
+	"app/internal/openapi"
+	"app/internal/ratelimit"
+	"app/internal/tracing"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ConfigRouter is a struct that represents the dependencies of the router
+type ConfigRouter struct {
+	// Service is the service of the vehicles
+	Service internal.VehicleService
+	// Status is the state of the application for the probes
+	Status *health.Status
+	// Authenticator authenticates the requests and checks the role of each route
+	Authenticator *auth.Authenticator
+	// Registry is the registry of the metrics of the requests, served in /metrics
+	Registry *prometheus.Registry
+	// Logger is the logger of the requests
+	Logger *slog.Logger
+	// CacheResponses enables the cache of the encoded listings
+	CacheResponses bool
+	// ReadLimit is the rate limit of each client on the read routes, a zero burst disables it
+	ReadLimit ratelimit.Limit
+	// WriteLimit is the rate limit of each client on the write routes, a zero burst disables it
+	WriteLimit ratelimit.Limit
+}
+
+// NewRouter is a function that returns the router of the api, with its middlewares and endpoints
+func NewRouter(cfg ConfigRouter) http.Handler {
+	// handler
+	hd := handler.NewVehicleDefault(cfg.Service, cfg.Logger)
+	if cfg.CacheResponses {
+		hd.EnableResponseCache()
+	}
+	hm := metrics.NewHTTPMetrics(cfg.Registry)
+	st, an := cfg.Status, cfg.Authenticator
+
+	// router
+	rt := chi.NewRouter()
+	// - middlewares
+	rt.Use(middleware.RequestID)
+	rt.Use(tracing.Middleware)
+	rt.Use(logging.Middleware(cfg.Logger))
+	rt.Use(hm.Middleware)
+	rt.Use(middleware.Recoverer)
+	rt.Use(an.Authenticate)
+	// - endpoints
+	// - GET /healthz
+	rt.Get("/healthz", st.Healthz)
+	// - GET /readyz
+	rt.Get("/readyz", st.Readyz)
+	// - GET /version
+	rt.Get("/version", health.Version)
+	// - GET /openapi.json
+	rt.Get("/openapi.json", openapi.Handler)
+	// - GET /docs
+	rt.Get("/docs", openapi.Docs)
+	// - GET /docs/{file}, the files of Swagger UI
+	rt.Method(http.MethodGet, "/docs/*", openapi.Assets("/docs/"))
+	rt.Route("/vehicles", func(rt chi.Router) {
+		rt.Use(st.RequireLoaded)
+		// readers
+		rt.Group(func(rt chi.Router) {
+			rt.Use(an.Require(auth.RoleReader))
+			rt.Use(rateLimit(cfg.ReadLimit)...)
+			// - GET /vehicles
+			rt.Get("/", hd.Conditional(hd.GetAll()))
+			// - GET /vehicles/color/{color}/year/{year}
+			rt.Get("/color/{color}/year/{year}", hd.Conditional(hd.FindByColorAndYear))
+			// - GET /vehicles/{id}
+			rt.Get("/{id}", hd.FindById)
+			// - GET /vehicles/vin/{vin}
+			rt.Get("/vin/{vin}", hd.FindByVin)
+			// - GET /vehicles/{id}/history
+			rt.Get("/{id}/history", hd.History)
+			// - GET /vehicles/average_capacity/brand/{brand}
+			rt.Get("/average_capacity/brand/{brand}", hd.Conditional(hd.GetAvgCapacity))
+			// - GET /vehicles/suggest
+			rt.Get("/suggest", hd.Conditional(hd.Suggest))
+			// - GET /vehicles/search
+			rt.Get("/search", hd.Conditional(hd.Search))
+			// - GET /vehicles/stats
+			rt.Get("/stats", hd.Conditional(hd.GetStats))
+			// - GET /vehicles/facets
+			rt.Get("/facets", hd.Conditional(hd.GetFacets))
+		})
+		// editors
+		rt.Group(func(rt chi.Router) {
+			rt.Use(an.Require(auth.RoleEditor))
+			rt.Use(rateLimit(cfg.WriteLimit)...)
+			// - POST /vehicles
+			rt.Post("/", hd.Add)
+			// - PUT /vehicles/{id}
+			rt.Put("/{id}", hd.Update)
+			// - DELETE /vehicles/{id}
+			rt.Delete("/{id}", hd.Delete)
+			// - POST /vehicles/{id}/revert
+			rt.Post("/{id}/revert", hd.Revert)
+			// - POST /vehicles/{id}/restore
+			rt.Post("/{id}/restore", hd.Restore)
+		})
+	})
+	// - GET /metrics
+	rt.With(an.Require(auth.RoleReader)).Method(http.MethodGet, "/metrics", metrics.Handler(cfg.Registry))
+	// admins
+	rt.Group(func(rt chi.Router) {
+		rt.Use(an.Require(auth.RoleAdmin))
+		rt.Use(st.RequireLoaded)
+		// - GET /audit
+		rt.Get("/audit", hd.AuditLog)
+	})
+
+	return rt
+}
+
+// rateLimit returns the middleware that limits the requests of each client, if the limit is set
+func rateLimit(limit ratelimit.Limit) (mw []func(http.Handler) http.Handler) {
+	if limit.Burst <= 0 {
+		return
+	}
+	lm := ratelimit.NewLimiter(ratelimit.ConfigLimiter{Limit: limit})
+	return append(mw, lm.Middleware)
+}
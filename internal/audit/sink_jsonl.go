@@ -0,0 +1,137 @@
+package audit
+
+import (
+	"app/internal"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+// NewSinkJSONLines is a function that returns a new instance of SinkJSONLines
+func NewSinkJSONLines(path string) *SinkJSONLines {
+	return &SinkJSONLines{
+		path: path,
+	}
+}
+
+// SinkJSONLines is a struct that implements internal.AuditSink appending the events to a file, one JSON per line
+type SinkJSONLines struct {
+	// mu serializes the access to the file
+	mu sync.Mutex
+	// path is the path to the file
+	path string
+}
+
+// EventJSON is a struct that represents an audit event in JSON format
+type EventJSON struct {
+	Timestamp time.Time             `json:"timestamp"`
+	Actor     string                `json:"actor"`
+	Action    string                `json:"action"`
+	VehicleId int                   `json:"vehicle_id"`
+	Changes   map[string]ChangeJSON `json:"changes"`
+}
+
+// ChangeJSON is a struct that represents the change of a field in JSON format
+type ChangeJSON struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// parseModelToJSON is a function that parses an event to its JSON format
+func parseModelToJSON(e internal.AuditEvent) (ej EventJSON) {
+	ej = EventJSON{
+		Timestamp: e.Timestamp,
+		Actor:     e.Actor,
+		Action:    string(e.Action),
+		VehicleId: e.VehicleId,
+		Changes:   make(map[string]ChangeJSON),
+	}
+	for field, change := range e.Changes {
+		ej.Changes[field] = ChangeJSON{Before: change.Before, After: change.After}
+	}
+	return
+}
+
+// parseJSONToModel is a function that parses an event in JSON format to the model
+func parseJSONToModel(ej EventJSON) (e internal.AuditEvent) {
+	e = internal.AuditEvent{
+		Timestamp: ej.Timestamp,
+		Actor:     ej.Actor,
+		Action:    internal.AuditAction(ej.Action),
+		VehicleId: ej.VehicleId,
+		Changes:   make(map[string]internal.AuditChange),
+	}
+	for field, change := range ej.Changes {
+		e.Changes[field] = internal.AuditChange{Before: change.Before, After: change.After}
+	}
+	return
+}
+
+// Record appends an event to the file
+func (s *SinkJSONLines) Record(e internal.AuditEvent) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// open file
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	// encode event, the encoder ends it with a new line
+	err = json.NewEncoder(file).Encode(parseModelToJSON(e))
+	return
+}
+
+// FindByVehicle returns the events of a vehicle, oldest first
+func (s *SinkJSONLines) FindByVehicle(id int) (e []internal.AuditEvent, err error) {
+	return s.find(func(event internal.AuditEvent) bool {
+		return event.VehicleId == id
+	})
+}
+
+// FindSince returns the events from a time on, oldest first
+func (s *SinkJSONLines) FindSince(since time.Time) (e []internal.AuditEvent, err error) {
+	return s.find(func(event internal.AuditEvent) bool {
+		return !event.Timestamp.Before(since)
+	})
+}
+
+// find returns the events of the file that satisfy the condition
+func (s *SinkJSONLines) find(cond func(event internal.AuditEvent) bool) (e []internal.AuditEvent, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e = []internal.AuditEvent{}
+
+	// open file, if it does not exist there are no events yet
+	file, err := os.Open(s.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return e, nil
+		}
+		return
+	}
+	defer file.Close()
+
+	// decode line by line
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var ej EventJSON
+		if err = json.Unmarshal(scanner.Bytes(), &ej); err != nil {
+			return nil, err
+		}
+		event := parseJSONToModel(ej)
+		if cond(event) {
+			e = append(e, event)
+		}
+	}
+	err = scanner.Err()
+	return
+}
@@ -0,0 +1,57 @@
+package audit
+
+import (
+	"app/internal"
+	"sync"
+	"time"
+)
+
+// NewSinkMemory is a function that returns a new instance of SinkMemory
+func NewSinkMemory() *SinkMemory {
+	return &SinkMemory{}
+}
+
+// SinkMemory is a struct that implements internal.AuditSink keeping the events in memory
+type SinkMemory struct {
+	// mu protects events
+	mu sync.RWMutex
+	// events are the recorded events, oldest first
+	events []internal.AuditEvent
+}
+
+// Record stores an event
+func (s *SinkMemory) Record(e internal.AuditEvent) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, e)
+	return
+}
+
+// FindByVehicle returns the events of a vehicle, oldest first
+func (s *SinkMemory) FindByVehicle(id int) (e []internal.AuditEvent, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e = []internal.AuditEvent{}
+	for _, event := range s.events {
+		if event.VehicleId == id {
+			e = append(e, event)
+		}
+	}
+	return
+}
+
+// FindSince returns the events from a time on, oldest first
+func (s *SinkMemory) FindSince(since time.Time) (e []internal.AuditEvent, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e = []internal.AuditEvent{}
+	for _, event := range s.events {
+		if !event.Timestamp.Before(since) {
+			e = append(e, event)
+		}
+	}
+	return
+}
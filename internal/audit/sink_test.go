@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"app/internal"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// events are the events of two vehicles, oldest first. The values are the ones a JSON decoding returns
+var events = []internal.AuditEvent{
+	{Timestamp: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), Actor: "alice", Action: internal.AuditActionCreate, VehicleId: 1,
+		Changes: map[string]internal.AuditChange{"brand": {After: "Ford"}, "year": {After: 2010.0}}},
+	{Timestamp: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC), Actor: "bob", Action: internal.AuditActionCreate, VehicleId: 2,
+		Changes: map[string]internal.AuditChange{"brand": {After: "Fiat"}}},
+	{Timestamp: time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC), Actor: "alice", Action: internal.AuditActionUpdate, VehicleId: 1,
+		Changes: map[string]internal.AuditChange{"color": {Before: "Red", After: "Blue"}, "weight": {Before: 1100.5, After: 1200.0}}},
+	{Timestamp: time.Date(2024, 1, 4, 10, 0, 0, 0, time.UTC), Actor: "retention-job", Action: internal.AuditActionPurge, VehicleId: 2,
+		Changes: map[string]internal.AuditChange{}},
+}
+
+func TestSink_RoundTrip(t *testing.T) {
+	sinks := map[string]internal.AuditSink{
+		"memory":     NewSinkMemory(),
+		"json lines": NewSinkJSONLines(filepath.Join(t.TempDir(), "audit.jsonl")),
+	}
+
+	for name, sink := range sinks {
+		t.Run(name, func(t *testing.T) {
+			for _, e := range events {
+				if err := sink.Record(e); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			tests := []struct {
+				name string
+				find func() ([]internal.AuditEvent, error)
+				want []internal.AuditEvent
+			}{
+				{name: "by vehicle", find: func() ([]internal.AuditEvent, error) { return sink.FindByVehicle(1) }, want: []internal.AuditEvent{events[0], events[2]}},
+				{name: "by vehicle without events", find: func() ([]internal.AuditEvent, error) { return sink.FindByVehicle(3) }, want: []internal.AuditEvent{}},
+				{name: "since the time of an event", find: func() ([]internal.AuditEvent, error) { return sink.FindSince(events[1].Timestamp) }, want: events[1:]},
+				{name: "since after the last event", find: func() ([]internal.AuditEvent, error) { return sink.FindSince(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) }, want: []internal.AuditEvent{}},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					e, err := tt.find()
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !reflect.DeepEqual(e, tt.want) {
+						t.Errorf("events = %+v, want %+v", e, tt.want)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestSinkJSONLines_Find(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    int
+		wantErr bool
+	}{
+		{name: "missing file, no events yet", path: filepath.Join(dir, "missing.jsonl")},
+		{name: "empty file", path: write("empty.jsonl", "")},
+		{name: "one event", path: write("one.jsonl", `{"timestamp":"2024-01-01T10:00:00Z","actor":"alice","action":"create","vehicle_id":1,"changes":{}}`+"\n"), want: 1},
+		{name: "corrupt line", path: write("corrupt.jsonl", `{"timestamp":"2024-01-01T10:00:00Z","actor":"alice","action":"create","vehicle_id":1,"changes":{}}`+"\n{\"actor\":\n"), wantErr: true},
+		{name: "line of another type", path: write("type.jsonl", `{"vehicle_id":"1"}`+"\n"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewSinkJSONLines(tt.path).FindSince(time.Time{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindSince() error = %v, want error %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && (e == nil || len(e) != tt.want) {
+				t.Errorf("FindSince() = %v, want %d events", e, tt.want)
+			}
+		})
+	}
+}
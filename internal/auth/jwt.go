@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrTokenMalformed is returned when a token is not a well formed JWT
+	ErrTokenMalformed = errors.New("token is malformed")
+	// ErrTokenAlgorithm is returned when a token is not signed with HS256
+	ErrTokenAlgorithm = errors.New("token algorithm is not HS256")
+	// ErrTokenSignature is returned when the signature of a token is not valid
+	ErrTokenSignature = errors.New("token signature is invalid")
+	// ErrTokenExpired is returned when a token is expired or not valid yet
+	ErrTokenExpired = errors.New("token is expired or not valid yet")
+	// ErrTokenClaims is returned when a token does not have a subject, a known role and an expiration
+	ErrTokenClaims = errors.New("token claims are invalid")
+	// errInvalidAPIKey is returned when an api key is not configured
+	errInvalidAPIKey = errors.New("api key is invalid")
+)
+
+// ClockSkew is the tolerance of the times of the tokens, exp, nbf and iat, to the difference between the clocks
+// of the issuer and the api
+const ClockSkew = time.Minute
+
+// jwtHeader is the header of a JWT
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+// jwtClaims are the claims of a JWT used by the api
+type jwtClaims struct {
+	Subject   string `json:"sub"`
+	Role      string `json:"role"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	NotBefore int64  `json:"nbf,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+}
+
+// SignJWT returns a JWT signed with HS256 for the principal, valid until expiresAt.
+// It is meant for tools and tests, the api only verifies tokens
+func SignJWT(secret []byte, p Principal, expiresAt time.Time) (token string, err error) {
+	header, err := json.Marshal(jwtHeader{Alg: "HS256", Typ: "JWT"})
+	if err != nil {
+		return
+	}
+	claims, err := json.Marshal(jwtClaims{Subject: p.Subject, Role: string(p.Role), ExpiresAt: expiresAt.Unix()})
+	if err != nil {
+		return
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	token = unsigned + "." + base64.RawURLEncoding.EncodeToString(sign(secret, unsigned))
+	return
+}
+
+// VerifyJWT verifies offline a JWT signed with HS256 and returns the principal of its claims sub and role.
+// The claim exp is required, the tokens without expiration are rejected. The claims nbf and iat are optional,
+// a token is not valid before nbf nor if it was issued in the future. The times tolerate ClockSkew
+func VerifyJWT(secret []byte, token string, now time.Time) (p Principal, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return p, ErrTokenMalformed
+	}
+
+	// header
+	var header jwtHeader
+	if err = decodeSegment(parts[0], &header); err != nil {
+		return p, ErrTokenMalformed
+	}
+	if header.Alg != "HS256" {
+		return p, ErrTokenAlgorithm
+	}
+
+	// signature
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return p, ErrTokenMalformed
+	}
+	if !hmac.Equal(signature, sign(secret, parts[0]+"."+parts[1])) {
+		return p, ErrTokenSignature
+	}
+
+	// claims
+	var claims jwtClaims
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return p, ErrTokenMalformed
+	}
+	if claims.ExpiresAt == 0 {
+		return p, ErrTokenClaims
+	}
+	if !now.Before(time.Unix(claims.ExpiresAt, 0).Add(ClockSkew)) {
+		return p, ErrTokenExpired
+	}
+	if claims.NotBefore != 0 && now.Add(ClockSkew).Before(time.Unix(claims.NotBefore, 0)) {
+		return p, ErrTokenExpired
+	}
+	if claims.IssuedAt != 0 && now.Add(ClockSkew).Before(time.Unix(claims.IssuedAt, 0)) {
+		return p, ErrTokenExpired
+	}
+	p = Principal{Subject: claims.Subject, Role: Role(claims.Role)}
+	if p.Subject == "" || !p.Role.Valid() {
+		return Principal{}, ErrTokenClaims
+	}
+	return p, nil
+}
+
+// sign returns the HMAC-SHA256 of data
+func sign(secret []byte, data string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// decodeSegment decodes a base64url JSON segment of a token
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// signClaims returns a token signed with HS256 with the given claims, to test the claims SignJWT does not set
+func signClaims(t *testing.T, secret []byte, claims jwtClaims) string {
+	t.Helper()
+	header, _ := json.Marshal(jwtHeader{Alg: "HS256", Typ: "JWT"})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sign(secret, unsigned))
+}
+
+func TestVerifyJWT(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	unix := func(d time.Duration) int64 { return now.Add(d).Unix() }
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "valid", token: signClaims(t, secret, jwtClaims{Subject: "ana", Role: "editor", ExpiresAt: unix(time.Hour)})},
+		{name: "signed by SignJWT", token: func() string {
+			token, _ := SignJWT(secret, Principal{Subject: "ana", Role: RoleReader}, now.Add(time.Hour))
+			return token
+		}()},
+
+		// - exp is required
+		{name: "without exp", token: signClaims(t, secret, jwtClaims{Subject: "ana", Role: "editor"}), wantErr: ErrTokenClaims},
+		{name: "expired", token: signClaims(t, secret, jwtClaims{Subject: "ana", Role: "editor", ExpiresAt: unix(-time.Hour)}), wantErr: ErrTokenExpired},
+		{name: "expired within the skew", token: signClaims(t, secret, jwtClaims{Subject: "ana", Role: "editor", ExpiresAt: unix(-ClockSkew + time.Second)})},
+		{name: "expired at the skew", token: signClaims(t, secret, jwtClaims{Subject: "ana", Role: "editor", ExpiresAt: unix(-ClockSkew)}), wantErr: ErrTokenExpired},
+
+		// - nbf and iat, with the skew
+		{name: "not valid yet", token: signClaims(t, secret, jwtClaims{Subject: "ana", Role: "editor", ExpiresAt: unix(time.Hour), NotBefore: unix(10 * time.Minute)}), wantErr: ErrTokenExpired},
+		{name: "not valid yet within the skew", token: signClaims(t, secret, jwtClaims{Subject: "ana", Role: "editor", ExpiresAt: unix(time.Hour), NotBefore: unix(ClockSkew)})},
+		{name: "valid from nbf", token: signClaims(t, secret, jwtClaims{Subject: "ana", Role: "editor", ExpiresAt: unix(time.Hour), NotBefore: unix(-time.Minute)})},
+		{name: "issued in the future", token: signClaims(t, secret, jwtClaims{Subject: "ana", Role: "editor", ExpiresAt: unix(time.Hour), IssuedAt: unix(ClockSkew + time.Second)}), wantErr: ErrTokenExpired},
+		{name: "issued within the skew", token: signClaims(t, secret, jwtClaims{Subject: "ana", Role: "editor", ExpiresAt: unix(time.Hour), IssuedAt: unix(30 * time.Second)})},
+		{name: "issued in the past", token: signClaims(t, secret, jwtClaims{Subject: "ana", Role: "editor", ExpiresAt: unix(time.Hour), IssuedAt: unix(-time.Hour)})},
+
+		// - claims, signature and format
+		{name: "without subject", token: signClaims(t, secret, jwtClaims{Role: "editor", ExpiresAt: unix(time.Hour)}), wantErr: ErrTokenClaims},
+		{name: "unknown role", token: signClaims(t, secret, jwtClaims{Subject: "ana", Role: "owner", ExpiresAt: unix(time.Hour)}), wantErr: ErrTokenClaims},
+		{name: "other secret", token: signClaims(t, []byte("other"), jwtClaims{Subject: "ana", Role: "editor", ExpiresAt: unix(time.Hour)}), wantErr: ErrTokenSignature},
+		{name: "not a token", token: "abc", wantErr: ErrTokenMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := VerifyJWT(secret, tt.token, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyJWT() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
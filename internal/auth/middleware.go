@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"app/internal"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrNoCredentials is returned when an authenticator has no api keys, no jwt secret and no anonymous role,
+// so it would reject every request
+var ErrNoCredentials = errors.New("auth: no api keys nor jwt secret configured")
+
+// AnonymousSubject is the subject of the requests without credentials when an anonymous role is configured
+const AnonymousSubject = "anonymous"
+
+// ConfigAuthenticator is a struct that represents the configuration for Authenticator
+type ConfigAuthenticator struct {
+	// APIKeys are the static api keys accepted in the header X-API-Key, with the principal of each one
+	APIKeys map[string]Principal
+	// JWTSecret is the secret to verify the HS256 tokens accepted in the header Authorization: Bearer
+	JWTSecret []byte
+	// Now returns the current time, to check the expiration of the tokens. By default time.Now
+	Now func() time.Time
+	// Anonymous is the role of the requests without credentials, for development only.
+	// If it is empty the requests without credentials are rejected by Require
+	Anonymous Role
+}
+
+// NewAuthenticator is a function that returns a new instance of Authenticator.
+// It fails with ErrNoCredentials if there are no credentials nor anonymous role, and on an unknown anonymous role
+func NewAuthenticator(cfg ConfigAuthenticator) (a *Authenticator, err error) {
+	if cfg.Anonymous != "" && !cfg.Anonymous.Valid() {
+		return nil, fmt.Errorf("auth: unknown anonymous role %q", cfg.Anonymous)
+	}
+	if len(cfg.APIKeys) == 0 && len(cfg.JWTSecret) == 0 && cfg.Anonymous == "" {
+		return nil, ErrNoCredentials
+	}
+
+	// default values
+	now := time.Now
+	if cfg.Now != nil {
+		now = cfg.Now
+	}
+
+	// keep only the hashes of the keys, so they are compared in constant time
+	keys := make(map[[sha256.Size]byte]Principal)
+	for key, p := range cfg.APIKeys {
+		keys[sha256.Sum256([]byte(key))] = p
+	}
+
+	a = &Authenticator{
+		keys:      keys,
+		jwtSecret: cfg.JWTSecret,
+		now:       now,
+		anonymous: cfg.Anonymous,
+	}
+	return
+}
+
+// Authenticator is a struct with middlewares that authenticate and authorize the requests
+type Authenticator struct {
+	// keys are the principals by hash of their api key
+	keys map[[sha256.Size]byte]Principal
+	// jwtSecret is the secret of the tokens, nil if tokens are not accepted
+	jwtSecret []byte
+	// now returns the current time
+	now func() time.Time
+	// anonymous is the role of the requests without credentials, empty to reject them
+	anonymous Role
+}
+
+// Enabled returns true if any credential is configured
+func (a *Authenticator) Enabled() bool {
+	return len(a.keys) > 0 || len(a.jwtSecret) > 0
+}
+
+// Anonymous returns the role of the requests without credentials, empty if they are rejected
+func (a *Authenticator) Anonymous() Role {
+	return a.anonymous
+}
+
+// authenticate returns the principal of the credentials of the request.
+// ok is false if the request has no credentials
+func (a *Authenticator) authenticate(r *http.Request) (p Principal, ok bool, err error) {
+	// api key
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		hash := sha256.Sum256([]byte(key))
+		for candidate, principal := range a.keys {
+			if subtle.ConstantTimeCompare(candidate[:], hash[:]) == 1 {
+				return principal, true, nil
+			}
+		}
+		return p, true, errInvalidAPIKey
+	}
+
+	// bearer token
+	if header := r.Header.Get("Authorization"); header != "" {
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found || len(a.jwtSecret) == 0 {
+			return p, true, ErrTokenMalformed
+		}
+		p, err = VerifyJWT(a.jwtSecret, strings.TrimSpace(token), a.now())
+		return p, true, err
+	}
+
+	return p, false, nil
+}
+
+// Authenticate is a middleware that verifies the credentials of the request, if any, and puts the principal
+// in the context of the request. The subject of the principal is also the actor of the changes for the audit.
+// Requests with invalid credentials are rejected with 401. Requests without credentials continue without principal,
+// or with the anonymous role if it is configured
+func (a *Authenticator) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, ok, err := a.authenticate(r)
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, "Credenciales invalidas")
+			return
+		}
+		if !ok && a.anonymous != "" {
+			p, ok = Principal{Subject: AnonymousSubject, Role: a.anonymous}, true
+		}
+		if ok {
+			ctx := WithPrincipal(r.Context(), p)
+			ctx = internal.WithActor(ctx, p.Subject)
+			r = r.WithContext(ctx)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Require is a middleware that only allows the requests of principals that have at least the role,
+// the requests without principal are rejected with 401
+func (a *Authenticator) Require(role Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				writeError(w, http.StatusUnauthorized, "Se requiere autenticacion")
+				return
+			}
+			if !p.Role.Includes(role) {
+				writeError(w, http.StatusForbidden, "No tiene permisos para esta operacion")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// errorJSON is the body of an error response, with the same shape of the responses of the handlers
+type errorJSON struct {
+	Message string `json:"message"`
+	Data    any    `json:"data"`
+}
+
+// writeError writes an error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Add("Content-Type", "application/json")
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="vehicles"`)
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorJSON{
+		Message: message,
+	})
+}
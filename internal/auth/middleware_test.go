@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewAuthenticator(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ConfigAuthenticator
+		wantErr error
+	}{
+		{name: "api keys", cfg: ConfigAuthenticator{APIKeys: map[string]Principal{"k": {Subject: "ana", Role: RoleReader}}}},
+		{name: "jwt secret", cfg: ConfigAuthenticator{JWTSecret: []byte("secret")}},
+		{name: "anonymous only", cfg: ConfigAuthenticator{Anonymous: RoleAdmin}},
+		{name: "no credentials", cfg: ConfigAuthenticator{}, wantErr: ErrNoCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewAuthenticator(tt.cfg); !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewAuthenticator() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := NewAuthenticator(ConfigAuthenticator{Anonymous: "owner"}); err == nil {
+		t.Error("NewAuthenticator() with an unknown anonymous role, want an error")
+	}
+}
+
+func TestAuthenticator_Require(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	token, err := SignJWT(secret, Principal{Subject: "eva", Role: RoleEditor}, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := map[string]Principal{"reader-key": {Subject: "ana", Role: RoleReader}, "admin-key": {Subject: "root", Role: RoleAdmin}}
+
+	tests := []struct {
+		name   string
+		cfg    ConfigAuthenticator
+		header http.Header
+		role   Role
+		code   int
+	}{
+		// - credentials
+		{name: "reader reads", header: http.Header{"X-Api-Key": {"reader-key"}}, role: RoleReader, code: http.StatusOK},
+		{name: "reader writes", header: http.Header{"X-Api-Key": {"reader-key"}}, role: RoleEditor, code: http.StatusForbidden},
+		{name: "admin audits", header: http.Header{"X-Api-Key": {"admin-key"}}, role: RoleAdmin, code: http.StatusOK},
+		{name: "unknown key", header: http.Header{"X-Api-Key": {"other"}}, role: RoleReader, code: http.StatusUnauthorized},
+		{name: "editor token writes", header: http.Header{"Authorization": {"Bearer " + token}}, role: RoleEditor, code: http.StatusOK},
+		{name: "editor token audits", header: http.Header{"Authorization": {"Bearer " + token}}, role: RoleAdmin, code: http.StatusForbidden},
+		{name: "no credentials", role: RoleReader, code: http.StatusUnauthorized},
+
+		// - anonymous role, the role is still checked
+		{name: "anonymous reader reads", cfg: ConfigAuthenticator{Anonymous: RoleReader}, role: RoleReader, code: http.StatusOK},
+		{name: "anonymous reader writes", cfg: ConfigAuthenticator{Anonymous: RoleReader}, role: RoleEditor, code: http.StatusForbidden},
+		{name: "anonymous admin audits", cfg: ConfigAuthenticator{Anonymous: RoleAdmin}, role: RoleAdmin, code: http.StatusOK},
+		{name: "anonymous with invalid credentials", cfg: ConfigAuthenticator{Anonymous: RoleAdmin},
+			header: http.Header{"Authorization": {"Bearer abc"}}, role: RoleReader, code: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			if cfg.Anonymous == "" {
+				cfg.APIKeys = keys
+				cfg.JWTSecret = secret
+			}
+			cfg.Now = func() time.Time { return now }
+			an, err := NewAuthenticator(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			h := an.Authenticate(an.Require(tt.role)(ok))
+			req := httptest.NewRequest("GET", "/vehicles", nil)
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+			res := httptest.NewRecorder()
+			h.ServeHTTP(res, req)
+
+			if res.Code != tt.code {
+				t.Errorf("%s = %d, want %d", tt.name, res.Code, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Role is the level of access of a principal, each role includes the permissions of the previous ones
+type Role string
+
+const (
+	// RoleReader can read the vehicles
+	RoleReader Role = "reader"
+	// RoleEditor can also add, update and delete the vehicles
+	RoleEditor Role = "editor"
+	// RoleAdmin can also read the audit log
+	RoleAdmin Role = "admin"
+)
+
+// roleLevels are the levels of the roles, a role includes the permissions of the roles with lower level
+var roleLevels = map[Role]int{
+	RoleReader: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+// Valid returns true if the role is known
+func (r Role) Valid() bool {
+	_, ok := roleLevels[r]
+	return ok
+}
+
+// Includes returns true if the role has at least the permissions of other
+func (r Role) Includes(other Role) bool {
+	return roleLevels[r] >= roleLevels[other]
+}
+
+// Principal is an authenticated client of the api
+type Principal struct {
+	// Subject identifies the client, e.g. the owner of an api key or the sub claim of a token
+	Subject string
+	// Role is the level of access of the client
+	Role Role
+}
+
+// principalKey is the key of the principal in a context
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx that carries the principal
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal carried by ctx
+func PrincipalFromContext(ctx context.Context) (p Principal, ok bool) {
+	p, ok = ctx.Value(principalKey{}).(Principal)
+	return
+}
+
+// ParseAPIKeys parses a list of api keys with the format "key:subject:role,key:subject:role"
+func ParseAPIKeys(s string) (keys map[string]Principal, err error) {
+	keys = make(map[string]Principal)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("auth: api key %q must be key:subject:role", entry)
+		}
+		p := Principal{Subject: parts[1], Role: Role(parts[2])}
+		if !p.Role.Valid() {
+			return nil, fmt.Errorf("auth: api key of %q has unknown role %q", p.Subject, p.Role)
+		}
+		keys[parts[0]] = p
+	}
+	return
+}
@@ -0,0 +1,179 @@
+package filter
+
+import (
+	"app/internal"
+	"app/internal/utilities"
+	"fmt"
+	"strings"
+)
+
+// Expr is a node of the syntax tree of a filter expression.
+// Every node is an internal.VehicleMatcher, so a parsed expression can be evaluated by the repository.
+type Expr interface {
+	internal.VehicleMatcher
+	// String returns the canonical representation of the expression
+	String() string
+}
+
+// And is an expression that matches when both sides match
+type And struct {
+	Left  Expr
+	Right Expr
+}
+
+// Match returns true if both sides match the vehicle
+func (e *And) Match(v internal.Vehicle) bool {
+	return e.Left.Match(v) && e.Right.Match(v)
+}
+
+// String returns the canonical representation of the expression
+func (e *And) String() string {
+	return fmt.Sprintf("(%s and %s)", e.Left, e.Right)
+}
+
+// Or is an expression that matches when any side matches
+type Or struct {
+	Left  Expr
+	Right Expr
+}
+
+// Match returns true if any side matches the vehicle
+func (e *Or) Match(v internal.Vehicle) bool {
+	return e.Left.Match(v) || e.Right.Match(v)
+}
+
+// String returns the canonical representation of the expression
+func (e *Or) String() string {
+	return fmt.Sprintf("(%s or %s)", e.Left, e.Right)
+}
+
+// Not is an expression that negates another one
+type Not struct {
+	X Expr
+}
+
+// Match returns true if the negated expression does not match the vehicle
+func (e *Not) Match(v internal.Vehicle) bool {
+	return !e.X.Match(v)
+}
+
+// String returns the canonical representation of the expression
+func (e *Not) String() string {
+	return fmt.Sprintf("not %s", e.X)
+}
+
+// Operator is a comparison operator
+type Operator string
+
+const (
+	OpEqual        Operator = "="
+	OpNotEqual     Operator = "!="
+	OpLess         Operator = "<"
+	OpLessEqual    Operator = "<="
+	OpGreater      Operator = ">"
+	OpGreaterEqual Operator = ">="
+	OpIn           Operator = "in"
+	OpContains     Operator = "contains"
+)
+
+// Comparison is an expression that compares a field of the vehicle against one or more values
+type Comparison struct {
+	// Field is the field of the vehicle being compared
+	Field Field
+	// Op is the comparison operator
+	Op Operator
+	// Values are the values to compare with, only OpIn can have more than one
+	Values []Value
+}
+
+// Match returns true if the field of the vehicle satisfies the comparison
+func (e *Comparison) Match(v internal.Vehicle) bool {
+	switch e.Field.Kind {
+	case KindNumber:
+		return e.matchNumber(e.Field.Number(v))
+	default:
+		return e.matchString(e.Field.text(v))
+	}
+}
+
+// matchNumber compares a numeric field
+func (e *Comparison) matchNumber(n float64) bool {
+	switch e.Op {
+	case OpEqual:
+		return n == e.Values[0].Number
+	case OpNotEqual:
+		return n != e.Values[0].Number
+	case OpLess:
+		return n < e.Values[0].Number
+	case OpLessEqual:
+		return n <= e.Values[0].Number
+	case OpGreater:
+		return n > e.Values[0].Number
+	case OpGreaterEqual:
+		return n >= e.Values[0].Number
+	case OpIn:
+		for _, value := range e.Values {
+			if n == value.Number {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchString compares a text field, ignoring case and accents
+func (e *Comparison) matchString(s string) bool {
+	s = utilities.Normalize(s)
+	switch e.Op {
+	case OpEqual:
+		return s == utilities.Normalize(e.Values[0].Text)
+	case OpNotEqual:
+		return s != utilities.Normalize(e.Values[0].Text)
+	case OpContains:
+		return strings.Contains(s, utilities.Normalize(e.Values[0].Text))
+	case OpIn:
+		for _, value := range e.Values {
+			if s == utilities.Normalize(value.Text) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// String returns the canonical representation of the expression
+func (e *Comparison) String() string {
+	if e.Op == OpIn {
+		values := make([]string, len(e.Values))
+		for i, value := range e.Values {
+			values[i] = value.String()
+		}
+		return fmt.Sprintf("%s in (%s)", e.Field.Name, strings.Join(values, ", "))
+	}
+	if e.Op == OpContains {
+		return fmt.Sprintf("%s contains %s", e.Field.Name, e.Values[0])
+	}
+	return fmt.Sprintf("%s%s%s", e.Field.Name, e.Op, e.Values[0])
+}
+
+// Value is a literal of a comparison
+type Value struct {
+	// Text is the value as written in the expression
+	Text string
+	// Number is the numeric value, only set for numeric fields
+	Number float64
+	// quoted is true if the value was written between quotes
+	quoted bool
+}
+
+// String returns the value as it should be written in an expression.
+// The quoted values only escape the quotes and backslashes, the only escapes of the lexer
+func (v Value) String() string {
+	if v.quoted {
+		return `"` + quoteEscaper.Replace(v.Text) + `"`
+	}
+	return v.Text
+}
+
+// quoteEscaper escapes the text of a quoted value
+var quoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
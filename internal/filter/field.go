@@ -0,0 +1,86 @@
+package filter
+
+import (
+	"app/internal"
+	"sort"
+	"strconv"
+)
+
+// Kind is the type of the values of a field
+type Kind int
+
+const (
+	// KindText is a field with string values
+	KindText Kind = iota
+	// KindNumber is a field with numeric values
+	KindNumber
+)
+
+// Field is a vehicle attribute that can be used in a filter expression
+type Field struct {
+	// Name is the name of the field, as used in the JSON representation of a vehicle
+	Name string
+	// Kind is the type of the values of the field
+	Kind Kind
+	// get returns the value of the field of a vehicle
+	get func(v internal.Vehicle) any
+}
+
+// text returns the value of a text field
+func (f Field) text(v internal.Vehicle) string {
+	return f.get(v).(string)
+}
+
+// Number returns the value of a numeric field of a vehicle
+func (f Field) Number(v internal.Vehicle) float64 {
+	switch n := f.get(v).(type) {
+	case int:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
+
+// Value returns the value of the field of a vehicle formatted as text
+func (f Field) Value(v internal.Vehicle) string {
+	if f.Kind == KindNumber {
+		return strconv.FormatFloat(f.Number(v), 'f', -1, 64)
+	}
+	return f.text(v)
+}
+
+// fields are the fields that can be used in a filter expression, by name
+var fields = map[string]Field{
+	"id":           {Name: "id", Kind: KindNumber, get: func(v internal.Vehicle) any { return v.Id }},
+	"brand":        {Name: "brand", Kind: KindText, get: func(v internal.Vehicle) any { return v.Brand }},
+	"model":        {Name: "model", Kind: KindText, get: func(v internal.Vehicle) any { return v.Model }},
+	"registration": {Name: "registration", Kind: KindText, get: func(v internal.Vehicle) any { return v.Registration }},
+	"country":      {Name: "country", Kind: KindText, get: func(v internal.Vehicle) any { return v.Country }},
+	"vin":          {Name: "vin", Kind: KindText, get: func(v internal.Vehicle) any { return v.Vin }},
+	"color":        {Name: "color", Kind: KindText, get: func(v internal.Vehicle) any { return v.Color }},
+	"year":         {Name: "year", Kind: KindNumber, get: func(v internal.Vehicle) any { return v.FabricationYear }},
+	"passengers":   {Name: "passengers", Kind: KindNumber, get: func(v internal.Vehicle) any { return v.Capacity }},
+	"max_speed":    {Name: "max_speed", Kind: KindNumber, get: func(v internal.Vehicle) any { return v.MaxSpeed }},
+	"fuel_type":    {Name: "fuel_type", Kind: KindText, get: func(v internal.Vehicle) any { return v.FuelType }},
+	"transmission": {Name: "transmission", Kind: KindText, get: func(v internal.Vehicle) any { return v.Transmission }},
+	"weight":       {Name: "weight", Kind: KindNumber, get: func(v internal.Vehicle) any { return v.Weight }},
+	"height":       {Name: "height", Kind: KindNumber, get: func(v internal.Vehicle) any { return v.Height }},
+	"length":       {Name: "length", Kind: KindNumber, get: func(v internal.Vehicle) any { return v.Length }},
+	"width":        {Name: "width", Kind: KindNumber, get: func(v internal.Vehicle) any { return v.Width }},
+}
+
+// LookupField returns the field with the given name
+func LookupField(name string) (f Field, ok bool) {
+	f, ok = fields[name]
+	return
+}
+
+// FieldNames returns the names of all the fields, sorted
+func FieldNames() (names []string) {
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
@@ -0,0 +1,142 @@
+package filter
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// lexer splits a filter expression into tokens
+type lexer struct {
+	// input is the expression being tokenized
+	input string
+	// offset is the current byte offset in the input
+	offset int
+}
+
+// next returns the next token of the input
+func (l *lexer) next() (t token, err error) {
+	// skip spaces
+	for l.offset < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.offset:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		l.offset += size
+	}
+
+	start := l.offset
+	t.pos = start + 1
+	if l.offset >= len(l.input) {
+		t.kind = tokenEOF
+		return
+	}
+
+	r, size := utf8.DecodeRuneInString(l.input[l.offset:])
+	switch {
+	case r == utf8.RuneError && size == 1:
+		return t, &SyntaxError{Pos: t.pos, Msg: "invalid utf-8 encoding"}
+	case r == '(':
+		l.offset++
+		t.kind, t.text = tokenLParen, "("
+	case r == ')':
+		l.offset++
+		t.kind, t.text = tokenRParen, ")"
+	case r == ',':
+		l.offset++
+		t.kind, t.text = tokenComma, ","
+	case r == '=':
+		l.offset++
+		t.kind, t.text = tokenOperator, "="
+	case r == '!' || r == '<' || r == '>':
+		l.offset++
+		if l.offset < len(l.input) && l.input[l.offset] == '=' {
+			l.offset++
+		} else if r == '!' {
+			return t, &SyntaxError{Pos: t.pos, Msg: "expected '=' after '!'"}
+		}
+		t.kind, t.text = tokenOperator, l.input[start:l.offset]
+	case r == '"' || r == '\'':
+		t.kind = tokenString
+		t.text, err = l.quoted(r)
+	case r == '-' || r == '.' || unicode.IsDigit(r):
+		t.kind = tokenNumber
+		t.text = l.number()
+		// a number followed by letters is a bare word, like a registration "123ABC"
+		if l.offset < len(l.input) && isWordRune(rune(l.input[l.offset])) {
+			l.offset = start
+			t.kind = tokenWord
+			t.text = l.word()
+		}
+	case isWordRune(r):
+		t.kind = tokenWord
+		t.text = l.word()
+	default:
+		return t, &SyntaxError{Pos: t.pos, Msg: "unexpected character " + string(r)}
+	}
+
+	return
+}
+
+// quoted reads a string delimited by quote, supporting backslash escapes
+func (l *lexer) quoted(quote rune) (s string, err error) {
+	start := l.offset
+	l.offset++
+
+	var sb strings.Builder
+	for l.offset < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.offset:])
+		l.offset += size
+
+		switch r {
+		case quote:
+			return sb.String(), nil
+		case '\\':
+			if l.offset >= len(l.input) {
+				break
+			}
+			escaped, size := utf8.DecodeRuneInString(l.input[l.offset:])
+			l.offset += size
+			sb.WriteRune(escaped)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	return "", &SyntaxError{Pos: start + 1, Msg: "unterminated string"}
+}
+
+// number reads a numeric literal, with an optional sign and decimal part
+func (l *lexer) number() string {
+	start := l.offset
+	if l.input[l.offset] == '-' {
+		l.offset++
+	}
+	for l.offset < len(l.input) && (isDigit(l.input[l.offset]) || l.input[l.offset] == '.') {
+		l.offset++
+	}
+	return l.input[start:l.offset]
+}
+
+// word reads a bare word
+func (l *lexer) word() string {
+	start := l.offset
+	for l.offset < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.offset:])
+		if !isWordRune(r) {
+			break
+		}
+		l.offset += size
+	}
+	return l.input[start:l.offset]
+}
+
+// isWordRune returns true if r can be part of a bare word
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.'
+}
+
+// isDigit returns true if b is an ascii digit
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
@@ -0,0 +1,268 @@
+package filter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// SyntaxError is an error in a filter expression, with the position where it was found
+type SyntaxError struct {
+	// Pos is the position in the expression where the error was found (1-based)
+	Pos int
+	// Msg is the description of the error
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+}
+
+// maxDepth is the maximum nesting of an expression, to protect the parser from deep recursion
+const maxDepth = 64
+
+// Parse parses a filter expression such as `year>=2000 and (brand=Ford or brand=GMC)`.
+//
+// The grammar is:
+//
+//	expr       = and { "or" and }
+//	and        = unary { "and" unary }
+//	unary      = "not" unary | "(" expr ")" | comparison
+//	comparison = field op value | field "in" "(" value { "," value } ")" | field "contains" value
+//	op         = "=" | "!=" | "<" | "<=" | ">" | ">="
+//
+// Keywords are case insensitive. Values can be numbers, bare words or quoted strings.
+func Parse(input string) (e Expr, err error) {
+	p := &parser{lx: &lexer{input: input}}
+	if err = p.advance(); err != nil {
+		return
+	}
+
+	e, err = p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind != tokenEOF {
+		return nil, p.unexpected()
+	}
+	return
+}
+
+// parser is a recursive descent parser of filter expressions
+type parser struct {
+	// lx is the lexer that provides the tokens
+	lx *lexer
+	// tok is the current token
+	tok token
+	// depth is the current nesting depth
+	depth int
+}
+
+// advance reads the next token
+func (p *parser) advance() (err error) {
+	p.tok, err = p.lx.next()
+	return
+}
+
+// isKeyword returns true if the current token is the given keyword
+func (p *parser) isKeyword(keyword string) bool {
+	return p.tok.kind == tokenWord && strings.EqualFold(p.tok.text, keyword)
+}
+
+// unexpected returns an error for the current token
+func (p *parser) unexpected() error {
+	switch p.tok.kind {
+	case tokenEOF:
+		return &SyntaxError{Pos: p.tok.pos, Msg: "unexpected end of expression"}
+	case tokenLParen, tokenRParen, tokenComma:
+		return &SyntaxError{Pos: p.tok.pos, Msg: fmt.Sprintf("unexpected %s", p.tok.kind)}
+	}
+	return &SyntaxError{Pos: p.tok.pos, Msg: fmt.Sprintf("unexpected %s %q", p.tok.kind, p.tok.text)}
+}
+
+func (p *parser) parseOr() (e Expr, err error) {
+	e, err = p.parseAnd()
+	if err != nil {
+		return
+	}
+
+	for p.isKeyword("or") {
+		if err = p.advance(); err != nil {
+			return
+		}
+		var right Expr
+		right, err = p.parseAnd()
+		if err != nil {
+			return
+		}
+		e = &Or{Left: e, Right: right}
+	}
+	return
+}
+
+func (p *parser) parseAnd() (e Expr, err error) {
+	e, err = p.parseUnary()
+	if err != nil {
+		return
+	}
+
+	for p.isKeyword("and") {
+		if err = p.advance(); err != nil {
+			return
+		}
+		var right Expr
+		right, err = p.parseUnary()
+		if err != nil {
+			return
+		}
+		e = &And{Left: e, Right: right}
+	}
+	return
+}
+
+func (p *parser) parseUnary() (e Expr, err error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxDepth {
+		return nil, &SyntaxError{Pos: p.tok.pos, Msg: "expression is nested too deeply"}
+	}
+
+	switch {
+	case p.isKeyword("not"):
+		if err = p.advance(); err != nil {
+			return
+		}
+		var x Expr
+		x, err = p.parseUnary()
+		if err != nil {
+			return
+		}
+		return &Not{X: x}, nil
+	case p.tok.kind == tokenLParen:
+		pos := p.tok.pos
+		if err = p.advance(); err != nil {
+			return
+		}
+		e, err = p.parseOr()
+		if err != nil {
+			return
+		}
+		if p.tok.kind != tokenRParen {
+			if p.tok.kind == tokenEOF {
+				return nil, &SyntaxError{Pos: pos, Msg: "unclosed '('"}
+			}
+			return nil, p.unexpected()
+		}
+		err = p.advance()
+		return
+	case p.tok.kind == tokenWord:
+		return p.parseComparison()
+	}
+
+	return nil, p.unexpected()
+}
+
+func (p *parser) parseComparison() (e Expr, err error) {
+	// field
+	field, ok := LookupField(strings.ToLower(p.tok.text))
+	if !ok {
+		return nil, &SyntaxError{Pos: p.tok.pos, Msg: fmt.Sprintf("unknown field %q", p.tok.text)}
+	}
+	if err = p.advance(); err != nil {
+		return
+	}
+
+	// operator
+	cmp := &Comparison{Field: field}
+	opPos := p.tok.pos
+	switch {
+	case p.tok.kind == tokenOperator:
+		cmp.Op = Operator(p.tok.text)
+	case p.isKeyword("in"):
+		cmp.Op = OpIn
+	case p.isKeyword("contains"):
+		cmp.Op = OpContains
+	default:
+		return nil, p.unexpected()
+	}
+	if err = checkOperator(field, cmp.Op, opPos); err != nil {
+		return
+	}
+	if err = p.advance(); err != nil {
+		return
+	}
+
+	// values
+	if cmp.Op != OpIn {
+		var value Value
+		value, err = p.parseValue(field)
+		if err != nil {
+			return
+		}
+		cmp.Values = []Value{value}
+		return cmp, nil
+	}
+
+	if p.tok.kind != tokenLParen {
+		return nil, p.unexpected()
+	}
+	for {
+		if err = p.advance(); err != nil {
+			return
+		}
+		var value Value
+		value, err = p.parseValue(field)
+		if err != nil {
+			return
+		}
+		cmp.Values = append(cmp.Values, value)
+
+		if p.tok.kind == tokenRParen {
+			break
+		}
+		if p.tok.kind != tokenComma {
+			return nil, p.unexpected()
+		}
+	}
+	err = p.advance()
+	return cmp, err
+}
+
+// parseValue parses a literal for the given field
+func (p *parser) parseValue(field Field) (v Value, err error) {
+	switch p.tok.kind {
+	case tokenWord, tokenNumber, tokenString:
+	default:
+		return v, p.unexpected()
+	}
+
+	v.Text = p.tok.text
+	v.quoted = p.tok.kind == tokenString
+	if field.Kind == KindNumber {
+		// - NaN and the infinities parse, but they would match every value or none
+		v.Number, err = strconv.ParseFloat(v.Text, 64)
+		if err != nil || math.IsNaN(v.Number) || math.IsInf(v.Number, 0) {
+			return v, &SyntaxError{Pos: p.tok.pos, Msg: fmt.Sprintf("field %s expects a number, got %q", field.Name, v.Text)}
+		}
+	}
+
+	err = p.advance()
+	return
+}
+
+// checkOperator validates that the operator can be applied to the field
+func checkOperator(field Field, op Operator, pos int) error {
+	switch op {
+	case OpLess, OpLessEqual, OpGreater, OpGreaterEqual:
+		if field.Kind != KindNumber {
+			return &SyntaxError{Pos: pos, Msg: fmt.Sprintf("operator %s is not allowed on text field %s", op, field.Name)}
+		}
+	case OpContains:
+		if field.Kind != KindText {
+			return &SyntaxError{Pos: pos, Msg: fmt.Sprintf("operator %s is not allowed on numeric field %s", op, field.Name)}
+		}
+	}
+	return nil
+}
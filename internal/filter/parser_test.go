@@ -0,0 +1,161 @@
+package filter
+
+import (
+	"app/internal"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		// pos is the 1-based position of the error and msg a part of its message
+		pos int
+		msg string
+	}{
+		{name: "unknown field", input: "year=2000 and colour=red", pos: 15, msg: `unknown field "colour"`},
+		{name: "unclosed paren", input: "year=2000 and (brand=Ford or brand=GMC", pos: 15, msg: "unclosed '('"},
+		{name: "unclosed nested paren", input: "((year=2000)", pos: 1, msg: "unclosed '('"},
+		{name: "unterminated string", input: `brand="Land Rover`, pos: 7, msg: "unterminated string"},
+		{name: "contains on a numeric field", input: "brand=Ford and year contains 20", pos: 21, msg: "operator contains is not allowed on numeric field year"},
+		{name: "order on a text field", input: "brand>Ford", pos: 6, msg: "operator > is not allowed on text field brand"},
+		{name: "text for a numeric field", input: "year in (1999, two)", pos: 16, msg: `field year expects a number, got "two"`},
+		{name: "missing value", input: "year>=", pos: 7, msg: "unexpected end of expression"},
+		{name: "unexpected character", input: "brand=Ford; year=2000", pos: 11, msg: "unexpected character ;"},
+		{name: "empty list", input: "brand in ()", pos: 11, msg: "unexpected"},
+		{name: "unbalanced close paren", input: "year=2000)", pos: 10, msg: "unexpected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var syntax *SyntaxError
+			if !errors.As(err, &syntax) {
+				t.Fatalf("Parse(%q) error = %v, want a SyntaxError", tt.input, err)
+			}
+			if syntax.Pos != tt.pos || !strings.Contains(syntax.Msg, tt.msg) {
+				t.Errorf("Parse(%q) error = %q at %d, want %q at %d", tt.input, syntax.Msg, syntax.Pos, tt.msg, tt.pos)
+			}
+		})
+	}
+}
+
+func TestExpr_Match(t *testing.T) {
+	v := internal.Vehicle{Id: 1, VehicleAttributes: internal.VehicleAttributes{
+		Brand: "Citroën", Model: "C4 Cactus", Color: "Red", FabricationYear: 2015, Capacity: 5, FuelType: "diesel",
+		Dimensions: internal.Dimensions{Length: 4.16},
+	}}
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		// - and binds tighter than or, not tighter than and
+		{input: "brand=Ford and year=2000 or color=red", want: true},
+		{input: "color=red or brand=Ford and year=2000", want: true},
+		{input: "brand=Ford and (year=2000 or color=red)", want: false},
+		{input: "not brand=Ford and color=red", want: true},
+		{input: "not (brand=Ford or color=red)", want: false},
+		{input: "not not color=red", want: true},
+
+		// - in, for text and numbers
+		{input: "brand in (Ford, citroen)", want: true},
+		{input: "brand in (Ford, Fiat)", want: false},
+		{input: "year in (2014, 2015)", want: true},
+		{input: "passengers in (2, 7)", want: false},
+
+		// - contains, a part of the text
+		{input: "model contains cact", want: true},
+		{input: `model contains "c4 c"`, want: true},
+		{input: "model contains picasso", want: false},
+
+		// - text ignoring case and accents, in the vehicle and in the value
+		{input: "brand=CITROEN", want: true},
+		{input: "brand=\"citroën\"", want: true},
+		{input: "brand!=citroen", want: false},
+		{input: "model contains CÁCTUS", want: true},
+
+		// - numbers
+		{input: "length>4.1 and length<=4.16", want: true},
+		{input: "year>=2016", want: false},
+		{input: "max_speed=0", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			e, err := Parse(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := e.Match(v); got != tt.want {
+				t.Errorf("Parse(%q).Match() = %t, want %t", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Numbers(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{input: "year=2000"},
+		{input: "weight>=1.5"},
+		{input: "weight>-1"},
+		{input: "year in (1999, 2000)"},
+		{input: "year=two", wantErr: true},
+		{input: "weight<NaN", wantErr: true},
+		{input: "weight>=nan", wantErr: true},
+		{input: "weight<=Inf", wantErr: true},
+		{input: `weight<"+Inf"`, wantErr: true},
+		{input: "year in (2000, Infinity)", wantErr: true},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		var syntax *SyntaxError
+		if tt.wantErr && !errors.As(err, &syntax) {
+			t.Errorf("Parse(%q) error = %v, want a SyntaxError", tt.input, err)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.input, err)
+		}
+	}
+}
+
+// FuzzParseFilter checks that the parser never panics and that the canonical representation
+// of a parsed expression parses again to the same expression
+func FuzzParseFilter(f *testing.F) {
+	for _, seed := range []string{
+		`year>=2000 and (brand=Ford or brand=GMC)`,
+		`not color=red`,
+		`brand in (Ford, "Land Rover", chevrolet)`,
+		`model contains "f-1"`,
+		`max_speed<200.5 or weight>=1e3`,
+		`registration!="AB 123 CD"`,
+		`(((year=1999)))`,
+		`brand="a\"b\\c"`,
+		`year>=`,
+		`brand in ()`,
+		`"unterminated`,
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		e, err := Parse(input)
+		if err != nil {
+			return
+		}
+
+		canonical := e.String()
+		again, err := Parse(canonical)
+		if err != nil {
+			t.Fatalf("Parse(%q) = %q, which does not parse: %v", input, canonical, err)
+		}
+		if again.String() != canonical {
+			t.Fatalf("Parse(%q) = %q, which parses to %q", input, canonical, again.String())
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package filter
+
+// tokenKind is the kind of a token of a filter expression
+type tokenKind int
+
+const (
+	// tokenEOF is the end of the expression
+	tokenEOF tokenKind = iota
+	// tokenWord is a bare word, like a field name or an unquoted value
+	tokenWord
+	// tokenNumber is a numeric literal
+	tokenNumber
+	// tokenString is a quoted string literal
+	tokenString
+	// tokenOperator is a comparison operator (=, !=, <, <=, >, >=)
+	tokenOperator
+	// tokenLParen is an opening parenthesis
+	tokenLParen
+	// tokenRParen is a closing parenthesis
+	tokenRParen
+	// tokenComma is a comma that separates the values of a list
+	tokenComma
+)
+
+// String returns a readable name of the token kind, used in error messages
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of expression"
+	case tokenWord:
+		return "word"
+	case tokenNumber:
+		return "number"
+	case tokenString:
+		return "string"
+	case tokenOperator:
+		return "operator"
+	case tokenLParen:
+		return "'('"
+	case tokenRParen:
+		return "')'"
+	case tokenComma:
+		return "','"
+	}
+	return "unknown token"
+}
+
+// token is a lexical unit of a filter expression
+type token struct {
+	// kind is the kind of the token
+	kind tokenKind
+	// text is the value of the token (unquoted for strings)
+	text string
+	// pos is the position of the token in the expression (1-based)
+	pos int
+}
@@ -0,0 +1,131 @@
+package generator
+
+// class is a class of vehicles with the ranges of its attributes.
+// The dimensions are in meters, the weight in kilograms and the speed in kilometers per hour
+type class struct {
+	// capacity are the possible numbers of passengers
+	capacity []int
+	// speed is the range of the maximum speed
+	speed [2]float64
+	// length, width and height are the ranges of the dimensions
+	length, width, height [2]float64
+	// density is the range of the weight by cubic meter of the bounding box of the vehicle
+	density [2]float64
+	// fuelTypes are the fuel types of the class, the first ones more frequent
+	fuelTypes []string
+}
+
+// classes are the classes of the catalog by name
+var classes = map[string]class{
+	"compact": {
+		capacity:  []int{4, 5},
+		speed:     [2]float64{150, 190},
+		length:    [2]float64{3.6, 4.3},
+		width:     [2]float64{1.6, 1.8},
+		height:    [2]float64{1.4, 1.55},
+		density:   [2]float64{115, 140},
+		fuelTypes: []string{"gasoline", "gasoline", "diesel", "gas"},
+	},
+	"sedan": {
+		capacity:  []int{5},
+		speed:     [2]float64{170, 240},
+		length:    [2]float64{4.5, 5.1},
+		width:     [2]float64{1.75, 1.9},
+		height:    [2]float64{1.4, 1.5},
+		density:   [2]float64{120, 145},
+		fuelTypes: []string{"gasoline", "gasoline", "diesel", "biodiesel"},
+	},
+	"sports": {
+		capacity:  []int{2, 4},
+		speed:     [2]float64{230, 320},
+		length:    [2]float64{4.2, 4.7},
+		width:     [2]float64{1.8, 2.0},
+		height:    [2]float64{1.15, 1.35},
+		density:   [2]float64{130, 160},
+		fuelTypes: []string{"gasoline"},
+	},
+	"suv": {
+		capacity:  []int{5, 7},
+		speed:     [2]float64{160, 210},
+		length:    [2]float64{4.4, 5.2},
+		width:     [2]float64{1.8, 2.0},
+		height:    [2]float64{1.65, 1.95},
+		density:   [2]float64{110, 135},
+		fuelTypes: []string{"gasoline", "diesel", "diesel", "biodiesel"},
+	},
+	"pickup": {
+		capacity:  []int{2, 3, 5},
+		speed:     [2]float64{150, 190},
+		length:    [2]float64{5.0, 5.9},
+		width:     [2]float64{1.8, 2.05},
+		height:    [2]float64{1.75, 1.95},
+		density:   [2]float64{95, 120},
+		fuelTypes: []string{"diesel", "diesel", "gasoline", "biodiesel"},
+	},
+	"van": {
+		capacity:  []int{2, 3, 8, 9, 12},
+		speed:     [2]float64{130, 170},
+		length:    [2]float64{4.8, 6.0},
+		width:     [2]float64{1.9, 2.05},
+		height:    [2]float64{1.9, 2.6},
+		density:   [2]float64{75, 100},
+		fuelTypes: []string{"diesel", "diesel", "gasoline"},
+	},
+	"electric": {
+		capacity:  []int{5},
+		speed:     [2]float64{200, 260},
+		length:    [2]float64{4.6, 5.0},
+		width:     [2]float64{1.85, 1.95},
+		height:    [2]float64{1.4, 1.65},
+		density:   [2]float64{140, 170},
+		fuelTypes: []string{"electric"},
+	},
+}
+
+// model is a model of the catalog, made from the year from to the year to
+type model struct {
+	brand, name string
+	class       string
+	from, to    int
+}
+
+// catalog are the models the vehicles are generated from. The last year is fixed so the output only depends on the seed
+var catalog = []model{
+	{"Chevrolet", "Cavalier", "compact", 1982, 2005},
+	{"Chevrolet", "Camaro", "sports", 1967, 2024},
+	{"Chevrolet", "Silverado", "pickup", 1999, 2024},
+	{"Chevrolet", "Express", "van", 1996, 2024},
+	{"Ford", "Escort", "compact", 1981, 2003},
+	{"Ford", "Focus", "compact", 1998, 2024},
+	{"Ford", "Mustang", "sports", 1964, 2024},
+	{"Ford", "Ranger", "pickup", 1983, 2024},
+	{"Ford", "Escape", "suv", 2000, 2024},
+	{"Ford", "E-Series", "van", 1961, 2014},
+	{"GMC", "Sierra", "pickup", 1999, 2024},
+	{"GMC", "Savana", "van", 1996, 2024},
+	{"GMC", "Yukon", "suv", 1992, 2024},
+	{"Toyota", "Corolla", "compact", 1966, 2024},
+	{"Toyota", "Camry", "sedan", 1982, 2024},
+	{"Toyota", "Hilux", "pickup", 1968, 2024},
+	{"Toyota", "RAV4", "suv", 1994, 2024},
+	{"Volkswagen", "Golf", "compact", 1974, 2024},
+	{"Volkswagen", "Passat", "sedan", 1973, 2024},
+	{"Volkswagen", "Amarok", "pickup", 2010, 2024},
+	{"Fiat", "Uno", "compact", 1983, 2013},
+	{"Fiat", "Palio", "compact", 1996, 2017},
+	{"Renault", "Clio", "compact", 1990, 2024},
+	{"Peugeot", "208", "compact", 2012, 2024},
+	{"Honda", "Civic", "compact", 1972, 2024},
+	{"Honda", "Accord", "sedan", 1976, 2024},
+	{"BMW", "3 Series", "sedan", 1975, 2024},
+	{"BMW", "X5", "suv", 1999, 2024},
+	{"Porsche", "911", "sports", 1964, 2024},
+	{"Porsche", "Boxster", "sports", 1996, 2024},
+	{"Audi", "A4", "sedan", 1994, 2024},
+	{"Mercedes-Benz", "Sprinter", "van", 1995, 2024},
+	{"Tesla", "Model S", "electric", 2012, 2024},
+	{"Tesla", "Model 3", "electric", 2017, 2024},
+}
+
+// colors are the colors of the vehicles, the first ones more frequent
+var colors = []string{"White", "White", "Black", "Black", "Gray", "Silver", "Silver", "Blue", "Red", "Green", "Brown", "Orange", "Yellow"}
@@ -0,0 +1,142 @@
+// Package generator generates synthetic fleets of plausible vehicles, deterministic by seed
+package generator
+
+import (
+	"app/internal"
+	"app/internal/vin"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// mercosurSince is the year the Mercosur plates replaced the old ones in Argentina
+const mercosurSince = 2016
+
+// vinSince is the year the VINs of 17 characters became the standard
+const vinSince = 1981
+
+// vinCharacters are the characters of the descriptors of the VINs, the letters I, O and Q are not used
+const vinCharacters = "0123456789ABCDEFGHJKLMNPRSTUVWXYZ"
+
+// NewGenerator is a function that returns a new instance of Generator.
+// The generators with the same seed generate the same vehicles
+func NewGenerator(seed int64) *Generator {
+	return &Generator{
+		rd:            rand.New(rand.NewSource(seed)),
+		registrations: make(map[string]bool),
+	}
+}
+
+// Generator is a struct that generates vehicles: brand and model pairs of the catalog, years in the production of the model,
+// dimensions, weight, capacity and speed of the class of the model,
+// unique Argentinian registrations of the format of the year, with the country AR,
+// and unique VINs of the manufacturer of the brand and the year, since 1981
+type Generator struct {
+	// rd is the source of the random values
+	rd *rand.Rand
+	// registrations are the registrations generated, to keep them unique
+	registrations map[string]bool
+	// lastId is the last id generated
+	lastId int
+}
+
+// Generate returns n vehicles by id, with the ids following the last one generated
+func (g *Generator) Generate(n int) (v map[int]internal.Vehicle) {
+	v = make(map[int]internal.Vehicle, n)
+	for i := 0; i < n; i++ {
+		vh := g.Vehicle()
+		v[vh.Id] = vh
+	}
+	return
+}
+
+// Vehicle returns a new vehicle
+func (g *Generator) Vehicle() (v internal.Vehicle) {
+	m := catalog[g.rd.Intn(len(catalog))]
+	c := classes[m.class]
+	year := m.from + g.rd.Intn(m.to-m.from+1)
+
+	// dimensions, the weight follows the volume
+	length := g.between(c.length, 2)
+	width := g.between(c.width, 2)
+	height := g.between(c.height, 2)
+	weight := math.Round(length * width * height * g.between(c.density, 0))
+
+	g.lastId++
+	v = internal.Vehicle{
+		Id: g.lastId,
+		VehicleAttributes: internal.VehicleAttributes{
+			Brand:           m.brand,
+			Model:           m.name,
+			Registration:    g.registration(year),
+			Country:         "AR",
+			Color:           colors[g.rd.Intn(len(colors))],
+			FabricationYear: year,
+			Capacity:        c.capacity[g.rd.Intn(len(c.capacity))],
+			MaxSpeed:        g.between(c.speed, 0),
+			FuelType:        c.fuelTypes[g.rd.Intn(len(c.fuelTypes))],
+			Transmission:    g.transmission(year),
+			Weight:          weight,
+			Dimensions: internal.Dimensions{
+				Height: height,
+				Length: length,
+				Width:  width,
+			},
+		},
+	}
+	v.Vin = g.vin(m.brand, year, v.Id)
+	return
+}
+
+// vin returns a VIN of the brand with the year as model year and the id as serial number,
+// empty if the year is before the VINs of 17 characters or the manufacturer of the brand is unknown
+func (g *Generator) vin(brand string, year int, id int) string {
+	wmi, ok := vin.WMI(brand)
+	if !ok || year < vinSince {
+		return ""
+	}
+
+	descriptor := make([]byte, 5)
+	for i := range descriptor {
+		descriptor[i] = vinCharacters[g.rd.Intn(len(vinCharacters))]
+	}
+	plant := vinCharacters[10+g.rd.Intn(len(vinCharacters)-10)]
+	number, err := vin.New(wmi, string(descriptor), year, plant, id)
+	if err != nil {
+		return ""
+	}
+	return number
+}
+
+// between returns a value of a range rounded to the given decimals
+func (g *Generator) between(r [2]float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round((r[0]+g.rd.Float64()*(r[1]-r[0]))*scale) / scale
+}
+
+// transmission returns a transmission, the automatic ones more frequent in the newer vehicles
+func (g *Generator) transmission(year int) string {
+	automatic := math.Min(0.85, math.Max(0.1, float64(year-1960)/70))
+	switch p := g.rd.Float64(); {
+	case p < automatic*0.8:
+		return "automatic"
+	case p < automatic:
+		return "semi-automatic"
+	default:
+		return "manual"
+	}
+}
+
+// registration returns a registration not generated before: the old format ABC123 or the Mercosur one AB123CD, by year
+func (g *Generator) registration(year int) (r string) {
+	letter := func() byte { return byte('A' + g.rd.Intn(26)) }
+	for r == "" || g.registrations[r] {
+		if year >= mercosurSince {
+			r = fmt.Sprintf("%c%c%03d%c%c", letter(), letter(), g.rd.Intn(1000), letter(), letter())
+		} else {
+			r = fmt.Sprintf("%c%c%c%03d", letter(), letter(), letter(), g.rd.Intn(1000))
+		}
+	}
+	g.registrations[r] = true
+	return
+}
@@ -0,0 +1,121 @@
+package generator
+
+import (
+	"app/internal/registration"
+	"app/internal/service"
+	"app/internal/vin"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestGenerator_Seed(t *testing.T) {
+	first := NewGenerator(42).Generate(200)
+	second := NewGenerator(42).Generate(200)
+	other := NewGenerator(43).Generate(200)
+
+	if !maps.Equal(first, second) {
+		t.Error("Generate() differs for the same seed")
+	}
+	if maps.Equal(first, other) {
+		t.Error("Generate() is the same for different seeds")
+	}
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	const n = 3000
+	rv, err := registration.NewValidator(registration.ConfigValidator{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGenerator(1)
+	v := g.Generate(n)
+	// - the ids follow the last one generated, and the registrations are unique across calls
+	for id, vh := range g.Generate(10) {
+		v[id] = vh
+	}
+	if len(v) != n+10 {
+		t.Fatalf("Generate() = %d vehicles, want %d", len(v), n+10)
+	}
+
+	registrations := make(map[string]int)
+	vins := make(map[string]int)
+	for id := 1; id <= n+10; id++ {
+		vh, ok := v[id]
+		if !ok || vh.Id != id {
+			t.Fatalf("vehicle %d is missing or has the id %d", id, vh.Id)
+		}
+
+		// - a model of the catalog, in its years of production
+		i := slices.IndexFunc(catalog, func(m model) bool { return m.brand == vh.Brand && m.name == vh.Model })
+		if i < 0 {
+			t.Errorf("vehicle %d: %s %s is not in the catalog", id, vh.Brand, vh.Model)
+			continue
+		}
+		m := catalog[i]
+		if vh.FabricationYear < m.from || vh.FabricationYear > m.to {
+			t.Errorf("vehicle %d: year %d out of the production of the %s, %d-%d", id, vh.FabricationYear, vh.Model, m.from, m.to)
+		}
+
+		// - the attributes of the class of the model, the weight follows the volume
+		c := classes[m.class]
+		inRange := func(value float64, r [2]float64) bool { return value >= r[0] && value <= r[1] }
+		if !inRange(vh.Length, c.length) || !inRange(vh.Width, c.width) || !inRange(vh.Height, c.height) {
+			t.Errorf("vehicle %d: dimensions %+v out of the class %s", id, vh.Dimensions, m.class)
+		}
+		volume := vh.Length * vh.Width * vh.Height
+		if !inRange(vh.Weight, [2]float64{c.density[0]*volume - 0.5, c.density[1]*volume + 0.5}) {
+			t.Errorf("vehicle %d: weight %v out of the density of the class %s for a volume of %v", id, vh.Weight, m.class, volume)
+		}
+		if !inRange(vh.MaxSpeed, c.speed) || !slices.Contains(c.capacity, vh.Capacity) || !slices.Contains(c.fuelTypes, vh.FuelType) {
+			t.Errorf("vehicle %d: speed %v, capacity %d or fuel type %s out of the class %s", id, vh.MaxSpeed, vh.Capacity, vh.FuelType, m.class)
+		}
+		if !slices.Contains(colors, vh.Color) || !slices.Contains([]string{"automatic", "semi-automatic", "manual"}, vh.Transmission) {
+			t.Errorf("vehicle %d: color %q or transmission %q unknown", id, vh.Color, vh.Transmission)
+		}
+
+		// - unique registrations of the format of the year
+		if err := rv.Validate(vh.Country, vh.Registration); err != nil || vh.Country != "AR" {
+			t.Errorf("vehicle %d: registration %q of %q: %v", id, vh.Registration, vh.Country, err)
+		}
+		if mercosur := len(vh.Registration) == 7 && vh.Registration[2] >= '0' && vh.Registration[2] <= '9'; mercosur != (vh.FabricationYear >= mercosurSince) {
+			t.Errorf("vehicle %d: registration %q is not of the format of %d", id, vh.Registration, vh.FabricationYear)
+		}
+		if first, ok := registrations[vh.Registration]; ok {
+			t.Errorf("vehicle %d: registration %q of the vehicle %d", id, vh.Registration, first)
+		}
+		registrations[vh.Registration] = id
+
+		// - unique VINs of the brand and the year, since the VINs of 17 characters
+		_, known := vin.WMI(vh.Brand)
+		if want := known && vh.FabricationYear >= vinSince; want != (vh.Vin != "") {
+			t.Errorf("vehicle %d: vin %q for a %s of %d", id, vh.Vin, vh.Brand, vh.FabricationYear)
+		}
+		if first, ok := vins[vh.Vin]; ok && vh.Vin != "" {
+			t.Errorf("vehicle %d: vin %q of the vehicle %d", id, vh.Vin, first)
+		}
+		vins[vh.Vin] = id
+
+		// - the service accepts the vehicle, including its vin
+		if invalid := service.Validate(vh.VehicleAttributes); len(invalid) > 0 {
+			t.Errorf("vehicle %d: invalid %s", id, invalid[0].Attr)
+		}
+	}
+}
+
+func TestGenerator_Vehicle_Years(t *testing.T) {
+	// - the first and the last years of the catalog are generated, and the years around the Mercosur registrations
+	g := NewGenerator(7)
+	years := make(map[int]bool)
+	for i := 0; i < 5000; i++ {
+		years[g.Vehicle().FabricationYear] = true
+	}
+
+	first, last := catalog[0].from, catalog[0].to
+	for _, m := range catalog {
+		first, last = min(first, m.from), max(last, m.to)
+	}
+	if !years[first] || !years[last] || !years[mercosurSince-1] || !years[mercosurSince] {
+		t.Errorf("Vehicle() years miss %d, %d or the years around %d", first, last, mercosurSince)
+	}
+}
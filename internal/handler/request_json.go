@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// maxBodyBytes is the maximum size of the body of a request
+const maxBodyBytes = 1 << 20
+
+// requestError is an error of the body of a request, with the status and message of the response
+type requestError struct {
+	// status is the status code of the response
+	status int
+	// message is the message of the response
+	message string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+// decodeJSONBody decodes strictly the JSON body of a request into the struct pointed by v:
+// - the body can not be bigger than maxBodyBytes
+// - unknown fields, values of the wrong type and trailing data are rejected
+// - the pointer fields of v without omitempty are required and can not be null
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v any) (err error) {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+
+	if err = dec.Decode(v); err != nil {
+		return decodeError(err)
+	}
+	if err = dec.Decode(&struct{}{}); err != io.EOF {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return decodeError(err)
+		}
+		return &requestError{status: http.StatusBadRequest, message: "El cuerpo debe contener un unico objeto JSON"}
+	}
+
+	if field := missingField(v); field != "" {
+		return &requestError{status: http.StatusBadRequest, message: fmt.Sprintf("El campo %s es requerido", field)}
+	}
+	return nil
+}
+
+// decodeError returns the requestError of an error of the decoder
+func decodeError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return &requestError{
+			status:  http.StatusRequestEntityTooLarge,
+			message: fmt.Sprintf("El cuerpo no puede superar los %d bytes", maxBytesErr.Limit),
+		}
+	case errors.Is(err, io.EOF):
+		return &requestError{status: http.StatusBadRequest, message: "El cuerpo no puede estar vacio"}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return &requestError{status: http.StatusBadRequest, message: "JSON mal formado"}
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return &requestError{status: http.StatusBadRequest, message: "El cuerpo debe ser un objeto JSON"}
+		}
+		return &requestError{
+			status:  http.StatusBadRequest,
+			message: fmt.Sprintf("El campo %s debe ser de tipo %s", typeErr.Field, jsonType(typeErr.Type)),
+		}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		return &requestError{status: http.StatusBadRequest, message: fmt.Sprintf("El campo %s no existe", field)}
+	}
+	return &requestError{status: http.StatusBadRequest, message: "JSON mal formado"}
+}
+
+// jsonType returns the name of the JSON type of a go type
+func jsonType(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "entero"
+	case reflect.Float32, reflect.Float64:
+		return "numero"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return "objeto"
+}
+
+// missingField returns the JSON name of the first required field of the struct pointed by v that is nil,
+// that is a pointer field without omitempty that was absent or null
+func missingField(v any) string {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return ""
+	}
+
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.Type.Kind() != reflect.Pointer {
+			continue
+		}
+		name, opts, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" || strings.Contains(opts, "omitempty") {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if rv.Field(i).IsNil() {
+			return name
+		}
+	}
+	return ""
+}
+
+// writeRequestError writes the response of an error of the body of a request
+func writeRequestError(w http.ResponseWriter, err error) {
+	status, message := http.StatusBadRequest, "Datos del vehículo mal formados"
+	var reqErr *requestError
+	if errors.As(err, &reqErr) {
+		status, message = reqErr.status, reqErr.message
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ResponseJSON{
+		Message: message,
+	})
+}
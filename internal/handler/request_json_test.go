@@ -0,0 +1,76 @@
+package handler_test
+
+import (
+	"app/internal"
+	"app/internal/handler"
+	"app/internal/repository"
+	"app/internal/service"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVehicleDefault_Add_Body(t *testing.T) {
+	// body returns a vehicle in JSON with the fields replaced by the given ones, a null value removes the field
+	body := func(fields map[string]any) string {
+		vehicle := map[string]any{
+			"brand": "Ford", "model": "Fiesta", "registration": "AB123CD", "color": "Red", "year": 2010,
+			"passengers": 5, "max_speed": 180, "fuel_type": "gasoline", "transmission": "manual",
+			"weight": 1100, "height": 1.5, "length": 4, "width": 1.7,
+		}
+		for name, value := range fields {
+			if value == nil {
+				delete(vehicle, name)
+				continue
+			}
+			vehicle[name] = value
+		}
+		b, err := json.Marshal(vehicle)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		code    int
+		message string
+	}{
+		{name: "valid", body: body(nil), code: http.StatusCreated, message: "Vehiculo añadido"},
+		{name: "optional fields", body: body(map[string]any{"country": "AR", "vin": ""}), code: http.StatusCreated, message: "Vehiculo añadido"},
+		{name: "unknown field", body: body(map[string]any{"owner": "Ana"}), code: http.StatusBadRequest, message: `El campo "owner" no existe`},
+		{name: "wrong type of a number", body: body(map[string]any{"year": "2010"}), code: http.StatusBadRequest, message: "El campo year debe ser de tipo entero"},
+		{name: "wrong type of a text", body: body(map[string]any{"brand": 1}), code: http.StatusBadRequest, message: "El campo brand debe ser de tipo string"},
+		{name: "decimal for an integer", body: body(map[string]any{"passengers": 4.5}), code: http.StatusBadRequest, message: "El campo passengers debe ser de tipo entero"},
+		{name: "missing required field", body: body(map[string]any{"color": nil}), code: http.StatusBadRequest, message: "El campo color es requerido"},
+		{name: "null required field", body: strings.Replace(body(nil), `"weight":1100`, `"weight":null`, 1), code: http.StatusBadRequest, message: "El campo weight es requerido"},
+		{name: "trailing object", body: body(nil) + `{}`, code: http.StatusBadRequest, message: "El cuerpo debe contener un unico objeto JSON"},
+		{name: "trailing data", body: body(nil) + ` x`, code: http.StatusBadRequest, message: "El cuerpo debe contener un unico objeto JSON"},
+		{name: "empty", body: "", code: http.StatusBadRequest, message: "El cuerpo no puede estar vacio"},
+		{name: "malformed", body: `{"brand": "Ford",`, code: http.StatusBadRequest, message: "JSON mal formado"},
+		{name: "not an object", body: `[1, 2]`, code: http.StatusBadRequest, message: "El cuerpo debe ser un objeto JSON"},
+		{name: "too large", body: body(map[string]any{"model": strings.Repeat("a", 1<<20)}), code: http.StatusRequestEntityTooLarge, message: "El cuerpo no puede superar los 1048576 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sv := service.NewVehicleDefault(repository.NewVehicleMap(map[int]internal.Vehicle{}, nil), nil, nil, nil)
+			hd := handler.NewVehicleDefault(sv, nil)
+			req := httptest.NewRequest("POST", "/vehicles", strings.NewReader(tt.body))
+			res := httptest.NewRecorder()
+
+			hd.Add(res, req)
+			var resBody handler.ResponseJSON
+			if err := json.NewDecoder(res.Body).Decode(&resBody); err != nil {
+				t.Fatal(err)
+			}
+			if res.Code != tt.code || resBody.Message != tt.message {
+				t.Errorf("POST /vehicles = %d %q, want %d %q", res.Code, resBody.Message, tt.code, tt.message)
+			}
+		})
+	}
+}
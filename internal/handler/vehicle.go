@@ -2,42 +2,106 @@ package handler
 
 import (
 	"app/internal"
-	"app/internal/utilities"
+	"app/internal/filter"
+	"app/internal/logging"
+	"app/internal/tracing"
+	"app/internal/vin"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/bootcamp-go/web/response"
 	"github.com/go-chi/chi/v5"
 )
 
-// NewVehicleDefault is a function that returns a new instance of VehicleDefault
-func NewVehicleDefault(sv internal.VehicleService) *VehicleDefault {
-	return &VehicleDefault{sv: sv}
+// NewVehicleDefault is a function that returns a new instance of VehicleDefault.
+// lg can be nil to use the default logger
+func NewVehicleDefault(sv internal.VehicleService, lg *slog.Logger) *VehicleDefault {
+	return &VehicleDefault{sv: sv, lg: logging.OrDefault(lg)}
 }
 
 // VehicleDefault is a struct with methods that represent handlers for vehicles
 type VehicleDefault struct {
 	// sv is the service that will be used by the handler
 	sv internal.VehicleService
+	// cache keeps the encoded listings, nil if the cache is disabled
+	cache *responseCache
+	// lg is the logger of the errors of the handlers
+	lg *slog.Logger
 }
 
 // GetAll is a method that returns a handler for the route GET /vehicles
 func (h *VehicleDefault) GetAll() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), "handler.VehicleDefault.GetAll")
+		defer span.End()
+		r = r.WithContext(ctx)
+
 		// request
-		// ...
+		// - optional filter expression, e.g. ?filter=year>=2000 and (brand=Ford or brand=GMC)
+		var expr filter.Expr
+		if query := r.URL.Query().Get("filter"); query != "" {
+			var err error
+			expr, err = filter.Parse(query)
+			span.SetAttributes(tracing.String("filter.expression", query))
+			if err != nil {
+				w.Header().Add("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(ResponseJSON{
+					Message: fmt.Sprintf("Filtro invalido: %s", err.Error()),
+				})
+				return
+			}
+		}
+		// - optional field filters, e.g. ?year_min=2000&weight_max=100
+		equalFilter, isSet, err := parseEqualFilter(r)
+		if err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: fmt.Sprintf("Filtro invalido: %s", err.Error()),
+			})
+			return
+		}
+		if expr != nil && isSet {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "No se puede combinar el parametro filter con filtros por campo",
+			})
+			return
+		}
 
 		// process
-		// - get all vehicles
-		v, err := h.sv.FindAll()
+		// - get all vehicles, or the ones that satisfy the filters
+		var v map[int]internal.Vehicle
+		switch {
+		case expr != nil:
+			v, err = h.sv.FindAllMatching(r.Context(), expr)
+		case isSet:
+			v, err = h.sv.FindAllEqualTo(r.Context(), equalFilter)
+		default:
+			v, err = h.sv.FindAll(r.Context())
+		}
 		if err != nil {
+			if errors.Is(err, internal.ErrInvalidRange) {
+				w.Header().Add("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(ResponseJSON{
+					Message: "El minimo de un rango es mayor al maximo",
+				})
+				return
+			}
+			h.logServerError(r, err)
 			response.JSON(w, http.StatusInternalServerError, nil)
 			return
 		}
+		span.SetAttributes(tracing.Int("vehicle.count", len(v)))
 
 		// response
 		// data := make(map[int]VehicleJSON)
@@ -83,48 +147,14 @@ func (h *VehicleDefault) GetAll() http.HandlerFunc {
 }
 
 func (h *VehicleDefault) Add(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Start(r.Context(), "handler.VehicleDefault.Add")
+	defer span.End()
+	r = r.WithContext(ctx)
 
-	// get the bytes of body
-	bodyBytes, err := io.ReadAll(r.Body)
-	if err != nil {
-		w.Header().Add("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ResponseJSON{
-			Message: "Datos del vehículo mal formados",
-		})
-		return
-	}
-
-	// deserialize to a map
-	bodyMap := make(map[string]any)
-	if err := json.Unmarshal(bodyBytes, &bodyMap); err != nil {
-		w.Header().Add("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ResponseJSON{
-			Message: "Datos del vehículo mal formados",
-		})
-		return
-	}
-
-	// validate if all fields are present
-	validFields := utilities.ValidateFields(bodyMap, "brand", "model", "registration", "color", "year", "passengers", "max_speed", "fuel_type", "transmission", "weight", "height", "length", "width")
-	if !validFields {
-		w.Header().Add("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ResponseJSON{
-			Message: "Datos del vehículo incompletos",
-		})
-		return
-	}
-
-	// deserialize to a VehicleRequestJSON
+	// decode the body
 	var vehicleReq VehicleRequestJSON
-	if err := json.Unmarshal(bodyBytes, &vehicleReq); err != nil {
-		w.Header().Add("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ResponseJSON{
-			Message: "Datos del vehículo mal formados",
-		})
+	if err := decodeJSONBody(w, r, &vehicleReq); err != nil {
+		writeRequestError(w, err)
 		return
 	}
 
@@ -132,7 +162,7 @@ func (h *VehicleDefault) Add(w http.ResponseWriter, r *http.Request) {
 	var vehicle internal.Vehicle = vehicleReq.parseRequestToModel()
 
 	// call service
-	vehicle, err = h.sv.Add(vehicle)
+	vehicle, err := h.sv.Add(r.Context(), vehicle)
 	if err != nil {
 
 		var target *internal.ErrInvalidAttributes
@@ -155,6 +185,16 @@ func (h *VehicleDefault) Add(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if errors.Is(err, internal.ErrAuditFailed) {
+			h.logServerError(r, err)
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "El cambio se realizo pero no se pudo auditar",
+			})
+			return
+		}
+
 		w.Header().Add("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(ResponseJSON{
@@ -169,6 +209,7 @@ func (h *VehicleDefault) Add(w http.ResponseWriter, r *http.Request) {
 
 	// write response
 	w.Header().Add("Content-Type", "application/json")
+	w.Header().Set("ETag", vehicleETag(vehicle.Version))
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(ResponseJSON{
 		Message: "Vehiculo añadido",
@@ -179,6 +220,9 @@ func (h *VehicleDefault) Add(w http.ResponseWriter, r *http.Request) {
 
 // FindByColorAndYear returns the list of Vehicles that has that color and year
 func (h *VehicleDefault) FindByColorAndYear(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Start(r.Context(), "handler.VehicleDefault.FindByColorAndYear")
+	defer span.End()
+	r = r.WithContext(ctx)
 
 	// get the path params
 	color := chi.URLParam(r, "color")
@@ -195,12 +239,25 @@ func (h *VehicleDefault) FindByColorAndYear(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// optional fuzzy matching of the color
+	fuzzy, err := queryBool(r, "fuzzy")
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ResponseJSON{
+			Message: "Parametro fuzzy invalido",
+		})
+		return
+	}
+
 	// call the service
-	vehiclesMap, err := h.sv.FindAllEqualTo(internal.EqualFilter{
+	vehiclesMap, err := h.sv.FindAllEqualTo(r.Context(), internal.EqualFilter{
 		Color:           color,
-		FabricationYear: yearInt,
+		FabricationYear: &yearInt,
+		Fuzzy:           fuzzy,
 	})
 	if err != nil {
+		h.logServerError(r, err)
 		w.Header().Add("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(ResponseJSON{
@@ -240,6 +297,9 @@ func (h *VehicleDefault) FindByColorAndYear(w http.ResponseWriter, r *http.Reque
 
 // Update updates an existent vehicle
 func (h *VehicleDefault) Update(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Start(r.Context(), "handler.VehicleDefault.Update")
+	defer span.End()
+	r = r.WithContext(ctx)
 
 	// get id from path param
 	id := chi.URLParam(r, "id")
@@ -255,56 +315,27 @@ func (h *VehicleDefault) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// get the bytes of body
-	bodyBytes, err := io.ReadAll(r.Body)
+	// get the version of the vehicle the client wants to update
+	version, err := parseIfMatch(r)
 	if err != nil {
-		w.Header().Add("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ResponseJSON{
-			Message: "Datos del vehículo mal formados",
-		})
+		writeIfMatchError(w, err)
 		return
 	}
 
-	// deserialize to a map
-	bodyMap := make(map[string]any)
-	if err := json.Unmarshal(bodyBytes, &bodyMap); err != nil {
-		w.Header().Add("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ResponseJSON{
-			Message: "Datos del vehículo mal formados",
-		})
-		return
-	}
-
-	// validate if all fields are present
-	validFields := utilities.ValidateFields(bodyMap, "brand", "model", "registration", "color", "year", "passengers", "max_speed", "fuel_type", "transmission", "weight", "height", "length", "width")
-	if !validFields {
-		w.Header().Add("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ResponseJSON{
-			Message: "Datos del vehículo incompletos",
-		})
-		return
-	}
-
-	// deserialize to a VehicleRequestJSON
+	// decode the body
 	var vehicleReq VehicleRequestJSON
-	if err := json.Unmarshal(bodyBytes, &vehicleReq); err != nil {
-		w.Header().Add("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ResponseJSON{
-			Message: "Datos del vehículo mal formados",
-		})
+	if err := decodeJSONBody(w, r, &vehicleReq); err != nil {
+		writeRequestError(w, err)
 		return
 	}
 
 	// parse VehicleRequestJSON to model
 	var vehicle internal.Vehicle = vehicleReq.parseRequestToModel()
 	vehicle.Id = idInt
+	vehicle.Version = version
 
 	// call service
-	vehicle, err = h.sv.Update(vehicle)
+	vehicle, err = h.sv.Update(r.Context(), vehicle)
 	if err != nil {
 
 		var target *internal.ErrInvalidAttributes
@@ -336,6 +367,26 @@ func (h *VehicleDefault) Update(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if errors.Is(err, internal.ErrVehicleConflict) {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusPreconditionFailed)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "El vehiculo fue modificado por otro cliente.",
+			})
+			return
+		}
+
+		if errors.Is(err, internal.ErrAuditFailed) {
+			h.logServerError(r, err)
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "El cambio se realizo pero no se pudo auditar",
+			})
+			return
+		}
+
+		h.logServerError(r, err)
 		w.Header().Add("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(ResponseJSON{
@@ -350,6 +401,7 @@ func (h *VehicleDefault) Update(w http.ResponseWriter, r *http.Request) {
 
 	// response
 	w.Header().Add("Content-Type", "application/json")
+	w.Header().Set("ETag", vehicleETag(vehicle.Version))
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(ResponseJSON{
 		Message: "success",
@@ -358,12 +410,15 @@ func (h *VehicleDefault) Update(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *VehicleDefault) GetAvgCapacity(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Start(r.Context(), "handler.VehicleDefault.GetAvgCapacity")
+	defer span.End()
+	r = r.WithContext(ctx)
 
 	// get brand from path param
 	brand := chi.URLParam(r, "brand")
 
 	// call the service
-	avg, err := h.sv.GetAvgCapacity(brand)
+	avg, err := h.sv.GetAvgCapacity(r.Context(), brand)
 	if err != nil {
 		if errors.Is(err, internal.ErrVehiclesNotFound) {
 			w.Header().Add("Content-Type", "application/json")
@@ -373,6 +428,7 @@ func (h *VehicleDefault) GetAvgCapacity(w http.ResponseWriter, r *http.Request)
 			})
 			return
 		}
+		h.logServerError(r, err)
 		w.Header().Add("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(ResponseJSON{
@@ -390,3 +446,782 @@ func (h *VehicleDefault) GetAvgCapacity(w http.ResponseWriter, r *http.Request)
 	})
 
 }
+
+// Suggest returns the values of a field that autocomplete the query param q, e.g. ?field=brand&q=chev
+func (h *VehicleDefault) Suggest(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Start(r.Context(), "handler.VehicleDefault.Suggest")
+	defer span.End()
+	r = r.WithContext(ctx)
+
+	// get the query params
+	field := r.URL.Query().Get("field")
+	prefix := r.URL.Query().Get("q")
+	limit := 10
+	if l, err := queryInt(r, "limit"); err != nil || (l != nil && *l <= 0) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ResponseJSON{
+			Message: "Limite invalido",
+		})
+		return
+	} else if l != nil {
+		limit = *l
+	}
+
+	// call the service
+	suggestions, err := h.sv.Suggest(r.Context(), field, prefix, limit)
+	if err != nil {
+		var target *internal.ErrInvalidAttributes
+		if errors.As(err, &target) {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: fmt.Sprintf("No se puede autocompletar el campo %s", target.Attr),
+			})
+			return
+		}
+		h.logServerError(r, err)
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ResponseJSON{
+			Message: "Hubo un error interno en el servidor",
+		})
+		return
+	}
+
+	// response
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ResponseJSON{
+		Message: "success",
+		Data:    suggestions,
+	})
+}
+
+// Search returns the vehicles that match the free text of the query param q, sorted by relevance
+func (h *VehicleDefault) Search(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Start(r.Context(), "handler.VehicleDefault.Search")
+	defer span.End()
+	r = r.WithContext(ctx)
+
+	// get the query params
+	query := r.URL.Query().Get("q")
+	limit := 20
+	if l, err := queryInt(r, "limit"); err != nil || (l != nil && *l <= 0) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ResponseJSON{
+			Message: "Limite invalido",
+		})
+		return
+	} else if l != nil {
+		limit = *l
+	}
+
+	// call the service
+	results, err := h.sv.Search(r.Context(), query, limit)
+	if err != nil {
+		if errors.Is(err, internal.ErrEmptySearch) {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "La busqueda esta vacia",
+			})
+			return
+		}
+		h.logServerError(r, err)
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ResponseJSON{
+			Message: "Hubo un error interno en el servidor",
+		})
+		return
+	}
+
+	// parse results to response
+	data := []VehicleSearchResponseJSON{}
+	for _, result := range results {
+		var resultJSON = VehicleSearchResponseJSON{Score: result.Score}
+		resultJSON.parseModelToResponse(result.Vehicle)
+		data = append(data, resultJSON)
+	}
+
+	// response
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ResponseJSON{
+		Message: "success",
+		Data:    data,
+	})
+}
+
+// GetStats returns the statistics of a numeric field of the vehicles that pass the filters of the query params,
+// e.g. ?field=weight&group_by=brand&percentiles=90,95&year_min=2000
+func (h *VehicleDefault) GetStats(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Start(r.Context(), "handler.VehicleDefault.GetStats")
+	defer span.End()
+	r = r.WithContext(ctx)
+
+	// get the query params
+	query := internal.VehicleStatsQuery{
+		Field:   r.URL.Query().Get("field"),
+		GroupBy: r.URL.Query().Get("group_by"),
+	}
+	percentiles, err := queryFloatList(r, "percentiles")
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ResponseJSON{
+			Message: "Percentiles invalidos",
+		})
+		return
+	}
+	query.Percentiles = percentiles
+	query.Filter, _, err = parseEqualFilter(r)
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ResponseJSON{
+			Message: fmt.Sprintf("Filtro invalido: %s", err.Error()),
+		})
+		return
+	}
+
+	// call the service
+	stats, err := h.sv.GetStats(r.Context(), query)
+	if err != nil {
+		var target *internal.ErrInvalidAttributes
+		switch {
+		case errors.As(err, &target):
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: fmt.Sprintf("El atributo %s es invalido", target.Attr),
+			})
+		case errors.Is(err, internal.ErrInvalidRange):
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "El minimo de un rango es mayor al maximo",
+			})
+		case errors.Is(err, internal.ErrVehiclesNotFound):
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "No se encontraron vehiculos con esos criterios",
+			})
+		default:
+			h.logServerError(r, err)
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "Hubo un error interno en el servidor",
+			})
+		}
+		return
+	}
+
+	// parse stats to response, a single summary if the query is not grouped
+	var data any
+	if query.GroupBy == "" {
+		var statsJSON = VehicleStatsResponseJSON{}
+		statsJSON.parseModelToResponse(stats[""])
+		data = statsJSON
+	} else {
+		groups := make(map[string]VehicleStatsResponseJSON)
+		for group, st := range stats {
+			var statsJSON = VehicleStatsResponseJSON{}
+			statsJSON.parseModelToResponse(st)
+			groups[group] = statsJSON
+		}
+		data = groups
+	}
+
+	// response
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ResponseJSON{
+		Message: "success",
+		Data:    data,
+	})
+}
+
+// GetFacets returns the number of vehicles per value of the fields of the query param fields,
+// for the vehicles that pass the filters of the query params, e.g. ?fields=brand,decade&fuel_type=gas
+func (h *VehicleDefault) GetFacets(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Start(r.Context(), "handler.VehicleDefault.GetFacets")
+	defer span.End()
+	r = r.WithContext(ctx)
+
+	// get the query params
+	var fields []string
+	if value := r.URL.Query().Get("fields"); value != "" {
+		fields = strings.Split(value, ",")
+	}
+	equalFilter, _, err := parseEqualFilter(r)
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ResponseJSON{
+			Message: fmt.Sprintf("Filtro invalido: %s", err.Error()),
+		})
+		return
+	}
+
+	// call the service
+	facets, err := h.sv.GetFacets(r.Context(), fields, equalFilter)
+	if err != nil {
+		var target *internal.ErrInvalidAttributes
+		switch {
+		case errors.As(err, &target):
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: fmt.Sprintf("El atributo %s es invalido", target.Attr),
+			})
+		case errors.Is(err, internal.ErrInvalidRange):
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "El minimo de un rango es mayor al maximo",
+			})
+		default:
+			h.logServerError(r, err)
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "Hubo un error interno en el servidor",
+			})
+		}
+		return
+	}
+
+	// parse facets to response
+	data := make(map[string][]VehicleFacetResponseJSON)
+	for field, list := range facets {
+		data[field] = []VehicleFacetResponseJSON{}
+		for _, facet := range list {
+			data[field] = append(data[field], VehicleFacetResponseJSON{
+				Value: facet.Value,
+				Count: facet.Count,
+			})
+		}
+	}
+
+	// response
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ResponseJSON{
+		Message: "success",
+		Data:    data,
+	})
+}
+
+// FindById returns the vehicle with the id of the path, with its version as ETag.
+// It answers 304 Not Modified if the header If-None-Match has the current ETag.
+// With the query param as_of it returns the state of the vehicle at that time,
+// and with include_deleted=true it returns the vehicle even if it is deleted
+func (h *VehicleDefault) FindById(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Start(r.Context(), "handler.VehicleDefault.FindById")
+	defer span.End()
+	r = r.WithContext(ctx)
+
+	// get id from path param
+	idInt, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ResponseJSON{
+			Message: "Id invalido",
+		})
+		return
+	}
+
+	// get the optional point in time, e.g. ?as_of=2024-01-02T15:04:05Z
+	var asOf *time.Time
+	if value := r.URL.Query().Get("as_of"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "Fecha as_of invalida, se espera el formato RFC 3339",
+			})
+			return
+		}
+		asOf = &t
+	}
+
+	// get the optional inclusion of a deleted vehicle
+	includeDeleted, err := queryBool(r, "include_deleted")
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ResponseJSON{
+			Message: "Parametro include_deleted invalido",
+		})
+		return
+	}
+
+	// call the service
+	var vehicle internal.Vehicle
+	if asOf != nil {
+		vehicle, err = h.sv.FindByIdAsOf(r.Context(), idInt, *asOf)
+	} else {
+		vehicle, err = h.sv.FindById(r.Context(), idInt, includeDeleted)
+	}
+	if err != nil {
+		if errors.Is(err, internal.ErrVehicleNotFound) {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "No se encontro el vehiculo.",
+			})
+			return
+		}
+		h.logServerError(r, err)
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ResponseJSON{
+			Message: "Hubo un error interno en el servidor",
+		})
+		return
+	}
+
+	// validators, the vehicle is not modified if its version is the same.
+	// A past state never changes, so it only has the ETag of its version
+	etag := vehicleETag(vehicle.Version)
+	w.Header().Set("ETag", etag)
+	if rev, err := h.sv.Revision(r.Context()); err == nil && asOf == nil {
+		w.Header().Set("Last-Modified", rev.ModifiedAt.UTC().Format(http.TimeFormat))
+		if notModified(r, etag, rev.ModifiedAt) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	// parse model to response
+	var vehicleJSON = VehicleResponseJSON{}
+	vehicleJSON.parseModelToResponse(vehicle)
+
+	// response
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ResponseJSON{
+		Message: "success",
+		Data:    vehicleJSON,
+	})
+}
+
+// FindByVin returns the vehicle with the VIN of the path, with its version as ETag.
+// It answers 304 Not Modified if the header If-None-Match has the current ETag,
+// and with include_deleted=true it returns the vehicle even if it is deleted
+func (h *VehicleDefault) FindByVin(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Start(r.Context(), "handler.VehicleDefault.FindByVin")
+	defer span.End()
+	r = r.WithContext(ctx)
+
+	// get vin from path param
+	number := vin.Normalize(chi.URLParam(r, "vin"))
+	if err := vin.Validate(number); err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ResponseJSON{
+			Message: "VIN invalido",
+		})
+		return
+	}
+
+	// get the optional inclusion of a deleted vehicle
+	includeDeleted, err := queryBool(r, "include_deleted")
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ResponseJSON{
+			Message: "Parametro include_deleted invalido",
+		})
+		return
+	}
+
+	// call the service
+	vehicle, err := h.sv.FindByVin(r.Context(), number, includeDeleted)
+	if err != nil {
+		if errors.Is(err, internal.ErrVehicleNotFound) {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "No se encontro el vehiculo.",
+			})
+			return
+		}
+		h.logServerError(r, err)
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ResponseJSON{
+			Message: "Hubo un error interno en el servidor",
+		})
+		return
+	}
+
+	// validators, the vehicle is not modified if its version is the same
+	etag := vehicleETag(vehicle.Version)
+	w.Header().Set("ETag", etag)
+	if rev, err := h.sv.Revision(r.Context()); err == nil {
+		w.Header().Set("Last-Modified", rev.ModifiedAt.UTC().Format(http.TimeFormat))
+		if notModified(r, etag, rev.ModifiedAt) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	// parse model to response
+	var vehicleJSON = VehicleResponseJSON{}
+	vehicleJSON.parseModelToResponse(vehicle)
+
+	// response
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ResponseJSON{
+		Message: "success",
+		Data:    vehicleJSON,
+	})
+}
+
+// Delete soft deletes the vehicle with the id of the path, if the header If-Match has its current ETag
+func (h *VehicleDefault) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Start(r.Context(), "handler.VehicleDefault.Delete")
+	defer span.End()
+	r = r.WithContext(ctx)
+
+	// get id from path param
+	idInt, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ResponseJSON{
+			Message: "Id invalido",
+		})
+		return
+	}
+
+	// get the version of the vehicle the client wants to delete
+	version, err := parseIfMatch(r)
+	if err != nil {
+		writeIfMatchError(w, err)
+		return
+	}
+
+	// call the service
+	err = h.sv.Delete(r.Context(), idInt, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, internal.ErrVehicleNotFound):
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "No se encontro el vehiculo.",
+			})
+		case errors.Is(err, internal.ErrVehicleConflict):
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusPreconditionFailed)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "El vehiculo fue modificado por otro cliente.",
+			})
+		case errors.Is(err, internal.ErrAuditFailed):
+			h.logServerError(r, err)
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "El cambio se realizo pero no se pudo auditar",
+			})
+		default:
+			h.logServerError(r, err)
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "No se pudo eliminar el vehiculo",
+			})
+		}
+		return
+	}
+
+	// response
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// History returns the audit events of the vehicle with the id of the path, oldest first
+func (h *VehicleDefault) History(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Start(r.Context(), "handler.VehicleDefault.History")
+	defer span.End()
+	r = r.WithContext(ctx)
+
+	// get id from path param
+	idInt, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ResponseJSON{
+			Message: "Id invalido",
+		})
+		return
+	}
+
+	// call the service
+	events, err := h.sv.History(r.Context(), idInt)
+	if err != nil {
+		h.writeAuditError(w, r, err)
+		return
+	}
+
+	// response
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ResponseJSON{
+		Message: "success",
+		Data:    parseAuditEventsToResponse(events),
+	})
+}
+
+// AuditLog returns the audit events from the time of the query param since (RFC 3339) on, oldest first.
+// Without since it returns every event
+func (h *VehicleDefault) AuditLog(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Start(r.Context(), "handler.VehicleDefault.AuditLog")
+	defer span.End()
+	r = r.WithContext(ctx)
+
+	// get the query params
+	var since time.Time
+	if value := r.URL.Query().Get("since"); value != "" {
+		var err error
+		since, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "Fecha since invalida, se espera el formato RFC 3339",
+			})
+			return
+		}
+	}
+
+	// call the service
+	events, err := h.sv.AuditSince(r.Context(), since)
+	if err != nil {
+		h.writeAuditError(w, r, err)
+		return
+	}
+
+	// response
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ResponseJSON{
+		Message: "success",
+		Data:    parseAuditEventsToResponse(events),
+	})
+}
+
+// writeAuditError writes the response for an error reading the audit events
+func (h *VehicleDefault) writeAuditError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, internal.ErrAuditDisabled) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ResponseJSON{
+			Message: "La auditoria no esta habilitada",
+		})
+		return
+	}
+	h.logServerError(r, err)
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(ResponseJSON{
+		Message: "Hubo un error interno en el servidor",
+	})
+}
+
+// Revert restores the attributes of the version of the query param version of the vehicle with the id of the path.
+// If the header If-Match is present it must have the current ETag of the vehicle
+func (h *VehicleDefault) Revert(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Start(r.Context(), "handler.VehicleDefault.Revert")
+	defer span.End()
+	r = r.WithContext(ctx)
+
+	// get id from path param
+	idInt, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ResponseJSON{
+			Message: "Id invalido",
+		})
+		return
+	}
+
+	// get the version to restore
+	version, err := queryInt(r, "version")
+	if err != nil || version == nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ResponseJSON{
+			Message: "Version invalida",
+		})
+		return
+	}
+
+	// get the optional current version
+	expectedVersion := 0
+	if r.Header.Get("If-Match") != "" {
+		expectedVersion, err = parseIfMatch(r)
+		if err != nil {
+			writeIfMatchError(w, err)
+			return
+		}
+	}
+
+	// call the service
+	vehicle, err := h.sv.Revert(r.Context(), idInt, *version, expectedVersion)
+	if err != nil {
+		var target *internal.ErrInvalidAttributes
+		switch {
+		case errors.As(err, &target):
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: fmt.Sprintf("El atributo %s es invalido", target.Attr),
+			})
+		case errors.Is(err, internal.ErrVehicleNotFound):
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "No se encontro el vehiculo.",
+			})
+		case errors.Is(err, internal.ErrVersionNotFound):
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "No se encontro esa version del vehiculo.",
+			})
+		case errors.Is(err, internal.ErrVehicleExistent):
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "Identificador del vehículo pertenece a otro vehiculo.",
+			})
+		case errors.Is(err, internal.ErrVehicleConflict):
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusPreconditionFailed)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "El vehiculo fue modificado por otro cliente.",
+			})
+		case errors.Is(err, internal.ErrAuditFailed):
+			h.logServerError(r, err)
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "El cambio se realizo pero no se pudo auditar",
+			})
+		default:
+			h.logServerError(r, err)
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "No se pudo restaurar el vehiculo",
+			})
+		}
+		return
+	}
+
+	// parse model to response
+	var vehicleJSON = VehicleResponseJSON{}
+	vehicleJSON.parseModelToResponse(vehicle)
+
+	// response
+	w.Header().Add("Content-Type", "application/json")
+	w.Header().Set("ETag", vehicleETag(vehicle.Version))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ResponseJSON{
+		Message: "success",
+		Data:    vehicleJSON,
+	})
+}
+
+// Restore restores the deleted vehicle with the id of the path.
+// If the header If-Match is present it must have the current ETag of the vehicle
+func (h *VehicleDefault) Restore(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Start(r.Context(), "handler.VehicleDefault.Restore")
+	defer span.End()
+	r = r.WithContext(ctx)
+
+	// get id from path param
+	idInt, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ResponseJSON{
+			Message: "Id invalido",
+		})
+		return
+	}
+
+	// get the optional current version
+	expectedVersion := 0
+	if r.Header.Get("If-Match") != "" {
+		expectedVersion, err = parseIfMatch(r)
+		if err != nil {
+			writeIfMatchError(w, err)
+			return
+		}
+	}
+
+	// call the service
+	vehicle, err := h.sv.Restore(r.Context(), idInt, expectedVersion)
+	if err != nil {
+		switch {
+		case errors.Is(err, internal.ErrVehicleNotFound):
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "No se encontro el vehiculo.",
+			})
+		case errors.Is(err, internal.ErrNotDeleted):
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "El vehiculo no esta eliminado.",
+			})
+		case errors.Is(err, internal.ErrVehicleConflict):
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusPreconditionFailed)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "El vehiculo fue modificado por otro cliente.",
+			})
+		case errors.Is(err, internal.ErrAuditFailed):
+			h.logServerError(r, err)
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "El cambio se realizo pero no se pudo auditar",
+			})
+		default:
+			h.logServerError(r, err)
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ResponseJSON{
+				Message: "No se pudo restaurar el vehiculo",
+			})
+		}
+		return
+	}
+
+	// parse model to response
+	var vehicleJSON = VehicleResponseJSON{}
+	vehicleJSON.parseModelToResponse(vehicle)
+
+	// response
+	w.Header().Add("Content-Type", "application/json")
+	w.Header().Set("ETag", vehicleETag(vehicle.Version))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ResponseJSON{
+		Message: "success",
+		Data:    vehicleJSON,
+	})
+}
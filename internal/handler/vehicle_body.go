@@ -2,73 +2,93 @@ package handler
 
 import (
 	"app/internal"
+	"strconv"
+	"time"
 )
 
-// VehicleRequestJSON is a struct that represents the request body of a vehicle in JSON format
+// VehicleRequestJSON is a struct that represents the request body of a vehicle in JSON format.
+// The fields are pointers so that the absent and null ones can be told apart from the zero values,
+// all of them are required except the omitempty ones
 type VehicleRequestJSON struct {
-	Brand           string  `json:"brand"`
-	Model           string  `json:"model"`
-	Registration    string  `json:"registration"`
-	Color           string  `json:"color"`
-	FabricationYear int     `json:"year"`
-	Capacity        int     `json:"passengers"`
-	MaxSpeed        float64 `json:"max_speed"`
-	FuelType        string  `json:"fuel_type"`
-	Transmission    string  `json:"transmission"`
-	Weight          float64 `json:"weight"`
-	Height          float64 `json:"height"`
-	Length          float64 `json:"length"`
-	Width           float64 `json:"width"`
+	Brand           *string  `json:"brand"`
+	Model           *string  `json:"model"`
+	Registration    *string  `json:"registration"`
+	Country         *string  `json:"country,omitempty"`
+	Vin             *string  `json:"vin,omitempty"`
+	Color           *string  `json:"color"`
+	FabricationYear *int     `json:"year"`
+	Capacity        *int     `json:"passengers"`
+	MaxSpeed        *float64 `json:"max_speed"`
+	FuelType        *string  `json:"fuel_type"`
+	Transmission    *string  `json:"transmission"`
+	Weight          *float64 `json:"weight"`
+	Height          *float64 `json:"height"`
+	Length          *float64 `json:"length"`
+	Width           *float64 `json:"width"`
 }
 
-// parseToModel is a function that parses a vehicle request to a vehicle model
-func (req VehicleRequestJSON) parseRequestToModel() internal.Vehicle {
-	return internal.Vehicle{
+// parseToModel is a function that parses a vehicle request to a vehicle model, the request must be complete
+func (req VehicleRequestJSON) parseRequestToModel() (v internal.Vehicle) {
+	v = internal.Vehicle{
 		// Id: 0,
 		VehicleAttributes: internal.VehicleAttributes{
-			Brand:           req.Brand,
-			Model:           req.Model,
-			Registration:    req.Registration,
-			Color:           req.Color,
-			FabricationYear: req.FabricationYear,
-			Capacity:        req.Capacity,
-			MaxSpeed:        req.MaxSpeed,
-			FuelType:        req.FuelType,
-			Transmission:    req.Transmission,
-			Weight:          req.Weight,
+			Brand:           *req.Brand,
+			Model:           *req.Model,
+			Registration:    *req.Registration,
+			Color:           *req.Color,
+			FabricationYear: *req.FabricationYear,
+			Capacity:        *req.Capacity,
+			MaxSpeed:        *req.MaxSpeed,
+			FuelType:        *req.FuelType,
+			Transmission:    *req.Transmission,
+			Weight:          *req.Weight,
 			Dimensions: internal.Dimensions{
-				Height: req.Height,
-				Length: req.Length,
-				Width:  req.Width,
+				Height: *req.Height,
+				Length: *req.Length,
+				Width:  *req.Width,
 			},
 		},
 	}
+	if req.Country != nil {
+		v.Country = *req.Country
+	}
+	if req.Vin != nil {
+		v.Vin = *req.Vin
+	}
+	return
 }
 
 // VehicleResponseJSON is a struct that represents the response body of a vehicle in JSON format
 type VehicleResponseJSON struct {
-	ID              int     `json:"id"`
-	Brand           string  `json:"brand"`
-	Model           string  `json:"model"`
-	Registration    string  `json:"registration"`
-	Color           string  `json:"color"`
-	FabricationYear int     `json:"year"`
-	Capacity        int     `json:"passengers"`
-	MaxSpeed        float64 `json:"max_speed"`
-	FuelType        string  `json:"fuel_type"`
-	Transmission    string  `json:"transmission"`
-	Weight          float64 `json:"weight"`
-	Height          float64 `json:"height"`
-	Length          float64 `json:"length"`
-	Width           float64 `json:"width"`
+	ID              int        `json:"id"`
+	Version         int        `json:"version"`
+	Brand           string     `json:"brand"`
+	Model           string     `json:"model"`
+	Registration    string     `json:"registration"`
+	Country         string     `json:"country,omitempty"`
+	Vin             string     `json:"vin,omitempty"`
+	Color           string     `json:"color"`
+	FabricationYear int        `json:"year"`
+	Capacity        int        `json:"passengers"`
+	MaxSpeed        float64    `json:"max_speed"`
+	FuelType        string     `json:"fuel_type"`
+	Transmission    string     `json:"transmission"`
+	Weight          float64    `json:"weight"`
+	Height          float64    `json:"height"`
+	Length          float64    `json:"length"`
+	Width           float64    `json:"width"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
 }
 
 // parseToResponse is a function that parses a vehicle model to a vehicle response
 func (res *VehicleResponseJSON) parseModelToResponse(v internal.Vehicle) {
 	res.ID = v.Id
+	res.Version = v.Version
 	res.Brand = v.Brand
 	res.Model = v.Model
 	res.Registration = v.Registration
+	res.Country = v.Country
+	res.Vin = v.Vin
 	res.Color = v.Color
 	res.FabricationYear = v.FabricationYear
 	res.Capacity = v.Capacity
@@ -79,6 +99,78 @@ func (res *VehicleResponseJSON) parseModelToResponse(v internal.Vehicle) {
 	res.Height = v.Height
 	res.Length = v.Length
 	res.Width = v.Width
+	res.DeletedAt = v.DeletedAt
+}
+
+// VehicleSearchResponseJSON is a struct that represents a vehicle found by a search in JSON format
+type VehicleSearchResponseJSON struct {
+	VehicleResponseJSON
+	Score float64 `json:"score"`
+}
+
+// VehicleStatsResponseJSON is a struct that represents the statistics of a field in JSON format
+type VehicleStatsResponseJSON struct {
+	Count       int                `json:"count"`
+	Min         float64            `json:"min"`
+	Max         float64            `json:"max"`
+	Mean        float64            `json:"mean"`
+	Median      float64            `json:"median"`
+	Percentiles map[string]float64 `json:"percentiles,omitempty"`
+}
+
+// parseModelToResponse is a function that parses the statistics model to a statistics response
+func (res *VehicleStatsResponseJSON) parseModelToResponse(st internal.VehicleStats) {
+	res.Count = st.Count
+	res.Min = st.Min
+	res.Max = st.Max
+	res.Mean = st.Mean
+	res.Median = st.Median
+	if len(st.Percentiles) > 0 {
+		res.Percentiles = make(map[string]float64)
+		for p, value := range st.Percentiles {
+			res.Percentiles["p"+strconv.FormatFloat(p, 'f', -1, 64)] = value
+		}
+	}
+}
+
+// VehicleFacetResponseJSON is a struct that represents the number of vehicles with a value in JSON format
+type VehicleFacetResponseJSON struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// AuditEventResponseJSON is a struct that represents an audit event in JSON format
+type AuditEventResponseJSON struct {
+	Timestamp time.Time                          `json:"timestamp"`
+	Actor     string                             `json:"actor"`
+	Action    string                             `json:"action"`
+	VehicleId int                                `json:"vehicle_id"`
+	Changes   map[string]AuditChangeResponseJSON `json:"changes"`
+}
+
+// AuditChangeResponseJSON is a struct that represents the change of a field in JSON format
+type AuditChangeResponseJSON struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// parseAuditEventsToResponse is a function that parses audit events to their response
+func parseAuditEventsToResponse(events []internal.AuditEvent) (res []AuditEventResponseJSON) {
+	res = []AuditEventResponseJSON{}
+	for _, e := range events {
+		eventJSON := AuditEventResponseJSON{
+			Timestamp: e.Timestamp,
+			Actor:     e.Actor,
+			Action:    string(e.Action),
+			VehicleId: e.VehicleId,
+			Changes:   make(map[string]AuditChangeResponseJSON),
+		}
+		for field, change := range e.Changes {
+			eventJSON.Changes[field] = AuditChangeResponseJSON{Before: change.Before, After: change.After}
+		}
+		res = append(res, eventJSON)
+	}
+	return
 }
 
 type ResponseJSON struct {
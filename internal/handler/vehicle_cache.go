@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// newResponseCache is a function that returns a new empty responseCache
+func newResponseCache() *responseCache {
+	return &responseCache{entries: make(map[string]cachedResponse)}
+}
+
+// responseCache keeps the encoded responses of the listings until the next modification of the vehicles
+type responseCache struct {
+	// mu protects entries
+	mu sync.Mutex
+	// entries are the cached responses by request uri
+	entries map[string]cachedResponse
+}
+
+// cachedResponse is an encoded response of a revision of the vehicles
+type cachedResponse struct {
+	// revision is the revision of the vehicles the response was built from
+	revision uint64
+	// contentType is the header Content-Type of the response
+	contentType string
+	// body is the encoded body of the response
+	body []byte
+}
+
+// get returns the cached response of key, if it was built from revision
+func (c *responseCache) get(key string, revision uint64) (res cachedResponse, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	res, ok = c.entries[key]
+	if ok && res.revision != revision {
+		// the vehicles changed, so every entry is stale
+		clear(c.entries)
+		return res, false
+	}
+	return
+}
+
+// set caches the response of key
+func (c *responseCache) set(key string, res cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = res
+}
+
+// responseRecorder is a http.ResponseWriter that keeps a copy of the body written
+type responseRecorder struct {
+	http.ResponseWriter
+	// status is the status code written
+	status int
+	// body is a copy of the body written
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// EnableResponseCache makes the listings wrapped by Conditional keep their encoded responses
+// until the next modification of the vehicles
+func (h *VehicleDefault) EnableResponseCache() {
+	h.cache = newResponseCache()
+}
+
+// Conditional wraps a listing handler so that it emits the revision of the vehicles as ETag and Last-Modified,
+// and answers 304 Not Modified to If-None-Match and If-Modified-Since when nothing changed
+func (h *VehicleDefault) Conditional(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// get the current revision
+		rev, err := h.sv.Revision(r.Context())
+		if err != nil {
+			// serve without validators
+			next(w, r)
+			return
+		}
+
+		// validators
+		etag := revisionETag(rev.Number)
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", rev.ModifiedAt.UTC().Format(http.TimeFormat))
+		if notModified(r, etag, rev.ModifiedAt) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		if h.cache == nil {
+			next(w, r)
+			return
+		}
+
+		// serve from the cache
+		key := r.URL.RequestURI()
+		if res, ok := h.cache.get(key, rev.Number); ok {
+			w.Header().Set("Content-Type", res.contentType)
+			w.Header().Set("X-Cache", "HIT")
+			w.WriteHeader(http.StatusOK)
+			w.Write(res.body)
+			return
+		}
+
+		// serve and cache the successful responses
+		w.Header().Set("X-Cache", "MISS")
+		rec := &responseRecorder{ResponseWriter: w}
+		next(rec, r)
+		if rec.status == http.StatusOK {
+			h.cache.set(key, cachedResponse{
+				revision:    rev.Number,
+				contentType: w.Header().Get("Content-Type"),
+				body:        rec.body.Bytes(),
+			})
+		}
+	}
+}
+
+// revisionETag returns the ETag of a revision of the vehicles.
+// It is weak because the same revision can be encoded in different orders
+func revisionETag(revision uint64) string {
+	return "W/" + strconv.Quote("r"+strconv.FormatUint(revision, 10))
+}
+
+// notModified returns true if the validators of the request match the current etag and modification time.
+// If-None-Match takes precedence over If-Modified-Since
+func notModified(r *http.Request, etag string, modifiedAt time.Time) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		for _, candidate := range strings.Split(header, ",") {
+			candidate = strings.TrimSpace(candidate)
+			// weak comparison
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if header := r.Header.Get("If-Modified-Since"); header != "" {
+		since, err := http.ParseTime(header)
+		if err != nil {
+			return false
+		}
+		return !modifiedAt.Truncate(time.Second).After(since)
+	}
+
+	return false
+}
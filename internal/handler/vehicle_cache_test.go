@@ -0,0 +1,107 @@
+package handler_test
+
+import (
+	"app/internal"
+	"app/internal/handler"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestVehicleDefault_Conditional(t *testing.T) {
+	db := map[int]internal.Vehicle{
+		1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Registration: "A1", Capacity: 5}},
+		2: {Id: 2, VehicleAttributes: internal.VehicleAttributes{Brand: "Fiat", Registration: "A2", Capacity: 4}},
+	}
+	sv := service.NewVehicleDefault(repository.NewVehicleMap(db, nil), nil, nil, nil)
+	hd := handler.NewVehicleDefault(sv, nil)
+	hd.EnableResponseCache()
+	listing := hd.Conditional(hd.GetAll())
+
+	// get requests the listing with the headers given as name and value pairs
+	get := func(uri string, headers ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", uri, nil)
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		res := httptest.NewRecorder()
+		listing(res, req)
+		return res
+	}
+
+	// - the first request builds the response, the second one is served from the cache with the same body
+	first := get("/vehicles")
+	if first.Code != http.StatusOK || first.Header().Get("X-Cache") != "MISS" {
+		t.Fatalf("first GET = %d, X-Cache %q, want 200 and MISS", first.Code, first.Header().Get("X-Cache"))
+	}
+	etag, lastModified := first.Header().Get("ETag"), first.Header().Get("Last-Modified")
+	if etag == "" || lastModified == "" {
+		t.Fatalf("first GET validators = %q and %q, want both", etag, lastModified)
+	}
+	second := get("/vehicles")
+	if second.Code != http.StatusOK || second.Header().Get("X-Cache") != "HIT" || second.Body.String() != first.Body.String() {
+		t.Errorf("second GET = %d, X-Cache %q, want 200 and HIT with the first body", second.Code, second.Header().Get("X-Cache"))
+	}
+	if ct := second.Header().Get("Content-Type"); ct != first.Header().Get("Content-Type") {
+		t.Errorf("second GET Content-Type = %q, want %q", ct, first.Header().Get("Content-Type"))
+	}
+
+	// - the validators of the request
+	modifiedAt, err := http.ParseTime(lastModified)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		headers []string
+		code    int
+	}{
+		{name: "matching etag", headers: []string{"If-None-Match", etag}, code: http.StatusNotModified},
+		{name: "matching etag in a list", headers: []string{"If-None-Match", `W/"r99", ` + etag}, code: http.StatusNotModified},
+		{name: "any etag", headers: []string{"If-None-Match", "*"}, code: http.StatusNotModified},
+		{name: "other etag", headers: []string{"If-None-Match", `W/"r99"`}, code: http.StatusOK},
+		{name: "other etag over a matching time", headers: []string{"If-None-Match", `W/"r99"`, "If-Modified-Since", lastModified}, code: http.StatusOK},
+		{name: "modified at the time", headers: []string{"If-Modified-Since", lastModified}, code: http.StatusNotModified},
+		{name: "modified before a later time", headers: []string{"If-Modified-Since", modifiedAt.Add(time.Hour).Format(http.TimeFormat)}, code: http.StatusNotModified},
+		{name: "modified after an earlier time", headers: []string{"If-Modified-Since", modifiedAt.Add(-time.Second).Format(http.TimeFormat)}, code: http.StatusOK},
+		{name: "invalid time", headers: []string{"If-Modified-Since", "yesterday"}, code: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := get("/vehicles", tt.headers...)
+			if res.Code != tt.code {
+				t.Errorf("GET with %v = %d, want %d", tt.headers, res.Code, tt.code)
+			}
+			if tt.code == http.StatusNotModified && (res.Body.Len() > 0 || res.Header().Get("ETag") != etag) {
+				t.Errorf("GET with %v = %q, ETag %q, want no body and the ETag %s", tt.headers, res.Body, res.Header().Get("ETag"), etag)
+			}
+		})
+	}
+
+	// - the error responses are not cached
+	for i := 0; i < 2; i++ {
+		res := get("/vehicles?filter=colour=red")
+		if res.Code != http.StatusBadRequest || res.Header().Get("X-Cache") != "MISS" {
+			t.Errorf("GET of an invalid filter %d = %d, X-Cache %q, want 400 and MISS", i+1, res.Code, res.Header().Get("X-Cache"))
+		}
+	}
+
+	// - a modification changes the revision, so the validators do not match and the cache is stale
+	if _, err := sv.Add(context.Background(), internal.Vehicle{VehicleAttributes: internal.VehicleAttributes{Brand: "GMC", Model: "Sierra", Registration: "A3", FabricationYear: 2000}}); err != nil {
+		t.Fatal(err)
+	}
+	third := get("/vehicles", "If-None-Match", etag)
+	if third.Code != http.StatusOK || third.Header().Get("X-Cache") != "MISS" || third.Body.String() == first.Body.String() {
+		t.Errorf("GET with the previous etag = %d, X-Cache %q, want 200 and MISS with the new vehicle", third.Code, third.Header().Get("X-Cache"))
+	}
+	if newETag := third.Header().Get("ETag"); newETag == etag {
+		t.Errorf("ETag after a modification = %s, want another one", newETag)
+	}
+	if res := get("/vehicles"); res.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("GET after the rebuild X-Cache = %q, want HIT", res.Header().Get("X-Cache"))
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	// errMissingIfMatch is returned when a write request does not have the header If-Match
+	errMissingIfMatch = errors.New("header If-Match is required")
+	// errInvalidIfMatch is returned when the header If-Match is not an ETag of a vehicle
+	errInvalidIfMatch = errors.New("header If-Match is not a valid ETag")
+	// errWeakIfMatch is returned when the header If-Match is a weak ETag, that never matches
+	// because If-Match uses the strong comparison (RFC 9110, section 13.1.1)
+	errWeakIfMatch = errors.New("header If-Match is a weak ETag")
+)
+
+// vehicleETag returns the ETag of a version of a vehicle
+func vehicleETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// parseIfMatch returns the version of the vehicle in the header If-Match
+func parseIfMatch(r *http.Request) (version int, err error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return 0, errMissingIfMatch
+	}
+
+	// - a weak ETag is valid syntax but it does not match any version
+	weak := strings.HasPrefix(header, "W/")
+	header = strings.TrimPrefix(header, "W/")
+
+	unquoted, err := strconv.Unquote(header)
+	if err != nil {
+		return 0, errInvalidIfMatch
+	}
+	version, err = strconv.Atoi(unquoted)
+	if err != nil || version <= 0 {
+		return 0, errInvalidIfMatch
+	}
+	if weak {
+		return 0, errWeakIfMatch
+	}
+	return
+}
+
+// writeIfMatchError writes the response for an invalid header If-Match
+func writeIfMatchError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errWeakIfMatch) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusPreconditionFailed)
+		json.NewEncoder(w).Encode(ResponseJSON{
+			Message: "El ETag debe ser fuerte, un ETag debil nunca coincide con el del vehiculo",
+		})
+		return
+	}
+	if errors.Is(err, errMissingIfMatch) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusPreconditionRequired)
+		json.NewEncoder(w).Encode(ResponseJSON{
+			Message: "Se requiere el header If-Match con el ETag del vehiculo",
+		})
+		return
+	}
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(ResponseJSON{
+		Message: "Header If-Match invalido",
+	})
+}
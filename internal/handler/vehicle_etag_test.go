@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		version int
+		wantErr error
+		code    int
+	}{
+		{name: "strong", header: `"2"`, version: 2},
+		{name: "strong with spaces", header: ` "15" `, version: 15},
+		{name: "missing", header: "", wantErr: errMissingIfMatch, code: http.StatusPreconditionRequired},
+		{name: "weak", header: `W/"2"`, wantErr: errWeakIfMatch, code: http.StatusPreconditionFailed},
+		{name: "weak not a version", header: `W/"abc"`, wantErr: errInvalidIfMatch, code: http.StatusBadRequest},
+		{name: "lowercase weak prefix", header: `w/"2"`, wantErr: errInvalidIfMatch, code: http.StatusBadRequest},
+		{name: "unquoted", header: `2`, wantErr: errInvalidIfMatch, code: http.StatusBadRequest},
+		{name: "not a version", header: `"abc"`, wantErr: errInvalidIfMatch, code: http.StatusBadRequest},
+		{name: "zero", header: `"0"`, wantErr: errInvalidIfMatch, code: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("PUT", "/vehicles/1", nil)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+
+			version, err := parseIfMatch(r)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parseIfMatch(%q) error = %v, want %v", tt.header, err, tt.wantErr)
+			}
+			if err == nil {
+				if version != tt.version {
+					t.Errorf("parseIfMatch(%q) = %d, want %d", tt.header, version, tt.version)
+				}
+				return
+			}
+
+			w := httptest.NewRecorder()
+			writeIfMatchError(w, err)
+			if w.Code != tt.code {
+				t.Errorf("writeIfMatchError(%v) = %d, want %d", err, w.Code, tt.code)
+			}
+		})
+	}
+}
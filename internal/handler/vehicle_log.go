@@ -0,0 +1,12 @@
+package handler
+
+import "net/http"
+
+// logServerError logs the underlying error of a response with status 500, that is not shown to the client
+func (h *VehicleDefault) logServerError(r *http.Request, err error) {
+	h.lg.ErrorContext(r.Context(), "request failed",
+		"method", r.Method,
+		"path", r.URL.Path,
+		"error", err,
+	)
+}
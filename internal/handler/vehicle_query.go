@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"app/internal"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// parseEqualFilter parses an internal.EqualFilter from the query params of the request.
+// Every param is optional and the bounds of the ranges can be omitted, e.g.
+// ?brand=Ford&passengers=0&year_min=2000&weight_max=100&include_deleted=true
+// isSet is true if at least one filter was present
+func parseEqualFilter(r *http.Request) (filter internal.EqualFilter, isSet bool, err error) {
+	query := r.URL.Query()
+
+	// equality
+	filter.Brand = query.Get("brand")
+	filter.Model = query.Get("model")
+	filter.Color = query.Get("color")
+	filter.FuelType = query.Get("fuel_type")
+	filter.Transmission = query.Get("transmission")
+	if filter.FabricationYear, err = queryInt(r, "year"); err != nil {
+		return
+	}
+	if filter.Capacity, err = queryInt(r, "passengers"); err != nil {
+		return
+	}
+	if filter.Fuzzy, err = queryBool(r, "fuzzy"); err != nil {
+		return
+	}
+	if filter.IncludeDeleted, err = queryBool(r, "include_deleted"); err != nil {
+		return
+	}
+
+	// ranges
+	if filter.FabricationYearRange, err = parseRangeInt(r, "year_min", "year_max"); err != nil {
+		return
+	}
+	if filter.LengthRange, err = parseRangeFloat(r, "length_min", "length_max"); err != nil {
+		return
+	}
+	if filter.WidthRange, err = parseRangeFloat(r, "width_min", "width_max"); err != nil {
+		return
+	}
+	if filter.WeightRange, err = parseRangeFloat(r, "weight_min", "weight_max"); err != nil {
+		return
+	}
+
+	isSet = filter.Brand != "" || filter.Model != "" || filter.Color != "" ||
+		filter.FuelType != "" || filter.Transmission != "" || filter.IncludeDeleted ||
+		filter.FabricationYear != nil || filter.Capacity != nil ||
+		filter.FabricationYearRange.IsSet() || filter.LengthRange.IsSet() ||
+		filter.WidthRange.IsSet() || filter.WeightRange.IsSet()
+	return
+}
+
+// parseRangeFloat parses an open-ended range from the query params minKey and maxKey.
+// A missing or empty param leaves that bound unset
+func parseRangeFloat(r *http.Request, minKey, maxKey string) (rg internal.Range[float64], err error) {
+	rg.Min, err = queryFloat(r, minKey)
+	if err != nil {
+		return
+	}
+	rg.Max, err = queryFloat(r, maxKey)
+	return
+}
+
+// parseRangeInt parses an open-ended range from the query params minKey and maxKey.
+// A missing or empty param leaves that bound unset
+func parseRangeInt(r *http.Request, minKey, maxKey string) (rg internal.Range[int], err error) {
+	rg.Min, err = queryInt(r, minKey)
+	if err != nil {
+		return
+	}
+	rg.Max, err = queryInt(r, maxKey)
+	return
+}
+
+// queryFloat returns the query param key parsed as a finite float, or nil if it is not present
+func queryFloat(r *http.Request, key string) (f *float64, err error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, ok := parseFinite(value)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a number", key)
+	}
+	return &parsed, nil
+}
+
+// parseFinite parses a float, rejecting NaN and the infinities that strconv.ParseFloat accepts
+func parseFinite(value string) (f float64, ok bool) {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f, true
+}
+
+// queryInt returns the query param key parsed as an int, or nil if it is not present
+func queryInt(r *http.Request, key string) (i *int, err error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an integer", key)
+	}
+	return &parsed, nil
+}
+
+// queryFloatList returns the query param key parsed as a comma separated list of finite floats, e.g. ?percentiles=90,95
+func queryFloatList(r *http.Request, key string) (list []float64, err error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return nil, nil
+	}
+	for _, item := range strings.Split(value, ",") {
+		parsed, ok := parseFinite(strings.TrimSpace(item))
+		if !ok {
+			return nil, fmt.Errorf("%s must be a list of numbers", key)
+		}
+		list = append(list, parsed)
+	}
+	return
+}
+
+// queryBool returns the query param key parsed as a bool, or false if it is not present
+func queryBool(r *http.Request, key string) (b bool, err error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return false, nil
+	}
+	b, err = strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be a boolean", key)
+	}
+	return
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"app/internal"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestParseEqualFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		filter  internal.EqualFilter
+		isSet   bool
+		wantErr bool
+	}{
+		{name: "no params", query: "", isSet: false},
+		{name: "unknown params are ignored", query: "page=2", isSet: false},
+
+		// - text fields, passed as they are to be compared ignoring case and accents
+		{name: "brand", query: "brand=Ford", filter: internal.EqualFilter{Brand: "Ford"}, isSet: true},
+		{name: "brand in other case", query: "brand=fORD", filter: internal.EqualFilter{Brand: "fORD"}, isSet: true},
+		{name: "model", query: "model=Citro%C3%ABn+C3", filter: internal.EqualFilter{Model: "Citroën C3"}, isSet: true},
+		{name: "color", query: "color=red", filter: internal.EqualFilter{Color: "red"}, isSet: true},
+		{name: "fuel type", query: "fuel_type=DIESEL", filter: internal.EqualFilter{FuelType: "DIESEL"}, isSet: true},
+		{name: "transmission", query: "transmission=manual", filter: internal.EqualFilter{Transmission: "manual"}, isSet: true},
+		{name: "fuzzy alone is not a filter", query: "fuzzy=true", filter: internal.EqualFilter{Fuzzy: true}, isSet: false},
+		{name: "fuzzy invalid", query: "fuzzy=maybe", wantErr: true},
+		{name: "include deleted", query: "include_deleted=1", filter: internal.EqualFilter{IncludeDeleted: true}, isSet: true},
+
+		// - numeric fields, zero is a value
+		{name: "year", query: "year=2015", filter: internal.EqualFilter{FabricationYear: ptr(2015)}, isSet: true},
+		{name: "year invalid", query: "year=dos+mil", wantErr: true},
+		{name: "passengers zero", query: "passengers=0", filter: internal.EqualFilter{Capacity: ptr(0)}, isSet: true},
+		{name: "passengers invalid", query: "passengers=2.5", wantErr: true},
+
+		// - ranges, inclusive and open-ended; min>max is rejected by the service, not the parser
+		{name: "year range", query: "year_min=2000&year_max=2010", filter: internal.EqualFilter{FabricationYearRange: internal.Range[int]{Min: ptr(2000), Max: ptr(2010)}}, isSet: true},
+		{name: "year range open max", query: "year_min=2000", filter: internal.EqualFilter{FabricationYearRange: internal.Range[int]{Min: ptr(2000)}}, isSet: true},
+		{name: "year range open min", query: "year_max=2010", filter: internal.EqualFilter{FabricationYearRange: internal.Range[int]{Max: ptr(2010)}}, isSet: true},
+		{name: "year range empty bound", query: "year_min=&year_max=2010", filter: internal.EqualFilter{FabricationYearRange: internal.Range[int]{Max: ptr(2010)}}, isSet: true},
+		{name: "year range min greater than max", query: "year_min=2011&year_max=2010", filter: internal.EqualFilter{FabricationYearRange: internal.Range[int]{Min: ptr(2011), Max: ptr(2010)}}, isSet: true},
+		{name: "year range invalid", query: "year_min=2000.5", wantErr: true},
+		{name: "length range", query: "length_min=3.5&length_max=4.5", filter: internal.EqualFilter{LengthRange: internal.Range[float64]{Min: ptr(3.5), Max: ptr(4.5)}}, isSet: true},
+		{name: "length range open max", query: "length_min=3.5", filter: internal.EqualFilter{LengthRange: internal.Range[float64]{Min: ptr(3.5)}}, isSet: true},
+		{name: "length range open min", query: "length_max=4.5", filter: internal.EqualFilter{LengthRange: internal.Range[float64]{Max: ptr(4.5)}}, isSet: true},
+		{name: "length range invalid", query: "length_max=long", wantErr: true},
+		{name: "width range", query: "width_min=1.5&width_max=2", filter: internal.EqualFilter{WidthRange: internal.Range[float64]{Min: ptr(1.5), Max: ptr(2.0)}}, isSet: true},
+		{name: "width range open max", query: "width_min=1.5", filter: internal.EqualFilter{WidthRange: internal.Range[float64]{Min: ptr(1.5)}}, isSet: true},
+		{name: "width range open min", query: "width_max=2", filter: internal.EqualFilter{WidthRange: internal.Range[float64]{Max: ptr(2.0)}}, isSet: true},
+		{name: "width range invalid", query: "width_min=wide", wantErr: true},
+		{name: "weight range zero", query: "weight_min=0&weight_max=0", filter: internal.EqualFilter{WeightRange: internal.Range[float64]{Min: ptr(0.0), Max: ptr(0.0)}}, isSet: true},
+		{name: "weight range open max", query: "weight_min=100", filter: internal.EqualFilter{WeightRange: internal.Range[float64]{Min: ptr(100.0)}}, isSet: true},
+		{name: "weight range open min", query: "weight_max=100", filter: internal.EqualFilter{WeightRange: internal.Range[float64]{Max: ptr(100.0)}}, isSet: true},
+		{name: "weight range invalid", query: "weight_max=heavy", wantErr: true},
+		{name: "weight range NaN", query: "weight_min=NaN", wantErr: true},
+		{name: "length range infinite", query: "length_max=Inf", wantErr: true},
+		{name: "width range negative infinite", query: "width_min=-Infinity", wantErr: true},
+
+		// - combinations
+		{name: "text and range", query: "brand=Ford&year_min=2000&weight_max=1500", filter: internal.EqualFilter{
+			Brand:                "Ford",
+			FabricationYearRange: internal.Range[int]{Min: ptr(2000)},
+			WeightRange:          internal.Range[float64]{Max: ptr(1500.0)},
+		}, isSet: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/vehicles?"+tt.query, nil)
+
+			filter, isSet, err := parseEqualFilter(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseEqualFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if isSet != tt.isSet {
+				t.Errorf("parseEqualFilter() isSet = %v, want %v", isSet, tt.isSet)
+			}
+			if !reflect.DeepEqual(filter, tt.filter) {
+				t.Errorf("parseEqualFilter() filter = %+v, want %+v", filter, tt.filter)
+			}
+		})
+	}
+}
+
+func TestQueryFloatList(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		list    []float64
+		wantErr bool
+	}{
+		{name: "absent", query: "", list: nil},
+		{name: "one", query: "percentiles=90", list: []float64{90}},
+		{name: "several with spaces", query: "percentiles=50,+90.5,99", list: []float64{50, 90.5, 99}},
+		{name: "not a number", query: "percentiles=90,high", wantErr: true},
+		{name: "empty item", query: "percentiles=90,", wantErr: true},
+		{name: "NaN", query: "percentiles=NaN", wantErr: true},
+		{name: "NaN in the list", query: "percentiles=50,nan", wantErr: true},
+		{name: "infinite", query: "percentiles=+Inf", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/vehicles/stats?"+tt.query, nil)
+
+			list, err := queryFloatList(r, "percentiles")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("queryFloatList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(list, tt.list) {
+				t.Errorf("queryFloatList() = %v, want %v", list, tt.list)
+			}
+		})
+	}
+}
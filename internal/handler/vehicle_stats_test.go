@@ -0,0 +1,44 @@
+package handler_test
+
+import (
+	"app/internal"
+	"app/internal/handler"
+	"app/internal/repository"
+	"app/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVehicleDefault_GetStats(t *testing.T) {
+	db := map[int]internal.Vehicle{
+		1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Registration: "A1", Weight: 1000}},
+		2: {Id: 2, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Registration: "A2", Weight: 2000}},
+	}
+	sv := service.NewVehicleDefault(repository.NewVehicleMap(db, nil), nil, nil, nil)
+	hd := handler.NewVehicleDefault(sv, nil)
+
+	tests := []struct {
+		name  string
+		query string
+		code  int
+	}{
+		{name: "percentiles", query: "field=weight&percentiles=50,90", code: http.StatusOK},
+		{name: "percentile out of range", query: "field=weight&percentiles=101", code: http.StatusBadRequest},
+		{name: "percentile NaN", query: "field=weight&percentiles=NaN", code: http.StatusBadRequest},
+		{name: "percentile infinite", query: "field=weight&percentiles=-Inf", code: http.StatusBadRequest},
+		{name: "range bound NaN", query: "field=weight&weight_min=NaN", code: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/vehicles/stats?"+tt.query, nil)
+			res := httptest.NewRecorder()
+
+			hd.GetStats(res, req)
+			if res.Code != tt.code {
+				t.Errorf("GET /vehicles/stats?%s = %d, want %d: %s", tt.query, res.Code, tt.code, res.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Checker is a dependency that can report whether it is available, e.g. a database
+type Checker interface {
+	// Ping returns an error if the dependency is not available
+	Ping(ctx context.Context) (err error)
+}
+
+// NewStatus is a function that returns a new instance of Status, not ready
+func NewStatus() *Status {
+	return &Status{checks: make(map[string]Checker)}
+}
+
+// Status is a struct that keeps the state of the application for the orchestrator
+type Status struct {
+	// mu protects the fields
+	mu sync.Mutex
+	// loaded is true once the vehicles were loaded
+	loaded bool
+	// reloading is true while the vehicles are being reloaded
+	reloading bool
+	// checks are the dependencies checked by the readiness probe, by name
+	checks map[string]Checker
+}
+
+// AddCheck adds a dependency to the readiness probe
+func (s *Status) AddCheck(name string, c Checker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checks[name] = c
+}
+
+// BeginLoad marks that the vehicles are being loaded or reloaded, so the application is not ready
+func (s *Status) BeginLoad() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reloading = true
+}
+
+// EndLoad marks the end of a load, that is loaded if err is nil. A failed reload keeps the previous vehicles
+func (s *Status) EndLoad(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reloading = false
+	if err == nil {
+		s.loaded = true
+	}
+}
+
+// Loaded returns true once the vehicles were loaded
+func (s *Status) Loaded() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.loaded
+}
+
+// checkTimeout is the maximum time of each check of the readiness probe
+const checkTimeout = 2 * time.Second
+
+// ready returns the state of each condition of the readiness, and true if all of them are ok
+func (s *Status) ready(ctx context.Context) (conditions map[string]string, ok bool) {
+	s.mu.Lock()
+	loaded, reloading := s.loaded, s.reloading
+	checks := make(map[string]Checker, len(s.checks))
+	for name, c := range s.checks {
+		checks[name] = c
+	}
+	s.mu.Unlock()
+
+	ok = true
+	conditions = make(map[string]string)
+	switch {
+	case !loaded:
+		conditions["loader"], ok = "loading", false
+	case reloading:
+		conditions["loader"], ok = "reloading", false
+	default:
+		conditions["loader"] = "ok"
+	}
+
+	for name, c := range checks {
+		ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+		err := c.Ping(ctx)
+		cancel()
+		if err != nil {
+			conditions[name], ok = err.Error(), false
+			continue
+		}
+		conditions[name] = "ok"
+	}
+	return
+}
+
+// responseJSON is the body of the responses, with the same shape of the responses of the handlers
+type responseJSON struct {
+	Message string `json:"message"`
+	Data    any    `json:"data"`
+}
+
+// writeJSON writes a response
+func writeJSON(w http.ResponseWriter, status int, message string, data any) {
+	w.Header().Add("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(responseJSON{Message: message, Data: data})
+}
+
+// Healthz is the liveness probe, it answers 200 while the process can serve requests
+func (s *Status) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, "ok", nil)
+}
+
+// Readyz is the readiness probe, it answers 200 when the vehicles are loaded, no reload is in progress
+// and every dependency is available, 503 otherwise
+func (s *Status) Readyz(w http.ResponseWriter, r *http.Request) {
+	conditions, ok := s.ready(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusServiceUnavailable, "not ready", conditions)
+		return
+	}
+	writeJSON(w, http.StatusOK, "ready", conditions)
+}
+
+// RequireLoaded is a middleware that answers 503 until the vehicles were loaded for the first time
+func (s *Status) RequireLoaded(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.Loaded() {
+			w.Header().Set("Retry-After", "1")
+			writeJSON(w, http.StatusServiceUnavailable, "El servicio todavia esta cargando los vehiculos", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// BuildInfoJSON is the build information of the binary
+type BuildInfoJSON struct {
+	Path      string `json:"path"`
+	Version   string `json:"version"`
+	GoVersion string `json:"go_version"`
+	Revision  string `json:"revision,omitempty"`
+	Time      string `json:"time,omitempty"`
+	Modified  bool   `json:"modified"`
+}
+
+// BuildInfo returns the build information of the binary, from debug.ReadBuildInfo
+func BuildInfo() (info BuildInfoJSON) {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return BuildInfoJSON{Version: "unknown"}
+	}
+
+	info = BuildInfoJSON{
+		Path:      bi.Main.Path,
+		Version:   bi.Main.Version,
+		GoVersion: bi.GoVersion,
+	}
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Revision = setting.Value
+		case "vcs.time":
+			info.Time = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return
+}
+
+// Version answers the build information of the binary
+func Version(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, "success", BuildInfo())
+}
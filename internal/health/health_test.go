@@ -0,0 +1,83 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// checkerFunc is a Checker that calls the function
+type checkerFunc func(ctx context.Context) error
+
+func (f checkerFunc) Ping(ctx context.Context) error { return f(ctx) }
+
+func TestStatus_Readyz(t *testing.T) {
+	ok := checkerFunc(func(ctx context.Context) error { return nil })
+	down := checkerFunc(func(ctx context.Context) error { return errors.New("connection refused") })
+
+	tests := []struct {
+		name           string
+		setup          func(st *Status)
+		wantCode       int
+		wantConditions map[string]string
+	}{
+		{name: "loading", setup: func(st *Status) { st.BeginLoad() },
+			wantCode: http.StatusServiceUnavailable, wantConditions: map[string]string{"loader": "loading"}},
+		{name: "loaded", setup: func(st *Status) { st.BeginLoad(); st.EndLoad(nil) },
+			wantCode: http.StatusOK, wantConditions: map[string]string{"loader": "ok"}},
+		{name: "first load failed", setup: func(st *Status) { st.BeginLoad(); st.EndLoad(errors.New("no file")) },
+			wantCode: http.StatusServiceUnavailable, wantConditions: map[string]string{"loader": "loading"}},
+		{name: "reloading", setup: func(st *Status) { st.EndLoad(nil); st.BeginLoad() },
+			wantCode: http.StatusServiceUnavailable, wantConditions: map[string]string{"loader": "reloading"}},
+		{name: "reload failed keeps the vehicles", setup: func(st *Status) { st.EndLoad(nil); st.BeginLoad(); st.EndLoad(errors.New("no file")) },
+			wantCode: http.StatusOK, wantConditions: map[string]string{"loader": "ok"}},
+		{name: "dependency available", setup: func(st *Status) { st.EndLoad(nil); st.AddCheck("db", ok) },
+			wantCode: http.StatusOK, wantConditions: map[string]string{"loader": "ok", "db": "ok"}},
+		{name: "dependency down", setup: func(st *Status) { st.EndLoad(nil); st.AddCheck("db", ok); st.AddCheck("cache", down) },
+			wantCode: http.StatusServiceUnavailable, wantConditions: map[string]string{"loader": "ok", "db": "ok", "cache": "connection refused"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := NewStatus()
+			tt.setup(st)
+
+			res := httptest.NewRecorder()
+			st.Readyz(res, httptest.NewRequest("GET", "/readyz", nil))
+
+			var body struct {
+				Data map[string]string `json:"data"`
+			}
+			if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if res.Code != tt.wantCode || !reflect.DeepEqual(body.Data, tt.wantConditions) {
+				t.Errorf("Readyz() = %d %v, want %d %v", res.Code, body.Data, tt.wantCode, tt.wantConditions)
+			}
+		})
+	}
+}
+
+func TestStatus_RequireLoaded(t *testing.T) {
+	st := NewStatus()
+	h := st.RequireLoaded(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, httptest.NewRequest("GET", "/vehicles", nil))
+	if res.Code != http.StatusServiceUnavailable || res.Header().Get("Retry-After") != "1" {
+		t.Errorf("before the load = %d, want 503 with Retry-After", res.Code)
+	}
+
+	// - a reload does not stop the requests, the previous vehicles are served
+	st.EndLoad(nil)
+	st.BeginLoad()
+	res = httptest.NewRecorder()
+	h.ServeHTTP(res, httptest.NewRequest("GET", "/vehicles", nil))
+	if res.Code != http.StatusOK {
+		t.Errorf("after the load = %d, want 200", res.Code)
+	}
+}
@@ -0,0 +1,231 @@
+// Package lint checks the files of vehicles with the rules of the service and repairs them
+package lint
+
+import (
+	"app/internal"
+	"app/internal/loader"
+	"app/internal/service"
+	"app/internal/vin"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Severity is the severity of a problem
+type Severity string
+
+const (
+	// SeverityError is a problem that the service or the repository would reject
+	SeverityError Severity = "error"
+	// SeverityWarning is a problem that is accepted but likely wrong, e.g. a missing dimension or a negative weight
+	SeverityWarning Severity = "warning"
+)
+
+// Problem is a problem of a record of a file
+type Problem struct {
+	// Position is the position of the record in the file, see loader.VehicleRecord
+	Position int `json:"position"`
+	// Id is the id of the record
+	Id int `json:"id"`
+	// Field is the name of the field with the problem in the files
+	Field    string   `json:"field"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// attrFields are the names in the files of the attributes returned by service.Validate
+var attrFields = map[string]string{
+	"Brand":           "brand",
+	"Model":           "model",
+	"Registration":    "registration",
+	"Country":         "country",
+	"Vin":             "vin",
+	"FabricationYear": "year",
+	"Capacity":        "passengers",
+	"MaxSpeed":        "max_speed",
+	"Weight":          "weight",
+	"Height":          "height",
+	"Length":          "length",
+	"Width":           "width",
+}
+
+// optional are the fields that can be missing without a problem
+var optional = map[string]bool{"country": true, "vin": true}
+
+// Check returns the problems of the records, in the order of the records.
+// rv validates the registrations by country, it can be nil to accept any registration
+func Check(records []loader.VehicleRecord, rv internal.RegistrationValidator) (problems []Problem) {
+	ids := make(map[int]int)
+	registrations := make(map[string]int)
+	vins := make(map[string]int)
+
+	for _, r := range records {
+		v := r.Vehicle
+		report := func(field string, severity Severity, format string, args ...any) {
+			problems = append(problems, Problem{
+				Position: r.Position,
+				Id:       v.Id,
+				Field:    field,
+				Severity: severity,
+				Message:  fmt.Sprintf(format, args...),
+			})
+		}
+
+		// - values that could not be read
+		for _, name := range sortedKeys(r.Invalid) {
+			report(name, SeverityError, "invalid value: %s", r.Invalid[name])
+		}
+
+		// - rules of the service, the missing values are errors if the service requires them
+		invalid := make(map[string]bool)
+		for _, errInv := range service.Validate(v.VehicleAttributes) {
+			invalid[attrFields[errInv.Attr]] = true
+		}
+		var errInv *internal.ErrInvalidAttributes
+		if rv != nil && errors.As(rv.Validate(v.Country, v.Registration), &errInv) && !invalid["registration"] {
+			if field := attrFields[errInv.Attr]; field == "country" {
+				report(field, SeverityError, "unknown country %q", v.Country)
+			} else {
+				country := "the default country"
+				if v.Country != "" {
+					country = v.Country
+				}
+				report(field, SeverityError, "%q is not a registration of %s", v.Registration, country)
+			}
+		}
+		for _, name := range r.Missing {
+			if optional[name] {
+				continue
+			}
+			if name == "id" || invalid[name] {
+				report(name, SeverityError, "missing value")
+				continue
+			}
+			report(name, SeverityWarning, "missing value")
+		}
+		for _, field := range loader.VehicleFields {
+			if !invalid[field] || r.IsMissing(field) || r.Invalid[field] != nil {
+				continue
+			}
+			if field == "vin" {
+				report(field, SeverityError, "invalid value %q: %v", v.Vin, vin.Check(vin.Normalize(v.Vin), v.Brand, v.FabricationYear))
+				continue
+			}
+			report(field, SeverityError, "invalid value %q", value(v, field))
+		}
+
+		// - values the service accepts but are likely wrong, and magnitudes it accepts as unknown
+		for _, field := range loader.VehicleFields {
+			if r.IsMissing(field) || r.Invalid[field] != nil || invalid[field] {
+				continue
+			}
+			if reason := implausible(v, field); reason != "" {
+				report(field, SeverityWarning, "%s", reason)
+			}
+		}
+		for _, field := range []string{"max_speed", "weight", "height", "length", "width"} {
+			if !r.IsMissing(field) && r.Invalid[field] == nil && value(v, field) == "0" {
+				report(field, SeverityWarning, "unknown value 0")
+			}
+		}
+
+		// - uniqueness of the ids, registrations and vins, like the repository
+		if v.Id <= 0 && !r.IsMissing("id") {
+			report("id", SeverityError, "id %d is not positive", v.Id)
+		}
+		if first, ok := ids[v.Id]; ok && v.Id > 0 {
+			report("id", SeverityError, "id %d is duplicated, first at %d", v.Id, first)
+		} else if !ok {
+			ids[v.Id] = r.Position
+		}
+		registration := internal.NormalizeRegistration(v.Registration)
+		if first, ok := registrations[registration]; ok && registration != "" {
+			report("registration", SeverityError, "registration %q is duplicated, first at %d", v.Registration, first)
+		} else if !ok {
+			registrations[registration] = r.Position
+		}
+		number := vin.Normalize(v.Vin)
+		if first, ok := vins[number]; ok && number != "" {
+			report("vin", SeverityError, "vin %q is duplicated, first at %d", v.Vin, first)
+		} else if !ok {
+			vins[number] = r.Position
+		}
+
+		// - normalization of the registrations, vins and categories
+		for _, field := range []string{"registration", "country", "vin", "color", "fuel_type", "transmission"} {
+			current := value(v, field)
+			if normalized := normalize(field, current); normalized != current {
+				report(field, SeverityWarning, "%q is not normalized, it should be %q", current, normalized)
+			}
+		}
+	}
+	return
+}
+
+// Count returns the number of problems of each severity
+func Count(problems []Problem) (errors, warnings int) {
+	for _, p := range problems {
+		if p.Severity == SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	return
+}
+
+// implausible returns why the value of a field is likely wrong although the service accepts it, empty if it is not:
+// a blank brand, model or registration, a fabrication year after the next one or a negative magnitude
+func implausible(v internal.Vehicle, field string) string {
+	current := value(v, field)
+	switch field {
+	case "brand", "model", "registration":
+		if strings.TrimSpace(current) == "" {
+			return "blank value"
+		}
+	case "year":
+		if v.FabricationYear > time.Now().Year()+1 {
+			return fmt.Sprintf("year %d is after the next year", v.FabricationYear)
+		}
+	case "passengers", "max_speed", "weight", "height", "length", "width":
+		if strings.HasPrefix(current, "-") {
+			return fmt.Sprintf("negative value %s", current)
+		}
+	}
+	return ""
+}
+
+// normalize returns the normalized value of a field: the registrations, countries and vins like the service,
+// the colors capitalized, the fuel types and transmissions in lowercase
+func normalize(field, value string) string {
+	switch field {
+	case "registration":
+		return internal.NormalizeRegistration(value)
+	case "country":
+		return internal.NormalizeCountry(value)
+	case "vin":
+		return vin.Normalize(value)
+	}
+
+	words := strings.Fields(strings.ToLower(value))
+	if field == "color" {
+		for i, w := range words {
+			first, size := utf8.DecodeRuneInString(w)
+			words[i] = string(unicode.ToUpper(first)) + w[size:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// sortedKeys returns the keys of a map sorted
+func sortedKeys[T any](m map[string]T) (keys []string) {
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return
+}
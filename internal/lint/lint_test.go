@@ -0,0 +1,147 @@
+package lint
+
+import (
+	"app/internal/loader"
+	"app/internal/registration"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// valid is a record without problems, in JSON
+const valid = `{"id":1,"brand":"Ford","model":"Fiesta","registration":"AA100AA","color":"Red","year":2010,"passengers":5,` +
+	`"max_speed":170,"fuel_type":"gasoline","transmission":"manual","weight":1100,"height":1.5,"length":4,"width":1.7}`
+
+// readRecords reads the records of an array of vehicles in JSON
+func readRecords(t *testing.T, records ...string) []loader.VehicleRecord {
+	t.Helper()
+	r, err := loader.ReadVehicleRecordsJSON(strings.NewReader("[" + strings.Join(records, ",") + "]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// with returns the valid record with the fields replaced, a field is removed if its value is empty
+func with(fields ...string) string {
+	record := valid
+	for i := 0; i+1 < len(fields); i += 2 {
+		name, value := fields[i], fields[i+1]
+		start := strings.Index(record, `"`+name+`":`)
+		if start < 0 {
+			record = strings.Replace(record, "{", fmt.Sprintf(`{"%s":%s,`, name, value), 1)
+			continue
+		}
+		end := start + strings.IndexAny(record[start:], ",}")
+		if value == "" {
+			if record[end] == ',' {
+				end++
+			}
+			record = record[:start] + record[end:]
+			continue
+		}
+		record = record[:start] + fmt.Sprintf(`"%s":%s`, name, value) + record[end:]
+	}
+	return record
+}
+
+// problem is a problem of a record, as field and severity
+type problem struct {
+	field    string
+	severity Severity
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []string
+		// want are the problems of the last record
+		want []problem
+	}{
+		{name: "valid", records: []string{valid}},
+
+		// - values that could not be read, or the service rejects
+		{name: "year not a number", records: []string{with("year", `"2010"`)}, want: []problem{{"year", SeverityError}}},
+		{name: "year before the first automobile", records: []string{with("year", "1885")}, want: []problem{{"year", SeverityError}}},
+		{name: "vin with a wrong check digit", records: []string{with("brand", `"Honda"`, "year", "2003", "vin", `"1HGCM82633A004353"`)},
+			want: []problem{{"vin", SeverityError}}},
+		{name: "missing id", records: []string{with("id", "")}, want: []problem{{"id", SeverityError}}},
+
+		// - values the service accepts but are likely wrong
+		{name: "missing length", records: []string{with("length", "")}, want: []problem{{"length", SeverityWarning}}},
+		{name: "blank brand", records: []string{with("brand", `" "`)}, want: []problem{{"brand", SeverityWarning}}},
+		{name: "missing model", records: []string{with("model", "")}, want: []problem{{"model", SeverityWarning}}},
+		{name: "year after the next one", records: []string{with("year", fmt.Sprint(time.Now().Year()+2))}, want: []problem{{"year", SeverityWarning}}},
+		{name: "year of the next one", records: []string{with("year", fmt.Sprint(time.Now().Year()+1))}},
+		{name: "negative weight", records: []string{with("weight", "-1100")}, want: []problem{{"weight", SeverityWarning}}},
+		{name: "unknown width", records: []string{with("width", "0")}, want: []problem{{"width", SeverityWarning}}},
+		{name: "color not normalized", records: []string{with("color", `"dark  red"`)}, want: []problem{{"color", SeverityWarning}}},
+		{name: "registration not normalized", records: []string{with("registration", `"aa-100-aa"`)}, want: []problem{{"registration", SeverityWarning}}},
+
+		// - uniqueness
+		{name: "duplicated id", records: []string{valid, with("registration", `"AA200AA"`)}, want: []problem{{"id", SeverityError}}},
+		{name: "duplicated registration", records: []string{valid, with("id", "2", "registration", `"AA100AA"`)},
+			want: []problem{{"registration", SeverityError}}},
+		{name: "duplicated vin", records: []string{
+			with("brand", `"Honda"`, "year", "2003", "vin", `"1HGCM82633A004352"`),
+			with("id", "2", "registration", `"AA200AA"`, "brand", `"Honda"`, "year", "2003", "vin", `"1HGCM82633A004352"`),
+		}, want: []problem{{"vin", SeverityError}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := readRecords(t, tt.records...)
+			last := records[len(records)-1].Position
+
+			var got []problem
+			for _, p := range Check(records, nil) {
+				if p.Position == last {
+					got = append(got, problem{p.Field, p.Severity})
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck_Registration(t *testing.T) {
+	rv, err := registration.NewValidator(registration.ConfigValidator{DefaultCountry: "AR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		record string
+		want   []problem
+	}{
+		{name: "mercosur plate of the default country", record: valid},
+		{name: "old plate of the default country", record: with("registration", `"ABC123"`)},
+		{name: "not a plate of the default country", record: with("registration", `"12345"`), want: []problem{{"registration", SeverityError}}},
+		{name: "plate of the country of the vehicle", record: with("country", `"US-CA"`, "registration", `"7ABC123"`)},
+		{name: "unknown country", record: with("country", `"XX"`), want: []problem{{"country", SeverityError}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []problem
+			for _, p := range Check(readRecords(t, tt.record), rv) {
+				got = append(got, problem{p.Field, p.Severity})
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	errs, warnings := Count([]Problem{{Severity: SeverityError}, {Severity: SeverityWarning}, {Severity: SeverityWarning}})
+	if errs != 1 || warnings != 2 {
+		t.Errorf("Count() = %d, %d, want 1, 2", errs, warnings)
+	}
+}
@@ -0,0 +1,103 @@
+package lint
+
+import (
+	"app/internal"
+	"app/internal/loader"
+	"fmt"
+)
+
+// Options are the options of Repair
+type Options struct {
+	// DropDuplicates removes the records with a duplicated registration, instead of renaming the registration
+	DropDuplicates bool
+}
+
+// Change is a change made by Repair to a record
+type Change struct {
+	// Position is the position of the record in the file, see loader.VehicleRecord
+	Position int `json:"position"`
+	// Id is the id of the record in the file
+	Id int `json:"id"`
+	// Field is the name of the changed field in the files, empty if the record was removed
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Repair returns the vehicles of the records repaired, by id, and the changes made:
+//   - the registrations, countries, vins, colors, fuel types and transmissions are normalized
+//   - the records identical to a previous one are removed
+//   - the duplicated registrations get a suffix X2, X3..., or their records are removed with opts.DropDuplicates
+//   - the duplicated, missing or not positive ids are reassigned after the greatest id
+//
+// The other problems, e.g. a missing length or a duplicated vin, can not be repaired and remain
+func Repair(records []loader.VehicleRecord, opts Options) (v map[int]internal.Vehicle, changes []Change) {
+	// the ids are reassigned after the greatest one
+	lastId := 0
+	for _, r := range records {
+		lastId = max(lastId, r.Vehicle.Id)
+	}
+
+	v = make(map[int]internal.Vehicle)
+	seen := make(map[internal.VehicleAttributes]int)
+	registrations := make(map[string]bool)
+	for _, r := range records {
+		vh := r.Vehicle
+		change := func(field string, format string, args ...any) {
+			changes = append(changes, Change{Position: r.Position, Id: r.Vehicle.Id, Field: field, Message: fmt.Sprintf(format, args...)})
+		}
+
+		// - registrations and categories
+		for _, field := range []string{"registration", "country", "vin", "color", "fuel_type", "transmission"} {
+			current := value(vh, field)
+			normalized := normalize(field, current)
+			if normalized == current {
+				continue
+			}
+			switch field {
+			case "registration":
+				vh.Registration = normalized
+			case "country":
+				vh.Country = normalized
+			case "vin":
+				vh.Vin = normalized
+			case "color":
+				vh.Color = normalized
+			case "fuel_type":
+				vh.FuelType = normalized
+			case "transmission":
+				vh.Transmission = normalized
+			}
+			change(field, "%q normalized to %q", current, normalized)
+		}
+
+		// - identical records
+		if first, ok := seen[vh.VehicleAttributes]; ok {
+			change("", "removed, identical to the record at %d", first)
+			continue
+		}
+		seen[vh.VehicleAttributes] = r.Position
+
+		// - registrations
+		if registrations[vh.Registration] {
+			if opts.DropDuplicates {
+				change("", "removed, registration %q is duplicated", vh.Registration)
+				continue
+			}
+			registration := vh.Registration
+			for n := 2; registrations[vh.Registration]; n++ {
+				vh.Registration = internal.NormalizeRegistration(fmt.Sprintf("%sX%d", registration, n))
+			}
+			change("registration", "duplicated %q renamed to %q", registration, vh.Registration)
+		}
+		registrations[vh.Registration] = true
+
+		// - ids
+		if _, ok := v[vh.Id]; ok || vh.Id <= 0 {
+			lastId++
+			change("id", "id %d reassigned to %d", vh.Id, lastId)
+			vh.Id = lastId
+		}
+		v[vh.Id] = vh
+	}
+	return
+}
@@ -0,0 +1,112 @@
+package lint
+
+import (
+	"app/internal"
+	"app/internal/loader"
+	"bytes"
+	"encoding/json"
+	"maps"
+	"sort"
+	"testing"
+)
+
+// reread returns the records of the vehicles written to a JSON file and read back
+func reread(t *testing.T, v map[int]internal.Vehicle) []loader.VehicleRecord {
+	t.Helper()
+	ids := make([]int, 0, len(v))
+	for id := range v {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	vehicles := make([]loader.VehicleJSON, 0, len(v))
+	for _, id := range ids {
+		vehicles = append(vehicles, loader.NewVehicleJSON(v[id]))
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(vehicles); err != nil {
+		t.Fatal(err)
+	}
+	records, err := loader.ReadVehicleRecordsJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestRepair(t *testing.T) {
+	records := readRecords(t,
+		valid,
+		// - categories not normalized
+		with("id", "2", "registration", `"aa 200 aa"`, "color", `"dark  red"`, "fuel_type", `"Diesel"`, "transmission", `" MANUAL"`),
+		// - identical to the first record but the id
+		with("id", "3"),
+		// - duplicated registration and id
+		with("id", "2", "registration", `"AA-200-AA"`, "model", `"Focus"`),
+		// - missing id
+		with("id", "", "registration", `"AA500AA"`),
+	)
+
+	tests := []struct {
+		name string
+		opts Options
+		// registrations are the registrations of the repaired vehicles, by id
+		registrations map[int]string
+		changes       int
+	}{
+		{name: "rename duplicates", opts: Options{}, registrations: map[int]string{
+			1: "AA100AA", 2: "AA200AA", 4: "AA200AAX2", 5: "AA500AA",
+		}, changes: 9},
+		{name: "drop duplicates", opts: Options{DropDuplicates: true}, registrations: map[int]string{
+			1: "AA100AA", 2: "AA200AA", 4: "AA500AA",
+		}, changes: 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, changes := Repair(records, tt.opts)
+
+			registrations := make(map[int]string)
+			for id, vh := range v {
+				if vh.Id != id {
+					t.Errorf("vehicle %d has id %d", id, vh.Id)
+				}
+				registrations[id] = vh.Registration
+			}
+			if len(registrations) != len(tt.registrations) {
+				t.Errorf("Repair() = %v, want %v", registrations, tt.registrations)
+			}
+			for id, want := range tt.registrations {
+				if registrations[id] != want {
+					t.Errorf("registration of %d = %q, want %q", id, registrations[id], want)
+				}
+			}
+			if len(changes) != tt.changes {
+				t.Errorf("Repair() = %d changes, want %d: %v", len(changes), tt.changes, changes)
+			}
+
+			// - the categories are normalized
+			if vh := v[2]; vh.Color != "Dark Red" || vh.FuelType != "diesel" || vh.Transmission != "manual" {
+				t.Errorf("vehicle 2 = %+v, want the categories normalized", vh)
+			}
+
+			// - only the problems that can not be repaired remain, none in these records
+			if problems := Check(reread(t, v), nil); len(problems) > 0 {
+				t.Errorf("problems after Repair() = %v", problems)
+			}
+		})
+	}
+}
+
+func TestRepair_Idempotent(t *testing.T) {
+	records := readRecords(t, valid, with("id", "1", "registration", `"aa100aa"`, "color", `"red"`))
+
+	v, _ := Repair(records, Options{})
+	again, changes := Repair(reread(t, v), Options{})
+	if len(changes) > 0 {
+		t.Errorf("Repair() of a repaired file = %v, want no changes", changes)
+	}
+	if !maps.Equal(again, v) {
+		t.Errorf("Repair() of a repaired file = %v, want %v", again, v)
+	}
+}
@@ -0,0 +1,49 @@
+package lint
+
+import (
+	"app/internal"
+	"strconv"
+)
+
+// value returns the value of a field of a vehicle as text, by its name in the files
+func value(v internal.Vehicle, field string) string {
+	number := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	switch field {
+	case "id":
+		return strconv.Itoa(v.Id)
+	case "brand":
+		return v.Brand
+	case "model":
+		return v.Model
+	case "registration":
+		return v.Registration
+	case "country":
+		return v.Country
+	case "vin":
+		return v.Vin
+	case "color":
+		return v.Color
+	case "year":
+		return strconv.Itoa(v.FabricationYear)
+	case "passengers":
+		return strconv.Itoa(v.Capacity)
+	case "max_speed":
+		return number(v.MaxSpeed)
+	case "fuel_type":
+		return v.FuelType
+	case "transmission":
+		return v.Transmission
+	case "weight":
+		return number(v.Weight)
+	case "height":
+		return number(v.Height)
+	case "length":
+		return number(v.Length)
+	case "width":
+		return number(v.Width)
+	}
+	return ""
+}
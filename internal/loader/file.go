@@ -0,0 +1,55 @@
+package loader
+
+import (
+	"app/internal"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// NewVehicleFile is a function that returns the loader and saver of a file of vehicles,
+// in CSV if its extension is .csv and in JSON otherwise
+func NewVehicleFile(path string) interface {
+	internal.VehicleLoader
+	internal.VehicleSaver
+} {
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return NewVehicleCSVFile(path)
+	}
+	return NewVehicleJSONFile(path)
+}
+
+// sortedVehicles returns the vehicles sorted by id
+func sortedVehicles(v map[int]internal.Vehicle) (vehicles []internal.Vehicle) {
+	vehicles = make([]internal.Vehicle, 0, len(v))
+	for _, vh := range v {
+		vehicles = append(vehicles, vh)
+	}
+	sort.Slice(vehicles, func(i, j int) bool { return vehicles[i].Id < vehicles[j].Id })
+	return
+}
+
+// writeFileAtomic writes a file through a temporary file in the same directory that replaces it once complete,
+// so a failed write does not leave the file truncated
+func writeFileAtomic(path string, write func(w io.Writer) error) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if err = write(tmp); err != nil {
+		return
+	}
+	if err = tmp.Close(); err != nil {
+		return
+	}
+	return os.Rename(tmp.Name(), path)
+}
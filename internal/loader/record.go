@@ -0,0 +1,180 @@
+package loader
+
+import (
+	"app/internal"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// VehicleFields are the names of the fields of a vehicle in the files, in the order of the CSV columns
+var VehicleFields = []string{"id", "brand", "model", "registration", "color", "year", "passengers", "max_speed",
+	"fuel_type", "transmission", "weight", "height", "length", "width", "country", "vin"}
+
+// VehicleRecord is a vehicle as it is in a file, with the fields that are missing or could not be parsed.
+// The records are read as they are, e.g. with duplicated ids, to check the files
+type VehicleRecord struct {
+	// Position is the position of the record in the file: the index starting at 1 in JSON, the line in CSV
+	Position int
+	// Vehicle is the vehicle of the record, the missing and invalid fields have the zero value
+	Vehicle internal.Vehicle
+	// Missing are the names of the fields that are absent, null or empty
+	Missing []string
+	// Invalid are the errors of the fields that could not be parsed, by name
+	Invalid map[string]error
+}
+
+// ReadVehicleRecords reads the records of a file of vehicles, in CSV if its extension is .csv and in JSON otherwise
+func ReadVehicleRecords(path string) (records []VehicleRecord, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return ReadVehicleRecordsCSV(file)
+	}
+	return ReadVehicleRecordsJSON(file)
+}
+
+// ReadVehicleRecordsJSON reads the records of an array of vehicles in JSON format
+func ReadVehicleRecordsJSON(r io.Reader) (records []VehicleRecord, err error) {
+	var objects []map[string]json.RawMessage
+	if err = json.NewDecoder(r).Decode(&objects); err != nil {
+		return nil, fmt.Errorf("json: %w", err)
+	}
+
+	for i, object := range objects {
+		var vj VehicleJSON
+		record := VehicleRecord{Position: i + 1}
+		for _, name := range VehicleFields {
+			raw, ok := object[name]
+			if !ok || string(raw) == "null" {
+				record.Missing = append(record.Missing, name)
+				continue
+			}
+			if err := json.Unmarshal(raw, vj.field(name)); err != nil {
+				record.setInvalid(name, fmt.Errorf("%s is not a %s", raw, kind(vj.field(name))))
+			}
+		}
+		record.Vehicle = vj.model()
+		records = append(records, record)
+	}
+	return
+}
+
+// setInvalid records the error of a field
+func (r *VehicleRecord) setInvalid(name string, err error) {
+	if r.Invalid == nil {
+		r.Invalid = make(map[string]error)
+	}
+	r.Invalid[name] = err
+}
+
+// field returns a pointer to the field of vj with the given name, one of VehicleFields
+func (vj *VehicleJSON) field(name string) any {
+	switch name {
+	case "id":
+		return &vj.Id
+	case "brand":
+		return &vj.Brand
+	case "model":
+		return &vj.Model
+	case "registration":
+		return &vj.Registration
+	case "country":
+		return &vj.Country
+	case "vin":
+		return &vj.Vin
+	case "color":
+		return &vj.Color
+	case "year":
+		return &vj.FabricationYear
+	case "passengers":
+		return &vj.Capacity
+	case "max_speed":
+		return &vj.MaxSpeed
+	case "fuel_type":
+		return &vj.FuelType
+	case "transmission":
+		return &vj.Transmission
+	case "weight":
+		return &vj.Weight
+	case "height":
+		return &vj.Height
+	case "length":
+		return &vj.Length
+	case "width":
+		return &vj.Width
+	}
+	return nil
+}
+
+// setText parses the text of a field, e.g. a CSV cell, into the field of vj with the given name
+func (vj *VehicleJSON) setText(name, text string) (err error) {
+	switch p := vj.field(name).(type) {
+	case *string:
+		*p = text
+	case *int:
+		if *p, err = strconv.Atoi(text); err != nil {
+			return fmt.Errorf("%q is not an integer", text)
+		}
+	case *float64:
+		if *p, err = strconv.ParseFloat(text, 64); err != nil {
+			return fmt.Errorf("%q is not a number", text)
+		}
+	}
+	return
+}
+
+// kind returns the name of the type of a field for the errors
+func kind(field any) string {
+	switch field.(type) {
+	case *int:
+		return "integer"
+	case *float64:
+		return "number"
+	}
+	return "string"
+}
+
+// model returns the vehicle of the JSON representation
+func (vj VehicleJSON) model() internal.Vehicle {
+	return internal.Vehicle{
+		Id: vj.Id,
+		VehicleAttributes: internal.VehicleAttributes{
+			Brand:           vj.Brand,
+			Model:           vj.Model,
+			Registration:    vj.Registration,
+			Country:         vj.Country,
+			Vin:             vj.Vin,
+			Color:           vj.Color,
+			FabricationYear: vj.FabricationYear,
+			Capacity:        vj.Capacity,
+			MaxSpeed:        vj.MaxSpeed,
+			FuelType:        vj.FuelType,
+			Transmission:    vj.Transmission,
+			Weight:          vj.Weight,
+			Dimensions: internal.Dimensions{
+				Height: vj.Height,
+				Length: vj.Length,
+				Width:  vj.Width,
+			},
+		},
+	}
+}
+
+// IsMissing returns true if the field with the given name is missing in the record
+func (r VehicleRecord) IsMissing(name string) bool {
+	for _, missing := range r.Missing {
+		if missing == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package loader
+
+import (
+	"app/internal"
+	"app/internal/tracing"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NewVehicleCSVFile is a function that returns a new instance of VehicleCSVFile
+func NewVehicleCSVFile(path string) *VehicleCSVFile {
+	return &VehicleCSVFile{
+		path: path,
+	}
+}
+
+// VehicleCSVFile is a struct that implements the LoaderVehicle interface for CSV files with a header row.
+// The columns are VehicleFields, in any order
+type VehicleCSVFile struct {
+	// path is the path to the file that contains the vehicles in CSV format
+	path string
+}
+
+// Load is a method that loads the vehicles. Only the column id is required,
+// the missing columns and empty cells have the zero value like the absent fields in JSON
+func (l *VehicleCSVFile) Load() (v map[int]internal.Vehicle, err error) {
+	_, span := tracing.Start(context.Background(), "loader.VehicleCSVFile.Load", tracing.String("file.path", l.path))
+	defer span.EndError(&err)
+
+	// open file
+	file, err := os.Open(l.path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	v, err = ReadVehiclesCSV(file)
+	span.SetAttributes(tracing.Int("vehicle.count", len(v)))
+	return
+}
+
+// Save is a method that writes the vehicles to the file, sorted by id, replacing its content
+func (l *VehicleCSVFile) Save(v map[int]internal.Vehicle) (err error) {
+	_, span := tracing.Start(context.Background(), "loader.VehicleCSVFile.Save",
+		tracing.String("file.path", l.path), tracing.Int("vehicle.count", len(v)))
+	defer span.EndError(&err)
+
+	return writeFileAtomic(l.path, func(w io.Writer) error {
+		return WriteVehiclesCSV(w, sortedVehicles(v))
+	})
+}
+
+// ReadVehiclesCSV reads vehicles in CSV format with a header row, failing on the invalid values
+func ReadVehiclesCSV(r io.Reader) (v map[int]internal.Vehicle, err error) {
+	records, err := ReadVehicleRecordsCSV(r)
+	if err != nil {
+		return
+	}
+
+	v = make(map[int]internal.Vehicle)
+	for _, record := range records {
+		if len(record.Invalid) > 0 {
+			names := make([]string, 0, len(record.Invalid))
+			for name := range record.Invalid {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("csv: line %d: column %s: %w", record.Position, names[0], record.Invalid[names[0]])
+		}
+		v[record.Vehicle.Id] = record.Vehicle
+	}
+	return
+}
+
+// ReadVehicleRecordsCSV reads the records of vehicles in CSV format with a header row.
+// The columns that are not in the header are missing in every record
+func ReadVehicleRecordsCSV(r io.Reader) (records []VehicleRecord, err error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	// header
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("csv: reading header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	if _, ok := columns["id"]; !ok {
+		return nil, fmt.Errorf("csv: missing column id")
+	}
+
+	// rows
+	for {
+		row, readErr := reader.Read()
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, fmt.Errorf("csv: %w", readErr)
+		}
+
+		var vj VehicleJSON
+		line, _ := reader.FieldPos(0)
+		record := VehicleRecord{Position: line}
+		for _, name := range VehicleFields {
+			i, ok := columns[name]
+			if !ok || i >= len(row) || strings.TrimSpace(row[i]) == "" {
+				record.Missing = append(record.Missing, name)
+				continue
+			}
+			if err := vj.setText(name, strings.TrimSpace(row[i])); err != nil {
+				record.setInvalid(name, err)
+			}
+		}
+		record.Vehicle = vj.model()
+		records = append(records, record)
+	}
+	return
+}
+
+// WriteVehiclesCSV writes vehicles in CSV format with a header row
+func WriteVehiclesCSV(w io.Writer, vehicles []internal.Vehicle) (err error) {
+	writer := csv.NewWriter(w)
+	if err = writer.Write(VehicleFields); err != nil {
+		return
+	}
+
+	formatFloat := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	for _, vh := range vehicles {
+		err = writer.Write([]string{
+			strconv.Itoa(vh.Id), vh.Brand, vh.Model, vh.Registration, vh.Color,
+			strconv.Itoa(vh.FabricationYear), strconv.Itoa(vh.Capacity), formatFloat(vh.MaxSpeed),
+			vh.FuelType, vh.Transmission, formatFloat(vh.Weight),
+			formatFloat(vh.Height), formatFloat(vh.Length), formatFloat(vh.Width), vh.Country, vh.Vin,
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -2,7 +2,10 @@ package loader
 
 import (
 	"app/internal"
+	"app/internal/tracing"
+	"context"
 	"encoding/json"
+	"io"
 	"os"
 )
 
@@ -25,6 +28,8 @@ type VehicleJSON struct {
 	Brand           string  `json:"brand"`
 	Model           string  `json:"model"`
 	Registration    string  `json:"registration"`
+	Country         string  `json:"country,omitempty"`
+	Vin             string  `json:"vin,omitempty"`
 	Color           string  `json:"color"`
 	FabricationYear int     `json:"year"`
 	Capacity        int     `json:"passengers"`
@@ -39,6 +44,9 @@ type VehicleJSON struct {
 
 // Load is a method that loads the vehicles
 func (l *VehicleJSONFile) Load() (v map[int]internal.Vehicle, err error) {
+	_, span := tracing.Start(context.Background(), "loader.VehicleJSONFile.Load", tracing.String("file.path", l.path))
+	defer span.EndError(&err)
+
 	// open file
 	file, err := os.Open(l.path)
 	if err != nil {
@@ -56,27 +64,52 @@ func (l *VehicleJSONFile) Load() (v map[int]internal.Vehicle, err error) {
 	// serialize vehicles
 	v = make(map[int]internal.Vehicle)
 	for _, vh := range vehiclesJSON {
-		v[vh.Id] = internal.Vehicle{
-			Id: vh.Id,
-			VehicleAttributes: internal.VehicleAttributes{
-				Brand:           vh.Brand,
-				Model:           vh.Model,
-				Registration:    vh.Registration,
-				Color:           vh.Color,
-				FabricationYear: vh.FabricationYear,
-				Capacity:        vh.Capacity,
-				MaxSpeed:        vh.MaxSpeed,
-				FuelType:        vh.FuelType,
-				Transmission:    vh.Transmission,
-				Weight:          vh.Weight,
-				Dimensions: internal.Dimensions{
-					Height: vh.Height,
-					Length: vh.Length,
-					Width:  vh.Width,
-				},
-			},
-		}
+		v[vh.Id] = vh.model()
 	}
 
+	span.SetAttributes(tracing.Int("vehicle.count", len(v)))
+
 	return
 }
+
+// Save is a method that writes the vehicles to the file, sorted by id, replacing its content
+func (l *VehicleJSONFile) Save(v map[int]internal.Vehicle) (err error) {
+	_, span := tracing.Start(context.Background(), "loader.VehicleJSONFile.Save",
+		tracing.String("file.path", l.path), tracing.Int("vehicle.count", len(v)))
+	defer span.EndError(&err)
+
+	// serialize vehicles
+	vehiclesJSON := make([]VehicleJSON, 0, len(v))
+	for _, vh := range sortedVehicles(v) {
+		vehiclesJSON = append(vehiclesJSON, NewVehicleJSON(vh))
+	}
+
+	// encode file
+	return writeFileAtomic(l.path, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(vehiclesJSON)
+	})
+}
+
+// NewVehicleJSON is a function that returns the JSON representation of a vehicle
+func NewVehicleJSON(v internal.Vehicle) VehicleJSON {
+	return VehicleJSON{
+		Id:              v.Id,
+		Brand:           v.Brand,
+		Model:           v.Model,
+		Registration:    v.Registration,
+		Country:         v.Country,
+		Vin:             v.Vin,
+		Color:           v.Color,
+		FabricationYear: v.FabricationYear,
+		Capacity:        v.Capacity,
+		MaxSpeed:        v.MaxSpeed,
+		FuelType:        v.FuelType,
+		Transmission:    v.Transmission,
+		Weight:          v.Weight,
+		Height:          v.Height,
+		Length:          v.Length,
+		Width:           v.Width,
+	}
+}
@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ConfigLogger is a struct that represents the configuration for a logger
+type ConfigLogger struct {
+	// Level is the minimum level of the records: debug, info, warn or error. By default info
+	Level string
+	// Format is the format of the records: json or text. By default text
+	Format string
+	// Output is where the records are written. By default os.Stderr
+	Output io.Writer
+}
+
+// NewLogger is a function that returns a new logger that adds to every record the request id and trace of its context
+func NewLogger(cfg ConfigLogger) (lg *slog.Logger, err error) {
+	// default values
+	output := cfg.Output
+	if output == nil {
+		output = os.Stderr
+	}
+
+	// level
+	var level slog.Level
+	if cfg.Level != "" {
+		if err = level.UnmarshalText([]byte(cfg.Level)); err != nil {
+			return nil, fmt.Errorf("logging: unknown level %q", cfg.Level)
+		}
+	}
+	opts := &slog.HandlerOptions{Level: level}
+
+	// format
+	var h slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case "", "text":
+		h = slog.NewTextHandler(output, opts)
+	case "json":
+		h = slog.NewJSONHandler(output, opts)
+	default:
+		return nil, fmt.Errorf("logging: unknown format %q", cfg.Format)
+	}
+
+	lg = slog.New(&contextHandler{Handler: h})
+	return
+}
+
+// OrDefault returns lg, or the default logger if lg is nil
+func OrDefault(lg *slog.Logger) *slog.Logger {
+	if lg == nil {
+		return slog.Default()
+	}
+	return lg
+}
+
+// contextHandler is a slog.Handler that adds the request id and the trace of the context to the records
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := middleware.GetReqID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// GaugeSample is a value of a gauge with the values of its labels
+type GaugeSample struct {
+	// Labels are the values of the labels, in the order of the names of the family
+	Labels []string
+	// Value is the value of the gauge
+	Value float64
+}
+
+// NewGaugeFunc returns a collector of a gauge with labels whose samples are computed by fn on each scrape.
+// The samples with the same label values are added up
+func NewGaugeFunc(name, help string, labels []string, fn func() []GaugeSample) *GaugeFunc {
+	return &GaugeFunc{desc: prometheus.NewDesc(name, help, labels, nil), fn: fn}
+}
+
+// GaugeFunc is a collector of a family of gauges computed when they are collected
+type GaugeFunc struct {
+	desc *prometheus.Desc
+	fn   func() []GaugeSample
+}
+
+// Describe implements prometheus.Collector
+func (g *GaugeFunc) Describe(ch chan<- *prometheus.Desc) {
+	ch <- g.desc
+}
+
+// Collect implements prometheus.Collector
+func (g *GaugeFunc) Collect(ch chan<- prometheus.Metric) {
+	type sample struct {
+		labels []string
+		value  float64
+	}
+	var samples []*sample
+	byLabels := make(map[string]*sample)
+	for _, s := range g.fn() {
+		key := strings.Join(s.Labels, "\xff")
+		if sm, ok := byLabels[key]; ok {
+			sm.value += s.Value
+			continue
+		}
+		sm := &sample{labels: s.Labels, value: s.Value}
+		byLabels[key] = sm
+		samples = append(samples, sm)
+	}
+
+	for _, s := range samples {
+		ch <- prometheus.MustNewConstMetric(g.desc, prometheus.GaugeValue, s.value, s.labels...)
+	}
+}
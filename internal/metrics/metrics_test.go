@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestHTTPMetrics_Middleware(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	hm := NewHTTPMetrics(reg)
+
+	rt := chi.NewRouter()
+	rt.Use(hm.Middleware)
+	rt.Get("/vehicles/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if chi.URLParam(r, "id") == "0" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	for _, path := range []string{"/vehicles/1", "/vehicles/2", "/vehicles/0", "/other"} {
+		rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	// - the paths are measured by their route pattern
+	expected := `
+# HELP http_requests_total Number of HTTP requests served, by method, chi route pattern and status code.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",route="/vehicles/{id}",status="200"} 2
+http_requests_total{method="GET",route="/vehicles/{id}",status="404"} 1
+http_requests_total{method="GET",route="unmatched",status="404"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "http_requests_total"); err != nil {
+		t.Error(err)
+	}
+	if got := testutil.CollectAndCount(hm.duration); got != 3 {
+		t.Errorf("http_request_duration_seconds has %d series, want 3", got)
+	}
+}
+
+func TestGaugeFunc(t *testing.T) {
+	samples := []GaugeSample{
+		{Labels: []string{"Ford"}, Value: 1},
+		{Labels: []string{"GMC"}, Value: 1},
+		{Labels: []string{"Ford"}, Value: 1},
+	}
+	g := NewGaugeFunc("vehicles_by_brand", "Number of vehicles by brand.", []string{"brand"},
+		func() []GaugeSample { return samples })
+
+	// - the samples with the same labels are added up, and computed on each scrape
+	expected := `
+# HELP vehicles_by_brand Number of vehicles by brand.
+# TYPE vehicles_by_brand gauge
+vehicles_by_brand{brand="Ford"} 2
+vehicles_by_brand{brand="GMC"} 1
+`
+	if err := testutil.CollectAndCompare(g, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+
+	samples = samples[1:2]
+	expected = `
+# HELP vehicles_by_brand Number of vehicles by brand.
+# TYPE vehicles_by_brand gauge
+vehicles_by_brand{brand="GMC"} 1
+`
+	if err := testutil.CollectAndCompare(g, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,609 @@
+package openapi_test
+
+import (
+	"app/internal"
+	"app/internal/application"
+	"app/internal/audit"
+	"app/internal/auth"
+	"app/internal/handler"
+	"app/internal/health"
+	"app/internal/openapi"
+	"app/internal/repository"
+	"app/internal/service"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// document is the part of the OpenAPI document that is checked against the code
+type document struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas   map[string]*schema   `json:"schemas"`
+		Responses map[string]*response `json:"responses"`
+	} `json:"components"`
+}
+
+// operation is an operation of a path of the document
+type operation struct {
+	Responses map[string]*response `json:"responses"`
+}
+
+// response is a response of an operation, or a reference to one
+type response struct {
+	Ref     string `json:"$ref"`
+	Content map[string]struct {
+		Schema *schema `json:"schema"`
+	} `json:"content"`
+}
+
+// schema is the subset of the schemas of OpenAPI 3.0 used by the document
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Nullable             bool               `json:"nullable"`
+	Enum                 []any              `json:"enum"`
+	Required             []string           `json:"required"`
+	Properties           map[string]*schema `json:"properties"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+	Items                *schema            `json:"items"`
+	AllOf                []*schema          `json:"allOf"`
+}
+
+// additional returns the schema of the additional properties of an object, and false if they are not allowed
+func (s *schema) additional() (additional *schema, allowed bool) {
+	if len(s.AdditionalProperties) == 0 || string(s.AdditionalProperties) == "true" {
+		return nil, true
+	}
+	if string(s.AdditionalProperties) == "false" {
+		return nil, false
+	}
+	additional = &schema{}
+	if err := json.Unmarshal(s.AdditionalProperties, additional); err != nil {
+		panic(err)
+	}
+	return additional, true
+}
+
+// methods are the keys of a path item of the document that are operations
+var methods = map[string]bool{"get": true, "put": true, "post": true, "delete": true, "patch": true, "head": true, "options": true}
+
+// parseDocument parses the OpenAPI document served by the api
+func parseDocument(t *testing.T) (doc document) {
+	t.Helper()
+	if err := json.Unmarshal(openapi.Spec(), &doc); err != nil {
+		t.Fatalf("invalid document: %v", err)
+	}
+	return
+}
+
+// operations returns the operations of the document, e.g. GET /vehicles/{id}
+func (doc document) operations(t *testing.T) (ops map[string]operation) {
+	t.Helper()
+	ops = make(map[string]operation)
+	for path, item := range doc.Paths {
+		for method, raw := range item {
+			if !methods[method] {
+				continue
+			}
+			var op operation
+			if err := json.Unmarshal(raw, &op); err != nil {
+				t.Fatalf("invalid operation %s %s: %v", method, path, err)
+			}
+			ops[strings.ToUpper(method)+" "+path] = op
+		}
+	}
+	return
+}
+
+// resolve returns the schema referenced by s, if it is a reference
+func (doc document) resolve(s *schema) *schema {
+	for s.Ref != "" {
+		s = doc.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+// fleet returns the vehicles of the api under test, by id
+func fleet() map[int]internal.Vehicle {
+	return map[int]internal.Vehicle{
+		1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{
+			Brand: "Ford", Model: "Fiesta", Registration: "AA100AA", Color: "Red", FabricationYear: 2010, Capacity: 5,
+			FuelType: "gasoline", Transmission: "manual", Weight: 1100,
+			Dimensions: internal.Dimensions{Length: 4.0, Width: 1.7, Height: 1.5},
+		}},
+		2: {Id: 2, VehicleAttributes: internal.VehicleAttributes{
+			Brand: "Ford", Model: "Ranger", Registration: "AA200AA", Color: "Black", FabricationYear: 2020, Capacity: 3,
+			FuelType: "diesel", Transmission: "manual", Weight: 2000,
+			Dimensions: internal.Dimensions{Length: 5.3, Width: 1.85, Height: 1.8},
+		}},
+		3: {Id: 3, VehicleAttributes: internal.VehicleAttributes{
+			Brand: "Honda", Model: "Accord", Registration: "AA300AA", Vin: "1HGCM82633A004352", Color: "Blue",
+			FabricationYear: 2003, Capacity: 5, FuelType: "gasoline", Transmission: "automatic", Weight: 1400,
+			Dimensions: internal.Dimensions{Length: 4.8, Width: 1.8, Height: 1.45},
+		}},
+	}
+}
+
+// newRouter returns the router of the api over the fleet, with an api key of each role
+func newRouter(t *testing.T, loaded bool) http.Handler {
+	t.Helper()
+	sv := service.NewVehicleDefault(repository.NewVehicleMap(fleet(), nil), audit.NewSinkMemory(), nil, nil)
+	st := health.NewStatus()
+	if loaded {
+		st.EndLoad(nil)
+	}
+	an, err := auth.NewAuthenticator(auth.ConfigAuthenticator{APIKeys: map[string]auth.Principal{
+		"reader": {Subject: "ana", Role: auth.RoleReader},
+		"admin":  {Subject: "eva", Role: auth.RoleAdmin},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return application.NewRouter(application.ConfigRouter{
+		Service:       sv,
+		Status:        st,
+		Authenticator: an,
+		Registry:      prometheus.NewRegistry(),
+	})
+}
+
+func TestContract_Routes(t *testing.T) {
+	doc := parseDocument(t)
+	documented := doc.operations(t)
+
+	served := make(map[string]bool)
+	err := chi.Walk(newRouter(t, true).(chi.Routes), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		// the files of Swagger UI are not part of the api
+		if strings.HasSuffix(route, "/*") {
+			return nil
+		}
+		if route != "/" {
+			route = strings.TrimSuffix(route, "/")
+		}
+		served[method+" "+route] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for op := range served {
+		if _, ok := documented[op]; !ok {
+			t.Errorf("route %s is not documented", op)
+		}
+	}
+	for op := range documented {
+		if !served[op] {
+			t.Errorf("operation %s has no route", op)
+		}
+	}
+}
+
+func TestContract_Schemas(t *testing.T) {
+	doc := parseDocument(t)
+
+	// the structs of the bodies by the name of their schema
+	schemas := map[string]reflect.Type{
+		"VehicleRequest":  reflect.TypeOf(handler.VehicleRequestJSON{}),
+		"VehicleResponse": reflect.TypeOf(handler.VehicleResponseJSON{}),
+		"Response":        reflect.TypeOf(handler.ResponseJSON{}),
+		"VehicleStats":    reflect.TypeOf(handler.VehicleStatsResponseJSON{}),
+		"VehicleFacet":    reflect.TypeOf(handler.VehicleFacetResponseJSON{}),
+		"AuditEvent":      reflect.TypeOf(handler.AuditEventResponseJSON{}),
+		"AuditChange":     reflect.TypeOf(handler.AuditChangeResponseJSON{}),
+		"BuildInfo":       reflect.TypeOf(health.BuildInfoJSON{}),
+	}
+	names := make(map[reflect.Type]string)
+	for name, typ := range schemas {
+		names[typ] = name
+	}
+
+	for name, typ := range schemas {
+		t.Run(name, func(t *testing.T) {
+			s, ok := doc.Components.Schemas[name]
+			if !ok {
+				t.Fatalf("schema %s is not documented", name)
+			}
+
+			fields := jsonFields(typ)
+			for field, sf := range fields {
+				property, ok := s.Properties[field]
+				if !ok {
+					t.Errorf("field %s is not documented", field)
+					continue
+				}
+				for _, problem := range compareType(doc, names, sf.Type, property) {
+					t.Errorf("property %s: %s", field, problem)
+				}
+				// - a field is always present in the JSON unless it is omitted when empty
+				omitempty := strings.Contains(sf.Tag.Get("json"), ",omitempty")
+				if required := slices.Contains(s.Required, field); required == omitempty {
+					t.Errorf("property %s is required %t, but the field has omitempty %t", field, required, omitempty)
+				}
+			}
+			for property := range s.Properties {
+				if _, ok := fields[property]; !ok {
+					t.Errorf("property %s is not a field of %s", property, typ)
+				}
+			}
+		})
+	}
+}
+
+// jsonFields returns the JSON fields of a struct by name, including the ones of its embedded structs
+func jsonFields(t reflect.Type) (fields map[string]reflect.StructField) {
+	fields = make(map[string]reflect.StructField)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" || (!sf.IsExported() && !sf.Anonymous) {
+			continue
+		}
+		if sf.Anonymous && name == "" {
+			for field, embedded := range jsonFields(sf.Type) {
+				fields[field] = embedded
+			}
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields[name] = sf
+	}
+	return
+}
+
+// timeType is the type of the times, encoded as RFC 3339 strings
+var timeType = reflect.TypeOf(time.Time{})
+
+// compareType returns the differences between the JSON encoding of a Go type and a schema.
+// names are the names of the schemas of the structs, that must be referenced by name
+func compareType(doc document, names map[reflect.Type]string, typ reflect.Type, s *schema) (problems []string) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if name, ok := names[typ]; ok {
+		if s.Ref != "#/components/schemas/"+name {
+			problems = append(problems, fmt.Sprintf("is a %s, documented as %q", name, s.Ref))
+		}
+		return
+	}
+	s = doc.resolve(s)
+
+	var want string
+	switch {
+	case typ == timeType:
+		if s.Format != "date-time" {
+			problems = append(problems, "is a time, documented without format date-time")
+		}
+		want = "string"
+	case typ.Kind() == reflect.Interface:
+		if s.Type != "" || !s.Nullable {
+			problems = append(problems, "can be any value, documented with a type or not nullable")
+		}
+		return
+	case typ.Kind() == reflect.String:
+		want = "string"
+	case typ.Kind() == reflect.Bool:
+		want = "boolean"
+	case typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Uint64:
+		want = "integer"
+	case typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64:
+		want = "number"
+	case typ.Kind() == reflect.Slice:
+		want = "array"
+		if s.Items != nil {
+			problems = append(problems, compareType(doc, names, typ.Elem(), s.Items)...)
+		}
+	case typ.Kind() == reflect.Map:
+		want = "object"
+		if additional, _ := s.additional(); additional != nil {
+			problems = append(problems, compareType(doc, names, typ.Elem(), additional)...)
+		} else {
+			problems = append(problems, "is a map, documented without additionalProperties")
+		}
+	case typ.Kind() == reflect.Struct:
+		want = "object"
+	}
+
+	if s.Type != want {
+		problems = append(problems, fmt.Sprintf("is a %s, documented as %q, want %q", typ, s.Type, want))
+	}
+	return
+}
+
+// exchange is a request to the api and the status code of its response
+type exchange struct {
+	// op is the documented operation of the request, e.g. GET /vehicles/{id}
+	op     string
+	method string
+	path   string
+	// apiKey is the api key of the request, admin by default and none if it is "-"
+	apiKey string
+	header http.Header
+	body   string
+	// status is the status code of the response
+	status int
+}
+
+func TestContract_Responses(t *testing.T) {
+	doc := parseDocument(t)
+	ops := doc.operations(t)
+
+	vehicle := `{"brand":"Fiat","model":"Uno","registration":"AA400AA","color":"White","year":2005,"passengers":5,` +
+		`"max_speed":150,"fuel_type":"gasoline","transmission":"manual","weight":800,"height":1.4,"length":3.7,"width":1.5}`
+	ifMatch := func(version int) http.Header { return http.Header{"If-Match": {fmt.Sprintf(`"%d"`, version)}} }
+
+	// the exchanges are sent in order to the same api, the writes change the vehicles of the later ones
+	exchanges := []exchange{
+		// - probes and documents
+		{op: "GET /healthz", method: "GET", path: "/healthz", apiKey: "-", status: 200},
+		{op: "GET /readyz", method: "GET", path: "/readyz", apiKey: "-", status: 200},
+		{op: "GET /version", method: "GET", path: "/version", apiKey: "-", status: 200},
+		{op: "GET /openapi.json", method: "GET", path: "/openapi.json", apiKey: "-", status: 200},
+		{op: "GET /docs", method: "GET", path: "/docs", apiKey: "-", status: 200},
+		{op: "GET /metrics", method: "GET", path: "/metrics", apiKey: "reader", status: 200},
+		{op: "GET /metrics", method: "GET", path: "/metrics", apiKey: "-", status: 401},
+
+		// - reads
+		{op: "GET /vehicles", method: "GET", path: "/vehicles", status: 200},
+		{op: "GET /vehicles", method: "GET", path: "/vehicles?brand=ford&year_min=2015", apiKey: "reader", status: 200},
+		{op: "GET /vehicles", method: "GET", path: "/vehicles?filter=year>=2000%20and%20brand=Ford", status: 200},
+		{op: "GET /vehicles", method: "GET", path: "/vehicles?year=new", status: 400},
+		{op: "GET /vehicles", method: "GET", path: "/vehicles", apiKey: "-", status: 401},
+		{op: "GET /vehicles", method: "GET", path: "/vehicles", apiKey: "unknown", status: 401},
+		{op: "GET /vehicles/color/{color}/year/{year}", method: "GET", path: "/vehicles/color/red/year/2010", status: 200},
+		{op: "GET /vehicles/color/{color}/year/{year}", method: "GET", path: "/vehicles/color/pink/year/2010", status: 404},
+		{op: "GET /vehicles/color/{color}/year/{year}", method: "GET", path: "/vehicles/color/red/year/new", status: 400},
+		{op: "GET /vehicles/{id}", method: "GET", path: "/vehicles/1", status: 200},
+		{op: "GET /vehicles/{id}", method: "GET", path: "/vehicles/1", header: http.Header{"If-None-Match": {`"1"`}}, status: 304},
+		{op: "GET /vehicles/{id}", method: "GET", path: "/vehicles/99", status: 404},
+		{op: "GET /vehicles/{id}", method: "GET", path: "/vehicles/one", status: 400},
+		{op: "GET /vehicles/vin/{vin}", method: "GET", path: "/vehicles/vin/1HGCM82633A004352", status: 200},
+		{op: "GET /vehicles/vin/{vin}", method: "GET", path: "/vehicles/vin/1HGCM82633A004353", status: 400},
+		{op: "GET /vehicles/{id}/history", method: "GET", path: "/vehicles/1/history", status: 200},
+		{op: "GET /vehicles/{id}/history", method: "GET", path: "/vehicles/99/history", status: 200},
+		{op: "GET /vehicles/average_capacity/brand/{brand}", method: "GET", path: "/vehicles/average_capacity/brand/Ford", status: 200},
+		{op: "GET /vehicles/average_capacity/brand/{brand}", method: "GET", path: "/vehicles/average_capacity/brand/Fiat", status: 404},
+		{op: "GET /vehicles/suggest", method: "GET", path: "/vehicles/suggest?field=brand&q=fo", status: 200},
+		{op: "GET /vehicles/suggest", method: "GET", path: "/vehicles/suggest?q=fo", status: 400},
+		{op: "GET /vehicles/search", method: "GET", path: "/vehicles/search?q=ford%20ranger&limit=2", status: 200},
+		{op: "GET /vehicles/search", method: "GET", path: "/vehicles/search", status: 400},
+		{op: "GET /vehicles/stats", method: "GET", path: "/vehicles/stats?field=weight&group_by=brand&percentiles=50,90", status: 200},
+		{op: "GET /vehicles/stats", method: "GET", path: "/vehicles/stats?field=weight&percentiles=NaN", status: 400},
+		{op: "GET /vehicles/facets", method: "GET", path: "/vehicles/facets?fields=brand,color", status: 200},
+		{op: "GET /vehicles/facets", method: "GET", path: "/vehicles/facets?fields=owner", status: 400},
+
+		// - writes
+		{op: "POST /vehicles", method: "POST", path: "/vehicles", body: vehicle, status: 201},
+		{op: "POST /vehicles", method: "POST", path: "/vehicles", body: vehicle, status: 409},
+		{op: "POST /vehicles", method: "POST", path: "/vehicles", body: `{"brand":"Fiat"}`, status: 400},
+		{op: "POST /vehicles", method: "POST", path: "/vehicles", body: vehicle, apiKey: "reader", status: 403},
+		{op: "POST /vehicles", method: "POST", path: "/vehicles", body: `{"brand":"` + strings.Repeat("a", 1<<20) + `"}`, status: 413},
+		{op: "PUT /vehicles/{id}", method: "PUT", path: "/vehicles/1", header: ifMatch(1), body: strings.Replace(vehicle, "AA400AA", "AA100AA", 1), status: 200},
+		{op: "PUT /vehicles/{id}", method: "PUT", path: "/vehicles/1", header: ifMatch(1), body: vehicle, status: 412},
+		{op: "PUT /vehicles/{id}", method: "PUT", path: "/vehicles/1", body: vehicle, status: 428},
+		{op: "PUT /vehicles/{id}", method: "PUT", path: "/vehicles/99", header: ifMatch(1), body: vehicle, status: 404},
+		{op: "PUT /vehicles/{id}", method: "PUT", path: "/vehicles/2", header: ifMatch(1), body: vehicle, status: 409},
+		{op: "POST /vehicles/{id}/revert", method: "POST", path: "/vehicles/1/revert?version=1", status: 200},
+		{op: "POST /vehicles/{id}/revert", method: "POST", path: "/vehicles/1/revert?version=9", status: 404},
+		{op: "POST /vehicles/{id}/revert", method: "POST", path: "/vehicles/1/revert", status: 400},
+		{op: "DELETE /vehicles/{id}", method: "DELETE", path: "/vehicles/2", header: ifMatch(1), status: 204},
+		{op: "DELETE /vehicles/{id}", method: "DELETE", path: "/vehicles/3", status: 428},
+		{op: "DELETE /vehicles/{id}", method: "DELETE", path: "/vehicles/3", header: ifMatch(7), status: 412},
+		{op: "DELETE /vehicles/{id}", method: "DELETE", path: "/vehicles/99", header: ifMatch(1), status: 404},
+		{op: "POST /vehicles/{id}/restore", method: "POST", path: "/vehicles/2/restore", status: 200},
+		{op: "POST /vehicles/{id}/restore", method: "POST", path: "/vehicles/2/restore", status: 409},
+		{op: "POST /vehicles/{id}/restore", method: "POST", path: "/vehicles/99/restore", status: 404},
+
+		// - audit
+		{op: "GET /audit", method: "GET", path: "/audit", status: 200},
+		{op: "GET /audit", method: "GET", path: "/audit?since=yesterday", status: 400},
+		{op: "GET /audit", method: "GET", path: "/audit", apiKey: "reader", status: 403},
+	}
+
+	rt := newRouter(t, true)
+	tested := make(map[string]bool)
+	for _, ex := range exchanges {
+		name := fmt.Sprintf("%s %s %d", ex.method, ex.path, ex.status)
+		if len(name) > 80 {
+			name = name[:80]
+		}
+		t.Run(name, func(t *testing.T) {
+			tested[ex.op] = true
+			res := send(rt, ex)
+			if res.Code != ex.status {
+				t.Fatalf("status = %d, want %d: %s", res.Code, ex.status, res.Body)
+			}
+			checkResponse(t, doc, ops, ex.op, res)
+		})
+	}
+
+	// - the api answers 503 until the vehicles are loaded
+	t.Run("GET /vehicles loading", func(t *testing.T) {
+		ex := exchange{op: "GET /vehicles", method: "GET", path: "/vehicles", status: 503}
+		res := send(newRouter(t, false), ex)
+		if res.Code != ex.status {
+			t.Fatalf("status = %d, want %d", res.Code, ex.status)
+		}
+		checkResponse(t, doc, ops, ex.op, res)
+	})
+
+	// every operation is exercised
+	for op := range ops {
+		if !tested[op] {
+			t.Errorf("operation %s has no exchange", op)
+		}
+	}
+}
+
+// send sends the request of an exchange to the api
+func send(rt http.Handler, ex exchange) *httptest.ResponseRecorder {
+	var body io.Reader
+	if ex.body != "" {
+		body = strings.NewReader(ex.body)
+	}
+	req := httptest.NewRequest(ex.method, ex.path, body)
+	for key, values := range ex.header {
+		req.Header[key] = values
+	}
+	switch ex.apiKey {
+	case "":
+		req.Header.Set("X-API-Key", "admin")
+	case "-":
+	default:
+		req.Header.Set("X-API-Key", ex.apiKey)
+	}
+
+	res := httptest.NewRecorder()
+	rt.ServeHTTP(res, req)
+	return res
+}
+
+// checkResponse checks that the status code of a response is documented for the operation,
+// and that its body has the documented content type and schema
+func checkResponse(t *testing.T, doc document, ops map[string]operation, op string, res *httptest.ResponseRecorder) {
+	t.Helper()
+	operation, ok := ops[op]
+	if !ok {
+		t.Fatalf("operation %s is not documented", op)
+	}
+	documented, ok := operation.Responses[fmt.Sprint(res.Code)]
+	if !ok {
+		t.Fatalf("status %d is not documented for %s", res.Code, op)
+	}
+	for documented.Ref != "" {
+		documented = doc.Components.Responses[strings.TrimPrefix(documented.Ref, "#/components/responses/")]
+	}
+
+	// - a response without content has no body
+	if len(documented.Content) == 0 {
+		if res.Body.Len() > 0 {
+			t.Errorf("body = %q, want none", res.Body)
+		}
+		return
+	}
+
+	contentType, _, _ := strings.Cut(res.Header().Get("Content-Type"), ";")
+	content, ok := documented.Content[contentType]
+	if !ok {
+		t.Fatalf("content type %q is not documented for %s %d", contentType, op, res.Code)
+	}
+	if contentType != "application/json" {
+		return
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(res.Body.Bytes()))
+	dec.UseNumber()
+	var body any
+	if err := dec.Decode(&body); err != nil {
+		t.Fatalf("invalid JSON body: %v", err)
+	}
+	for _, problem := range validate(doc, content.Schema, body, "body") {
+		t.Error(problem)
+	}
+}
+
+// validate returns the differences between a decoded JSON value and a schema, at the path of the value
+func validate(doc document, s *schema, value any, path string) (problems []string) {
+	s = doc.resolve(s)
+	for _, sub := range s.AllOf {
+		problems = append(problems, validate(doc, sub, value, path)...)
+	}
+
+	if value == nil {
+		if s.Type != "" && !s.Nullable {
+			problems = append(problems, fmt.Sprintf("%s is null, want %s", path, s.Type))
+		}
+		return
+	}
+	if len(s.Enum) > 0 && !slices.Contains(s.Enum, value) {
+		problems = append(problems, fmt.Sprintf("%s is %v, want one of %v", path, value, s.Enum))
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return append(problems, fmt.Sprintf("%s is %T, want an object", path, value))
+		}
+		for _, key := range s.Required {
+			if _, ok := object[key]; !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s is required", path, key))
+			}
+		}
+		additional, allowed := s.additional()
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if property, ok := s.Properties[key]; ok {
+				problems = append(problems, validate(doc, property, object[key], path+"."+key)...)
+				continue
+			}
+			switch {
+			case !allowed:
+				problems = append(problems, fmt.Sprintf("%s.%s is not a property", path, key))
+			case additional != nil:
+				problems = append(problems, validate(doc, additional, object[key], path+"."+key)...)
+			}
+		}
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			return append(problems, fmt.Sprintf("%s is %T, want an array", path, value))
+		}
+		if s.Items != nil {
+			for i, item := range array {
+				problems = append(problems, validate(doc, s.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return append(problems, fmt.Sprintf("%s is %T, want a string", path, value))
+		}
+		if _, err := time.Parse(time.RFC3339Nano, str); s.Format == "date-time" && err != nil {
+			problems = append(problems, fmt.Sprintf("%s is %q, want a date-time", path, str))
+		}
+	case "integer":
+		if n, ok := value.(json.Number); !ok {
+			problems = append(problems, fmt.Sprintf("%s is %T, want an integer", path, value))
+		} else if _, err := n.Int64(); err != nil {
+			problems = append(problems, fmt.Sprintf("%s is %s, want an integer", path, n))
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			problems = append(problems, fmt.Sprintf("%s is %T, want a number", path, value))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			problems = append(problems, fmt.Sprintf("%s is %T, want a boolean", path, value))
+		}
+	}
+	return
+}
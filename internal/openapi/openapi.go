@@ -0,0 +1,56 @@
+package openapi
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// spec is the OpenAPI 3 document of the api
+//
+//go:embed openapi.json
+var spec []byte
+
+// docs is the page that renders the document with Swagger UI
+//
+//go:embed docs.html
+var docs []byte
+
+// swaggerUI are the files of Swagger UI used by the docs page, vendored so the page does not depend on a CDN
+//
+//go:embed swagger-ui/swagger-ui-bundle.js swagger-ui/swagger-ui.css
+var swaggerUI embed.FS
+
+// Spec returns the OpenAPI 3 document of the api, in JSON
+func Spec() []byte {
+	return spec
+}
+
+// Handler serves the OpenAPI document
+func Handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(spec)
+}
+
+// Docs serves the page that renders the OpenAPI document
+func Docs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(docs)
+}
+
+// Assets returns a handler that serves the files of Swagger UI under prefix, e.g. /docs/swagger-ui.css.
+// They only change with the binary, so they can be cached for a day
+func Assets(prefix string) http.Handler {
+	files, err := fs.Sub(swaggerUI, "swagger-ui")
+	if err != nil {
+		panic(err)
+	}
+	fileServer := http.StripPrefix(prefix, http.FileServer(http.FS(files)))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		fileServer.ServeHTTP(w, r)
+	})
+}
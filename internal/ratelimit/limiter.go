@@ -0,0 +1,139 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is the limit of a token bucket
+type Limit struct {
+	// Rate is the number of requests per second refilled in the bucket
+	Rate float64
+	// Burst is the capacity of the bucket, the maximum number of requests in a row
+	Burst int
+}
+
+// ConfigLimiter is a struct that represents the configuration for Limiter
+type ConfigLimiter struct {
+	// Limit is the limit of every client
+	Limit Limit
+	// Now returns the current time. By default time.Now
+	Now func() time.Time
+}
+
+// NewLimiter is a function that returns a new instance of Limiter
+func NewLimiter(cfg ConfigLimiter) *Limiter {
+	// default values
+	now := time.Now
+	if cfg.Now != nil {
+		now = cfg.Now
+	}
+
+	return &Limiter{
+		limit:   cfg.Limit,
+		now:     now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Limiter is a struct that limits the requests of each client with a token bucket kept in memory
+type Limiter struct {
+	// limit is the limit of every client
+	limit Limit
+	// now returns the current time
+	now func() time.Time
+	// mu protects buckets and calls
+	mu sync.Mutex
+	// buckets are the token buckets by client key
+	buckets map[string]*bucket
+	// calls counts the calls to Allow, to sweep the idle buckets from time to time
+	calls int
+}
+
+// bucket is the state of the token bucket of a client
+type bucket struct {
+	// tokens are the requests available, refilled up to the burst
+	tokens float64
+	// updatedAt is the time tokens was last refilled
+	updatedAt time.Time
+}
+
+// Decision is the result of a request checked by the limiter
+type Decision struct {
+	// Allowed is true if the request can be served
+	Allowed bool
+	// Limit is the burst of the bucket
+	Limit int
+	// Remaining is the number of requests still available
+	Remaining int
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, only set if the request is not allowed
+	RetryAfter time.Duration
+}
+
+// sweepEvery is the number of calls to Allow between sweeps of the idle buckets
+const sweepEvery = 1024
+
+// Allow takes a token from the bucket of the client key, if there is any
+func (l *Limiter) Allow(key string) (d Decision) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.calls++
+	if l.calls%sweepEvery == 0 {
+		l.sweep(now)
+	}
+
+	// refill the bucket
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.limit.Burst), updatedAt: now}
+		l.buckets[key] = b
+	}
+	b.refill(now, l.limit)
+
+	// take a token
+	d.Limit = l.limit.Burst
+	if b.tokens >= 1 {
+		b.tokens--
+		d.Allowed = true
+	} else {
+		d.RetryAfter = l.limit.wait(1 - b.tokens)
+	}
+	d.Remaining = int(math.Floor(b.tokens))
+	d.Reset = l.limit.wait(float64(l.limit.Burst) - b.tokens)
+
+	return
+}
+
+// sweep removes the buckets that are full, they are the same as a new bucket
+func (l *Limiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		b.refill(now, l.limit)
+		if b.tokens >= float64(l.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// refill adds the tokens accrued since the last refill
+func (b *bucket) refill(now time.Time, limit Limit) {
+	if elapsed := now.Sub(b.updatedAt); elapsed > 0 {
+		b.tokens = min(float64(limit.Burst), b.tokens+elapsed.Seconds()*limit.Rate)
+		b.updatedAt = now
+	}
+}
+
+// wait returns the time to refill a number of tokens
+func (l Limit) wait(tokens float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	if l.Rate <= 0 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(tokens / l.Rate * float64(time.Second))
+}
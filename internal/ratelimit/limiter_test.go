@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// clock is a fake clock that only moves when advanced
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time { return c.now }
+
+func (c *clock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newLimiter(limit Limit) (l *Limiter, c *clock) {
+	c = &clock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	l = NewLimiter(ConfigLimiter{Limit: limit, Now: c.Now})
+	return
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	// 2 requests per second, 4 in a row
+	l, c := newLimiter(Limit{Rate: 2, Burst: 4})
+
+	type step struct {
+		name       string
+		advance    time.Duration
+		key        string
+		allowed    bool
+		remaining  int
+		reset      time.Duration
+		retryAfter time.Duration
+	}
+	steps := []step{
+		// - the burst is available at once
+		{name: "1st of the burst", key: "a", allowed: true, remaining: 3, reset: 500 * time.Millisecond},
+		{name: "2nd of the burst", key: "a", allowed: true, remaining: 2, reset: time.Second},
+		{name: "3rd of the burst", key: "a", allowed: true, remaining: 1, reset: 1500 * time.Millisecond},
+		{name: "4th of the burst", key: "a", allowed: true, remaining: 0, reset: 2 * time.Second},
+		{name: "over the burst", key: "a", allowed: false, remaining: 0, reset: 2 * time.Second, retryAfter: 500 * time.Millisecond},
+		// - each client has its own bucket
+		{name: "other client", key: "b", allowed: true, remaining: 3, reset: 500 * time.Millisecond},
+		// - a token is refilled every 500ms
+		{name: "just before the refill", advance: 499 * time.Millisecond, key: "a", allowed: false, remaining: 0, reset: 1501 * time.Millisecond, retryAfter: time.Millisecond},
+		{name: "at the refill", advance: time.Millisecond, key: "a", allowed: true, remaining: 0, reset: 2 * time.Second},
+		// - the bucket is full again after the reset, and never over the burst
+		{name: "after the reset", advance: time.Hour, key: "a", allowed: true, remaining: 3, reset: 500 * time.Millisecond},
+	}
+
+	for _, s := range steps {
+		c.Advance(s.advance)
+		d := l.Allow(s.key)
+		if d.Allowed != s.allowed || d.Limit != 4 || d.Remaining != s.remaining || d.Reset != s.reset || d.RetryAfter != s.retryAfter {
+			t.Errorf("%s: Allow() = %+v, want allowed %t, remaining %d, reset %s, retry after %s",
+				s.name, d, s.allowed, s.remaining, s.reset, s.retryAfter)
+		}
+	}
+}
+
+func TestLimiter_Allow_NoRate(t *testing.T) {
+	l, c := newLimiter(Limit{Rate: 0, Burst: 1})
+
+	if d := l.Allow("a"); !d.Allowed {
+		t.Fatalf("Allow() = %+v, want allowed", d)
+	}
+	c.Advance(24 * time.Hour)
+	if d := l.Allow("a"); d.Allowed || d.RetryAfter <= 24*time.Hour {
+		t.Errorf("Allow() = %+v, want not allowed, never refilled", d)
+	}
+}
+
+func TestLimiter_Sweep(t *testing.T) {
+	// 1 request per second, 2 in a row
+	l, c := newLimiter(Limit{Rate: 1, Burst: 2})
+
+	l.Allow("idle")
+	l.Allow("busy")
+	l.Allow("busy")
+
+	// - just before the idle bucket is full again
+	c.Advance(999 * time.Millisecond)
+	l.sweep(c.Now())
+	if _, ok := l.buckets["idle"]; !ok {
+		t.Error("sweep() removed the bucket before it was full")
+	}
+
+	// - the idle bucket is full, the busy one is not
+	c.Advance(time.Millisecond)
+	l.sweep(c.Now())
+	if _, ok := l.buckets["idle"]; ok {
+		t.Error("sweep() kept the full bucket")
+	}
+	if _, ok := l.buckets["busy"]; !ok {
+		t.Error("sweep() removed the bucket that is not full")
+	}
+
+	// - a swept bucket starts full, as before
+	if d := l.Allow("idle"); !d.Allowed || d.Remaining != 1 {
+		t.Errorf("Allow() = %+v, want allowed with 1 remaining", d)
+	}
+
+	// - Allow sweeps every sweepEvery calls
+	c.Advance(time.Hour)
+	for i := l.calls; i%sweepEvery != sweepEvery-1; i++ {
+		l.Allow("busy")
+		c.Advance(time.Hour)
+	}
+	l.Allow("busy")
+	if len(l.buckets) != 1 {
+		t.Errorf("Allow() kept %d buckets after the sweep, want 1", len(l.buckets))
+	}
+}
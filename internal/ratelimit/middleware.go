@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"app/internal/auth"
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ClientKey returns the key of the client of a request: the subject of the authenticated principal,
+// or the ip address for anonymous requests, including the ones given the anonymous principal in insecure mode
+func ClientKey(r *http.Request) string {
+	if p, ok := auth.PrincipalFromContext(r.Context()); ok && p.Subject != auth.AnonymousSubject {
+		return "principal:" + p.Subject
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// Middleware is a middleware that limits the requests of each client, it must run after the authentication.
+// It emits the headers RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset,
+// and rejects the requests over the limit with 429 and Retry-After
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d := l.Allow(ClientKey(r))
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(d.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(seconds(d.Reset)))
+		if !d.Allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(seconds(d.RetryAfter)))
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(errorJSON{
+				Message: "Demasiadas solicitudes, intente mas tarde",
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// errorJSON is the body of an error response, with the same shape of the responses of the handlers
+type errorJSON struct {
+	Message string `json:"message"`
+	Data    any    `json:"data"`
+}
+
+// seconds returns a duration in whole seconds, rounded up
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,117 @@
+package ratelimit
+
+import (
+	"app/internal/auth"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLimiter_Middleware(t *testing.T) {
+	// 1 request every 2 seconds, 2 in a row
+	l, c := newLimiter(Limit{Rate: 0.5, Burst: 2})
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	type step struct {
+		name       string
+		advance    time.Duration
+		remoteAddr string
+		code       int
+		remaining  string
+		reset      string
+		retryAfter string
+	}
+	steps := []step{
+		{name: "1st", remoteAddr: "10.0.0.1:1234", code: http.StatusOK, remaining: "1", reset: "2"},
+		// - the port is not part of the key
+		{name: "2nd from other port", remoteAddr: "10.0.0.1:5678", code: http.StatusOK, remaining: "0", reset: "4"},
+		{name: "over the limit", remoteAddr: "10.0.0.1:1234", code: http.StatusTooManyRequests, remaining: "0", reset: "4", retryAfter: "2"},
+		// - the seconds are rounded up
+		{name: "over the limit later", advance: 1500 * time.Millisecond, remoteAddr: "10.0.0.1:1234", code: http.StatusTooManyRequests, remaining: "0", reset: "3", retryAfter: "1"},
+		{name: "after the refill", advance: 500 * time.Millisecond, remoteAddr: "10.0.0.1:1234", code: http.StatusOK, remaining: "0", reset: "4"},
+		{name: "other ip", remoteAddr: "10.0.0.2:1234", code: http.StatusOK, remaining: "1", reset: "2"},
+	}
+
+	for _, s := range steps {
+		c.Advance(s.advance)
+		req := httptest.NewRequest("GET", "/vehicles", nil)
+		req.RemoteAddr = s.remoteAddr
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, req)
+
+		if res.Code != s.code {
+			t.Errorf("%s: code = %d, want %d", s.name, res.Code, s.code)
+		}
+		header := res.Header()
+		if header.Get("RateLimit-Limit") != "2" || header.Get("RateLimit-Remaining") != s.remaining ||
+			header.Get("RateLimit-Reset") != s.reset || header.Get("Retry-After") != s.retryAfter {
+			t.Errorf("%s: headers = %v, want remaining %s, reset %s, retry after %q", s.name, header, s.remaining, s.reset, s.retryAfter)
+		}
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	req := httptest.NewRequest("GET", "/vehicles", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	if got := ClientKey(req); got != "ip:10.0.0.1" {
+		t.Errorf("ClientKey() = %q, want %q", got, "ip:10.0.0.1")
+	}
+
+	an, err := auth.NewAuthenticator(auth.ConfigAuthenticator{
+		APIKeys: map[string]auth.Principal{"key": {Subject: "ana", Role: auth.RoleReader}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Api-Key", "key")
+	var got string
+	an.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = ClientKey(r)
+	})).ServeHTTP(httptest.NewRecorder(), req)
+	if got != "principal:ana" {
+		t.Errorf("ClientKey() = %q, want %q", got, "principal:ana")
+	}
+}
+
+func TestLimiter_Middleware_Anonymous(t *testing.T) {
+	// 1 request in a row, the requests without credentials are anonymous as in insecure mode
+	l, _ := newLimiter(Limit{Rate: 0.5, Burst: 1})
+	an, err := auth.NewAuthenticator(auth.ConfigAuthenticator{
+		APIKeys:   map[string]auth.Principal{"key": {Subject: "ana", Role: auth.RoleReader}},
+		Anonymous: auth.RoleAdmin,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := an.Authenticate(l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	steps := []struct {
+		name       string
+		remoteAddr string
+		apiKey     string
+		code       int
+	}{
+		{name: "anonymous", remoteAddr: "10.0.0.1:1234", code: http.StatusOK},
+		// - the anonymous clients do not share a bucket, each ip has its own
+		{name: "anonymous from other ip", remoteAddr: "10.0.0.2:1234", code: http.StatusOK},
+		{name: "anonymous over the limit", remoteAddr: "10.0.0.1:5678", code: http.StatusTooManyRequests},
+		// - the authenticated clients are keyed by subject, not by ip
+		{name: "authenticated from a limited ip", remoteAddr: "10.0.0.1:1234", apiKey: "key", code: http.StatusOK},
+		{name: "authenticated over the limit from other ip", remoteAddr: "10.0.0.3:1234", apiKey: "key", code: http.StatusTooManyRequests},
+	}
+
+	for _, s := range steps {
+		req := httptest.NewRequest("GET", "/vehicles", nil)
+		req.RemoteAddr = s.remoteAddr
+		if s.apiKey != "" {
+			req.Header.Set("X-Api-Key", s.apiKey)
+		}
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, req)
+
+		if res.Code != s.code {
+			t.Errorf("%s: code = %d, want %d", s.name, res.Code, s.code)
+		}
+	}
+}
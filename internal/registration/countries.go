@@ -0,0 +1,50 @@
+package registration
+
+// Countries are the formats of the registrations of several countries, by ISO 3166 code.
+// The codes of subdivisions, e.g. US-CA, are used before the one of their country.
+// The patterns match normalized registrations, see internal.NormalizeRegistration
+var Countries = map[string][]Pattern{
+	// Mercosur
+	"AR": {
+		MustPattern("old", `[A-Z]{3}[0-9]{3}`),
+		MustPattern("mercosur", `[A-Z]{2}[0-9]{3}[A-Z]{2}`),
+	},
+	"BR": {
+		MustPattern("old", `[A-Z]{3}[0-9]{4}`),
+		MustPattern("mercosur", `[A-Z]{3}[0-9][A-Z][0-9]{2}`),
+	},
+	"UY": {
+		MustPattern("mercosur", `[A-Z]{3}[0-9]{4}`),
+	},
+	// European Union
+	"DE": {
+		MustPattern("standard", `[A-ZÄÖÜ]{1,3}[A-Z]{1,2}[1-9][0-9]{0,3}[EH]?`),
+	},
+	"ES": {
+		MustPattern("national", `[0-9]{4}[BCDFGHJKLMNPRSTVWXYZ]{3}`),
+	},
+	"FR": {
+		MustPattern("siv", `[A-HJ-NP-TV-Z]{2}[0-9]{3}[A-HJ-NP-TV-Z]{2}`),
+	},
+	"IT": {
+		MustPattern("standard", `[A-HJ-NPR-TV-Z]{2}[0-9]{3}[A-HJ-NPR-TV-Z]{2}`),
+	},
+	"PT": {
+		MustPattern("2020", `[A-Z]{2}[0-9]{2}[A-Z]{2}`),
+		MustPattern("2005", `[0-9]{2}[A-Z]{2}[0-9]{2}`),
+		MustPattern("1992", `[0-9]{4}[A-Z]{2}`),
+	},
+	// United States, the states not listed use the generic format
+	"US": {
+		MustPattern("generic", `[A-Z0-9]{1,8}`),
+	},
+	"US-CA": {
+		MustPattern("standard", `[1-9][A-Z]{3}[0-9]{3}`),
+	},
+	"US-NY": {
+		MustPattern("standard", `[A-Z]{3}[0-9]{4}`),
+	},
+	"US-TX": {
+		MustPattern("standard", `[A-Z]{3}[0-9]{4}`),
+	},
+}
@@ -0,0 +1,115 @@
+// Package registration validates the registrations of the vehicles with the formats of their countries
+package registration
+
+import (
+	"app/internal"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrUnknownCountry is returned when there are no formats for a country
+	ErrUnknownCountry = errors.New("registration: unknown country")
+)
+
+// Pattern is a format of registrations, a regular expression that matches the whole normalized registration
+type Pattern struct {
+	// Name is the name of the format, e.g. mercosur
+	Name string
+	// re is the regular expression anchored to the whole registration
+	re *regexp.Regexp
+}
+
+// NewPattern is a function that returns a new instance of Pattern
+func NewPattern(name, expr string) (p Pattern, err error) {
+	re, err := regexp.Compile(`^(?:` + expr + `)$`)
+	if err != nil {
+		return p, fmt.Errorf("registration: pattern %s: %w", name, err)
+	}
+	return Pattern{Name: name, re: re}, nil
+}
+
+// MustPattern is like NewPattern but panics if the expression is invalid, for the built in patterns
+func MustPattern(name, expr string) Pattern {
+	p, err := NewPattern(name, expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Match returns true if the normalized registration has the format
+func (p Pattern) Match(registration string) bool {
+	return p.re.MatchString(registration)
+}
+
+// ConfigValidator is a struct that represents the configuration for Validator
+type ConfigValidator struct {
+	// DefaultCountry is the country of the vehicles without one, empty to accept any registration of those vehicles
+	DefaultCountry string
+	// Countries are the formats by country, by default Countries
+	Countries map[string][]Pattern
+}
+
+// NewValidator is a function that returns a new instance of Validator
+func NewValidator(cfg ConfigValidator) (v *Validator, err error) {
+	v = &Validator{
+		defaultCountry: internal.NormalizeCountry(cfg.DefaultCountry),
+		countries:      cfg.Countries,
+	}
+	if v.countries == nil {
+		v.countries = Countries
+	}
+
+	if v.defaultCountry != "" {
+		if _, ok := v.patterns(v.defaultCountry); !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownCountry, v.defaultCountry)
+		}
+	}
+	return
+}
+
+// Validator is a struct that implements the RegistrationValidator interface with the formats of each country
+type Validator struct {
+	// defaultCountry is the country of the vehicles without one, empty if their registrations are not validated
+	defaultCountry string
+	// countries are the formats by country
+	countries map[string][]Pattern
+}
+
+// Validate returns an error if the registration has not a format of the country
+func (v *Validator) Validate(country, registration string) (err error) {
+	country = internal.NormalizeCountry(country)
+	if country == "" {
+		country = v.defaultCountry
+	}
+	if country == "" {
+		return
+	}
+
+	patterns, ok := v.patterns(country)
+	if !ok {
+		return &internal.ErrInvalidAttributes{Attr: "Country"}
+	}
+
+	registration = internal.NormalizeRegistration(registration)
+	for _, p := range patterns {
+		if p.Match(registration) {
+			return nil
+		}
+	}
+	return &internal.ErrInvalidAttributes{Attr: "Registration"}
+}
+
+// patterns returns the formats of a country, the ones of the country of a subdivision without its own formats
+func (v *Validator) patterns(country string) (patterns []Pattern, ok bool) {
+	if patterns, ok = v.countries[country]; ok {
+		return
+	}
+	if parent, _, found := strings.Cut(country, "-"); found {
+		patterns, ok = v.countries[parent]
+	}
+	return
+}
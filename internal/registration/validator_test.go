@@ -0,0 +1,135 @@
+package registration
+
+import (
+	"app/internal"
+	"errors"
+	"testing"
+)
+
+func TestValidator_Validate(t *testing.T) {
+	tests := []struct {
+		name           string
+		defaultCountry string
+		country        string
+		registration   string
+		// wantAttr is the invalid attribute, empty if the registration is valid
+		wantAttr string
+	}{
+		// - the vehicles without country are only validated with a default country
+		{name: "no country", registration: "0"},
+		{name: "default country, old plate", defaultCountry: "AR", registration: "ABC123"},
+		{name: "default country, mercosur plate", defaultCountry: "AR", registration: "AB123CD"},
+		{name: "default country, invalid", defaultCountry: "AR", registration: "0", wantAttr: "Registration"},
+		{name: "country of the vehicle over the default one", defaultCountry: "AR", country: "ES", registration: "1234BCD"},
+
+		// - normalization of the registration and the country
+		{name: "lowercase with spaces and dashes", country: "AR", registration: "ab 123-cd"},
+		{name: "country in lowercase", country: " ar ", registration: "AB123CD"},
+
+		// - the whole registration must match
+		{name: "prefix of a plate", country: "AR", registration: "AB123CDE", wantAttr: "Registration"},
+		{name: "suffix of a plate", country: "AR", registration: "XAB123CD", wantAttr: "Registration"},
+
+		// - formats by country
+		{name: "brazil mercosur", country: "BR", registration: "ABC1D23"},
+		{name: "brazil old", country: "BR", registration: "ABC1234"},
+		{name: "brazil argentinian plate", country: "BR", registration: "AB123CD", wantAttr: "Registration"},
+		{name: "uruguay", country: "UY", registration: "SBA1234"},
+		{name: "germany", country: "DE", registration: "B-MW 1234"},
+		{name: "germany electric", country: "DE", registration: "M AB 123 E"},
+		{name: "germany leading zero", country: "DE", registration: "M AB 0123", wantAttr: "Registration"},
+		{name: "spain", country: "ES", registration: "1234 BCD"},
+		{name: "spain with a vowel", country: "ES", registration: "1234 BCA", wantAttr: "Registration"},
+		{name: "france", country: "FR", registration: "AB-123-CD"},
+		{name: "france with an O", country: "FR", registration: "OB-123-CD", wantAttr: "Registration"},
+		{name: "italy", country: "IT", registration: "AB 123 CD"},
+		{name: "portugal 2020", country: "PT", registration: "AA-00-AA"},
+		{name: "portugal 2005", country: "PT", registration: "00-AA-00"},
+		{name: "portugal 1992", country: "PT", registration: "00-00-AA"},
+
+		// - the subdivisions use their formats, or the ones of their country
+		{name: "california", country: "US-CA", registration: "7ABC123"},
+		{name: "california generic plate", country: "US-CA", registration: "ABC", wantAttr: "Registration"},
+		{name: "state without formats", country: "US-OH", registration: "ABC", wantAttr: ""},
+		{name: "united states too long", country: "US", registration: "ABCDEFGHI", wantAttr: "Registration"},
+
+		{name: "unknown country", country: "XX", registration: "AB123CD", wantAttr: "Country"},
+		{name: "unknown subdivision", country: "XX-YY", registration: "AB123CD", wantAttr: "Country"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := NewValidator(ConfigValidator{DefaultCountry: tt.defaultCountry})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = v.Validate(tt.country, tt.registration)
+			var errInv *internal.ErrInvalidAttributes
+			switch {
+			case tt.wantAttr == "" && err != nil:
+				t.Errorf("Validate() = %v, want nil", err)
+			case tt.wantAttr != "" && (!errors.As(err, &errInv) || errInv.Attr != tt.wantAttr):
+				t.Errorf("Validate() = %v, want an invalid %s", err, tt.wantAttr)
+			}
+		})
+	}
+}
+
+func TestNewValidator(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ConfigValidator
+		wantErr error
+	}{
+		{name: "no default country", cfg: ConfigValidator{}},
+		{name: "default country", cfg: ConfigValidator{DefaultCountry: "ar"}},
+		{name: "default subdivision of a country", cfg: ConfigValidator{DefaultCountry: "US-OH"}},
+		{name: "unknown default country", cfg: ConfigValidator{DefaultCountry: "XX"}, wantErr: ErrUnknownCountry},
+		{name: "default country not in the custom countries", cfg: ConfigValidator{
+			DefaultCountry: "AR",
+			Countries:      map[string][]Pattern{"CL": {MustPattern("standard", `[A-Z]{4}[0-9]{2}`)}},
+		}, wantErr: ErrUnknownCountry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewValidator(tt.cfg)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewValidator() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidator_Validate_Countries(t *testing.T) {
+	v, err := NewValidator(ConfigValidator{
+		DefaultCountry: "CL",
+		Countries:      map[string][]Pattern{"CL": {MustPattern("standard", `[A-Z]{4}[0-9]{2}`)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.Validate("", "bc-df-12"); err != nil {
+		t.Errorf("Validate() of a plate of the custom country = %v", err)
+	}
+	if err := v.Validate("AR", "AB123CD"); err == nil {
+		t.Error("Validate() of a country not in the custom countries = nil, want an error")
+	}
+}
+
+func TestNewPattern(t *testing.T) {
+	if _, err := NewPattern("broken", `[A-Z`); err == nil {
+		t.Error("NewPattern() of an invalid expression = nil, want an error")
+	}
+
+	// - the alternatives are anchored together
+	p, err := NewPattern("either", `AB|CD`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Match("AB") || !p.Match("CD") || p.Match("ABX") || p.Match("XCD") {
+		t.Error("Match() does not match the whole registration")
+	}
+}
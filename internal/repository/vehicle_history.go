@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"app/internal"
+	"app/internal/tracing"
+	"context"
+	"time"
+)
+
+// vehicleRecord is a version of a vehicle kept by the repository
+type vehicleRecord struct {
+	// vehicle is the state of the vehicle in this version
+	vehicle internal.Vehicle
+	// recordedAt is the time from which this state was current
+	recordedAt time.Time
+	// deleted is true if the vehicle was deleted at recordedAt, so it did not exist from then on
+	deleted bool
+}
+
+// record appends a version of a vehicle to its history
+func (r *VehicleMap) record(v internal.Vehicle, deleted bool) {
+	r.history[v.Id] = append(r.history[v.Id], vehicleRecord{
+		vehicle:    v,
+		recordedAt: r.modifiedAt,
+		deleted:    deleted,
+	})
+}
+
+// FindByIdAsOf returns the state of the vehicle with the given id at a point in time
+func (r *VehicleMap) FindByIdAsOf(ctx context.Context, id int, asOf time.Time) (v internal.Vehicle, err error) {
+	ctx, span := tracing.Start(ctx, "repository.VehicleMap.FindByIdAsOf")
+	defer span.EndError(&err)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// the records are sorted by time, find the last one before asOf
+	var current *vehicleRecord
+	for i, rec := range r.history[id] {
+		if rec.recordedAt.After(asOf) {
+			break
+		}
+		current = &r.history[id][i]
+	}
+
+	// the vehicle did not exist or was deleted at that time
+	if current == nil || current.deleted {
+		return v, internal.ErrVehicleNotFound
+	}
+
+	v = current.vehicle
+	return
+}
+
+// FindVersion returns a version of the vehicle with the given id
+func (r *VehicleMap) FindVersion(ctx context.Context, id int, version int) (v internal.Vehicle, err error) {
+	ctx, span := tracing.Start(ctx, "repository.VehicleMap.FindVersion")
+	defer span.EndError(&err)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records, ok := r.history[id]
+	if !ok {
+		return v, internal.ErrVehicleNotFound
+	}
+
+	for _, rec := range records {
+		if !rec.deleted && rec.vehicle.Version == version {
+			return rec.vehicle, nil
+		}
+	}
+	return v, internal.ErrVersionNotFound
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVehicleMap_FindByIdAsOf_Load(t *testing.T) {
+	ctx := context.Background()
+	rp := NewVehicleMap(map[int]internal.Vehicle{
+		1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Registration: "A1", Color: "Red"}},
+	}, nil)
+	loadedAt := rp.modifiedAt
+
+	// a point in time after the first load and before the reload
+	time.Sleep(2 * time.Millisecond)
+	between := time.Now()
+	time.Sleep(2 * time.Millisecond)
+
+	rp.Load(ctx, map[int]internal.Vehicle{
+		1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Registration: "A1", Color: "Blue"}},
+	})
+	if !rp.modifiedAt.After(between) {
+		t.Fatalf("modifiedAt = %v, want after %v", rp.modifiedAt, between)
+	}
+
+	// - the history before the reload is discarded, the reloaded state did not exist yet
+	if v, err := rp.FindByIdAsOf(ctx, 1, between); !errors.Is(err, internal.ErrVehicleNotFound) {
+		t.Errorf("FindByIdAsOf(between) = %v, %v, want ErrVehicleNotFound", v.Color, err)
+	}
+	if _, err := rp.FindByIdAsOf(ctx, 1, loadedAt); !errors.Is(err, internal.ErrVehicleNotFound) {
+		t.Errorf("FindByIdAsOf(first load) error = %v, want ErrVehicleNotFound", err)
+	}
+
+	// - from the reload on, the reloaded state
+	v, err := rp.FindByIdAsOf(ctx, 1, time.Now())
+	if err != nil || v.Color != "Blue" {
+		t.Errorf("FindByIdAsOf(now) = %v, %v, want Blue", v.Color, err)
+	}
+}
+
+func TestVehicleMap_FindByIdAsOf_Update(t *testing.T) {
+	ctx := context.Background()
+	rp := NewVehicleMap(map[int]internal.Vehicle{
+		1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Registration: "A1", Color: "Red"}},
+	}, nil)
+
+	time.Sleep(2 * time.Millisecond)
+	before := time.Now()
+	time.Sleep(2 * time.Millisecond)
+
+	v, _ := rp.FindById(ctx, 1)
+	v.Color = "Blue"
+	if _, _, err := rp.Update(ctx, v); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err := rp.FindByIdAsOf(ctx, 1, before); err != nil || v.Color != "Red" {
+		t.Errorf("FindByIdAsOf(before) = %v, %v, want Red", v.Color, err)
+	}
+	if v, err := rp.FindByIdAsOf(ctx, 1, time.Now()); err != nil || v.Color != "Blue" {
+		t.Errorf("FindByIdAsOf(now) = %v, %v, want Blue", v.Color, err)
+	}
+}
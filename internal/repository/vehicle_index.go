@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"app/internal"
+	"app/internal/utilities"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// prefixWeight is the weight of a term that only matches the start of an indexed term,
+// e.g. "hum" for "hummer"
+const prefixWeight = 0.5
+
+// newVehicleIndex is a function that returns a new empty vehicleIndex
+func newVehicleIndex() *vehicleIndex {
+	return &vehicleIndex{
+		postings: make(map[string]map[int]int),
+		terms:    make(map[int][]string),
+	}
+}
+
+// vehicleIndex is an inverted index of the text attributes of the vehicles
+type vehicleIndex struct {
+	// postings is the frequency of each term in each vehicle, by term and vehicle id
+	postings map[string]map[int]int
+	// terms are the terms indexed for each vehicle id, used to remove a vehicle
+	terms map[int][]string
+}
+
+// tokenize splits a text into normalized terms
+func tokenize(text string) []string {
+	return strings.FieldsFunc(utilities.Normalize(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// add indexes the attributes of a vehicle
+func (ix *vehicleIndex) add(v internal.Vehicle) {
+	var terms []string
+	for _, text := range []string{
+		v.Brand,
+		v.Model,
+		v.Color,
+		v.FuelType,
+		v.Transmission,
+		v.Registration,
+		v.Vin,
+		strconv.Itoa(v.FabricationYear),
+	} {
+		terms = append(terms, tokenize(text)...)
+	}
+
+	for _, term := range terms {
+		if ix.postings[term] == nil {
+			ix.postings[term] = make(map[int]int)
+		}
+		ix.postings[term][v.Id]++
+	}
+	ix.terms[v.Id] = terms
+}
+
+// remove removes a vehicle from the index
+func (ix *vehicleIndex) remove(id int) {
+	for _, term := range ix.terms[id] {
+		delete(ix.postings[term], id)
+		if len(ix.postings[term]) == 0 {
+			delete(ix.postings, term)
+		}
+	}
+	delete(ix.terms, id)
+}
+
+// search returns the ids of the vehicles that match any term of the query with their score.
+// Each matching term adds its frequency in the vehicle weighted by its rarity (tf-idf),
+// so vehicles matching more and rarer terms rank first
+func (ix *vehicleIndex) search(query string) (scores map[int]float64) {
+	scores = make(map[int]float64)
+	total := float64(len(ix.terms))
+
+	for _, term := range tokenize(query) {
+		// exact matches
+		if postings, ok := ix.postings[term]; ok {
+			idf := math.Log(1 + total/float64(len(postings)))
+			for id, tf := range postings {
+				scores[id] += float64(tf) * idf
+			}
+			continue
+		}
+
+		// prefix matches, counted once per vehicle
+		matched := make(map[int]float64)
+		for indexed, postings := range ix.postings {
+			if !strings.HasPrefix(indexed, term) {
+				continue
+			}
+			idf := math.Log(1 + total/float64(len(postings)))
+			for id, tf := range postings {
+				matched[id] = max(matched[id], float64(tf)*idf*prefixWeight)
+			}
+		}
+		for id, score := range matched {
+			scores[id] += score
+		}
+	}
+
+	return
+}
+
+// sortSearchResults sorts the results by score, and by id on ties
+func sortSearchResults(results []internal.VehicleSearchResult) {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Id < results[j].Id
+	})
+}
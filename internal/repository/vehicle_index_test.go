@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"testing"
+)
+
+// searchIds returns the ids of the results of a search, by relevance
+func searchIds(t *testing.T, rp *VehicleMap, query string) (ids []int) {
+	t.Helper()
+	results, err := rp.Search(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids = []int{}
+	for _, r := range results {
+		ids = append(ids, r.Id)
+	}
+	return
+}
+
+// searchScores returns the scores of the results of a search, by id
+func searchScores(t *testing.T, rp *VehicleMap, query string) (scores map[int]float64) {
+	t.Helper()
+	results, err := rp.Search(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scores = make(map[int]float64)
+	for _, r := range results {
+		scores[r.Id] = r.Score
+	}
+	return
+}
+
+// newSearchFleet returns a repository with vehicles that share some of the terms of the queries
+func newSearchFleet() *VehicleMap {
+	return NewVehicleMap(map[int]internal.Vehicle{
+		1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Hummer", Model: "H2", Registration: "A1", Color: "Orange", FabricationYear: 2008}},
+		2: {Id: 2, VehicleAttributes: internal.VehicleAttributes{Brand: "Hummer", Model: "H2", Registration: "A2", Color: "Mauv", FabricationYear: 2004}},
+		3: {Id: 3, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Model: "Focus", Registration: "A3", Color: "Orange", FabricationYear: 2008}},
+		4: {Id: 4, VehicleAttributes: internal.VehicleAttributes{Brand: "Fiat", Model: "Uno", Registration: "A4", Color: "White", FabricationYear: 2008}},
+		5: {Id: 5, VehicleAttributes: internal.VehicleAttributes{Brand: "Humber", Model: "Sceptre", Registration: "A5", Color: "Green", FabricationYear: 1965}},
+	}, nil)
+}
+
+func TestVehicleMap_Search(t *testing.T) {
+	rp := newSearchFleet()
+
+	tests := []struct {
+		name  string
+		query string
+		ids   []int
+	}{
+		// - the vehicle matching every term first, then the ones matching the rarer terms
+		{name: "full match first", query: "orange hummer 2008", ids: []int{1, 3, 2, 4}},
+		{name: "case and accents ignored", query: "HÚMMER", ids: []int{1, 2}},
+		{name: "prefix of a term", query: "hum", ids: []int{5, 1, 2}},
+		{name: "no match", query: "tesla", ids: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := searchIds(t, rp, tt.query)
+			if len(ids) != len(tt.ids) {
+				t.Fatalf("Search(%q) = %v, want %v", tt.query, ids, tt.ids)
+			}
+			for i := range ids {
+				if ids[i] != tt.ids[i] {
+					t.Fatalf("Search(%q) = %v, want %v", tt.query, ids, tt.ids)
+				}
+			}
+		})
+	}
+}
+
+func TestVehicleMap_Search_Prefix(t *testing.T) {
+	rp := newSearchFleet()
+
+	// - a prefix scores below the exact term, for the same vehicle
+	exact := searchScores(t, rp, "hummer")
+	prefix := searchScores(t, rp, "humm")
+	if exact[1] == 0 || prefix[1] >= exact[1] {
+		t.Errorf("Search(humm) score = %v, want below the score of Search(hummer) %v", prefix[1], exact[1])
+	}
+
+	// - a term that is indexed is matched exactly, not as a prefix of longer terms
+	if scores := searchScores(t, rp, "h2"); len(scores) != 2 {
+		t.Errorf("Search(h2) = %v, want the two H2", scores)
+	}
+
+	// - a prefix of two terms of a vehicle, hummer and h2 as frequent as it, is counted once
+	both := searchScores(t, rp, "h")
+	if both[1] != prefix[1] {
+		t.Errorf("Search(h) score = %v, want the one of a single prefix match %v", both[1], prefix[1])
+	}
+}
+
+func TestVehicleMap_Search_Index(t *testing.T) {
+	ctx := context.Background()
+	rp := newSearchFleet()
+
+	// - an update re-indexes the changed terms only
+	v, err := rp.FindById(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Color = "Yellow"
+	if _, _, err = rp.Update(ctx, v); err != nil {
+		t.Fatal(err)
+	}
+	if ids := searchIds(t, rp, "orange"); len(ids) != 1 || ids[0] != 3 {
+		t.Errorf("Search(orange) after the update = %v, want [3]", ids)
+	}
+	if ids := searchIds(t, rp, "yellow"); len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("Search(yellow) after the update = %v, want [1]", ids)
+	}
+	if ids := searchIds(t, rp, "hummer"); len(ids) != 2 {
+		t.Errorf("Search(hummer) after the update = %v, want the two Hummer", ids)
+	}
+
+	// - a delete removes the vehicle from the results, and a restore adds it back
+	if _, err = rp.Delete(ctx, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if ids := searchIds(t, rp, "yellow hummer"); len(ids) != 1 || ids[0] != 2 {
+		t.Errorf("Search(yellow hummer) after the delete = %v, want [2]", ids)
+	}
+	if _, err = rp.Restore(ctx, 1, 3); err != nil {
+		t.Fatal(err)
+	}
+	if ids := searchIds(t, rp, "yellow hummer"); len(ids) != 2 || ids[0] != 1 {
+		t.Errorf("Search(yellow hummer) after the restore = %v, want 1 first", ids)
+	}
+
+	// - the terms of the removed vehicles are not left in the index
+	if _, ok := rp.index.postings["orange"][1]; ok {
+		t.Error("index keeps the previous color of the vehicle 1")
+	}
+	if _, ok := rp.index.postings["mauv"]; !ok {
+		t.Error("index misses the terms of the vehicle 2")
+	}
+}
@@ -1,158 +1,297 @@
 package repository
 
-import "app/internal"
+import (
+	"app/internal"
+	"app/internal/logging"
+	"app/internal/tracing"
+	"app/internal/utilities"
+	"app/internal/vin"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// NewVehicleMap is a function that returns a new instance of VehicleMap.
+// lg can be nil to use the default logger
+func NewVehicleMap(db map[int]internal.Vehicle, lg *slog.Logger) *VehicleMap {
+	rp := &VehicleMap{
+		modifiedAt: time.Now(),
+		lg:         logging.OrDefault(lg),
+	}
+	rp.reset(db)
+
+	return rp
+}
 
-// NewVehicleMap is a function that returns a new instance of VehicleMap
-func NewVehicleMap(db map[int]internal.Vehicle) *VehicleMap {
+// reset replaces the vehicles of the repository with the ones of db, indexing them and recording their first version.
+// The loaded vehicles start at version 1
+func (r *VehicleMap) reset(db map[int]internal.Vehicle) {
 	// default db
 	defaultDb := make(map[int]internal.Vehicle)
 	if db != nil {
 		defaultDb = db
 	}
-	return &VehicleMap{db: defaultDb}
+	r.db = defaultDb
+	r.index = newVehicleIndex()
+	r.history = make(map[int][]vehicleRecord)
+
+	for key, v := range defaultDb {
+		if v.Version == 0 {
+			v.Version = 1
+			defaultDb[key] = v
+		}
+		if v.DeletedAt == nil {
+			r.index.add(v)
+		}
+		r.record(v, v.DeletedAt != nil)
+		r.lastId = max(r.lastId, key)
+	}
+}
+
+// Load replaces all the vehicles of the repository with the ones of db, e.g. when the source file is reloaded.
+// The history of the previous vehicles is discarded, their ids are not reused
+func (r *VehicleMap) Load(ctx context.Context, db map[int]internal.Vehicle) {
+	_, span := tracing.Start(ctx, "repository.VehicleMap.Load", tracing.Int("vehicle.count", len(db)))
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// - the loaded versions are recorded at the time of the reload, not of the previous change
+	r.touch()
+	r.reset(db)
+	r.lg.DebugContext(ctx, "vehicles loaded", "count", len(r.db), "revision", r.revision)
 }
 
 // VehicleMap is a struct that represents a vehicle repository
 type VehicleMap struct {
+	// mu protects db and index, writes check the version and update it atomically
+	mu sync.RWMutex
 	// db is a map of vehicles
 	db map[int]internal.Vehicle
+	// index is the full-text index of the vehicles
+	index *vehicleIndex
+	// lastId is the last id assigned to a vehicle
+	lastId int
+	// history are the versions of each vehicle by id, oldest first
+	history map[int][]vehicleRecord
+	// revision is incremented on every modification of db
+	revision uint64
+	// modifiedAt is the time of the last modification of db
+	modifiedAt time.Time
+	// lg is the logger of the writes
+	lg *slog.Logger
+}
+
+// touch registers a modification of the db
+func (r *VehicleMap) touch() {
+	r.revision++
+	r.modifiedAt = time.Now()
 }
 
-// getLastId is a method that returns the last id of the db
+// getLastId is a method that returns the last id used, including the purged vehicles so their ids are not reused
 func (r *VehicleMap) getLastId() (id int) {
-	for key := range r.db {
-		if key > id {
-			id = key
-		}
-	}
-	return
+	return r.lastId
+}
+
+// sameRegistration returns true if two registrations are the same once normalized, e.g. ab-123 and AB 123
+func sameRegistration(a, b string) bool {
+	return internal.NormalizeRegistration(a) == internal.NormalizeRegistration(b)
+}
+
+// sameVin returns true if two VINs are known and the same once normalized
+func sameVin(a, b string) bool {
+	return a != "" && vin.Normalize(a) == vin.Normalize(b)
 }
 
 // FindAll is a method that returns a map of all vehicles
-func (r *VehicleMap) FindAll() (v map[int]internal.Vehicle, err error) {
+func (r *VehicleMap) FindAll(ctx context.Context) (v map[int]internal.Vehicle, err error) {
+	ctx, span := tracing.Start(ctx, "repository.VehicleMap.FindAll")
+	defer span.EndError(&err)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	v = make(map[int]internal.Vehicle)
 
-	// copy db
+	// copy db, except the deleted vehicles
 	for key, value := range r.db {
+		if value.DeletedAt != nil {
+			continue
+		}
 		v[key] = value
 	}
+	span.SetAttributes(tracing.Int("vehicle.count", len(v)))
 
 	return
 }
 
 // Add is a method that adds a new vehicle to the db
-func (r *VehicleMap) Add(newVehicle internal.Vehicle) (v internal.Vehicle, err error) {
+func (r *VehicleMap) Add(ctx context.Context, newVehicle internal.Vehicle) (v internal.Vehicle, err error) {
+	ctx, span := tracing.Start(ctx, "repository.VehicleMap.Add")
+	defer span.EndError(&err)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
 	// check if registration already exists
 	for _, v := range r.db {
-		if v.Registration == newVehicle.Registration {
+		if sameRegistration(v.Registration, newVehicle.Registration) {
 			return v, internal.ErrVehicleExistent
 		}
 	}
 
+	// check if vin already exists
+	for _, v := range r.db {
+		if sameVin(v.Vin, newVehicle.Vin) {
+			return v, internal.ErrVinExistent
+		}
+	}
+
 	// get the last id
 	lastId := r.getLastId()
 	id := lastId + 1
 
 	// add vehicle
 	newVehicle.Id = id
+	newVehicle.Version = 1
+	newVehicle.DeletedAt = nil
+	r.lastId = id
 	r.db[id] = newVehicle
+	r.index.add(newVehicle)
+	r.touch()
+	r.record(newVehicle, false)
+	r.lg.DebugContext(ctx, "vehicle stored", "op", "add", "vehicle_id", id, "revision", r.revision)
 
 	v = r.db[id]
 	return
 }
 
+// matchText returns true if the text of a vehicle matches the text of a filter,
+// ignoring case and accents and, in fuzzy mode, tolerating misspellings
+func matchText(filter, value string, fuzzy bool) bool {
+	if fuzzy {
+		return utilities.FuzzyEqual(filter, value)
+	}
+	return utilities.EqualNormalized(filter, value)
+}
+
 // FindAllEqualTo returns a map of vehicles that passed the filters
-func (r *VehicleMap) FindAllEqualTo(filter internal.EqualFilter) (v map[int]internal.Vehicle, err error) {
+func (r *VehicleMap) FindAllEqualTo(ctx context.Context, filter internal.EqualFilter) (v map[int]internal.Vehicle, err error) {
+	ctx, span := tracing.Start(ctx, "repository.VehicleMap.FindAllEqualTo")
+	defer span.EndError(&err)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
 	v = make(map[int]internal.Vehicle)
 
 	/* All of this can be improved using reflect and saving the non-empty fields into a map */
 	for key, value := range r.db {
-		// if the field is not zero value is because i want to filter using this field
+		// if the field is set is because i want to filter using this field
 
 		/* Esto se lee como: si quiero filtrar por este campo, pero el vehiculo no cumple, continuo */
-		if filter.Brand != "" && filter.Brand != value.Brand {
+		if !filter.IncludeDeleted && value.DeletedAt != nil {
+			continue
+		}
+
+		if filter.Brand != "" && !matchText(filter.Brand, value.Brand, filter.Fuzzy) {
 			continue
 		}
 
-		if filter.Model != "" && filter.Model != value.Model {
+		if filter.Model != "" && !matchText(filter.Model, value.Model, filter.Fuzzy) {
 			continue
 		}
 
-		if filter.Color != "" && filter.Color != value.Color {
+		if filter.Color != "" && !matchText(filter.Color, value.Color, filter.Fuzzy) {
 			continue
 		}
 
-		if filter.FabricationYear != 0 && filter.FabricationYear != value.FabricationYear {
+		if filter.FabricationYear != nil && *filter.FabricationYear != value.FabricationYear {
 			continue
 		}
 
-		if filter.Capacity != 0 && filter.Capacity != value.Capacity {
+		if filter.Capacity != nil && *filter.Capacity != value.Capacity {
 			continue
 		}
 
-		if filter.FuelType != "" && filter.FuelType != value.FuelType {
+		if filter.FuelType != "" && !matchText(filter.FuelType, value.FuelType, filter.Fuzzy) {
 			continue
 		}
 
-		if filter.Transmission != "" && filter.Transmission != value.Transmission {
+		if filter.Transmission != "" && !matchText(filter.Transmission, value.Transmission, filter.Fuzzy) {
 			continue
 		}
 
-		// filters by range
+		// filters by range, any of the bounds can be omitted
 
-		if filter.FabricationYearRange[0] != 0 && filter.FabricationYearRange[1] != 0 &&
-			// is out of range
-			(value.FabricationYear < filter.FabricationYearRange[0] || value.FabricationYear > filter.FabricationYearRange[1]) {
+		if !filter.FabricationYearRange.Contains(value.FabricationYear) {
 			continue
 		}
 
-		if filter.LengthRange[0] != 0 && filter.LengthRange[1] != 0 &&
-			// is out of range
-			(value.Length < filter.LengthRange[0] || value.Length > filter.LengthRange[1]) {
+		if !filter.LengthRange.Contains(value.Length) {
 			continue
 		}
 
-		if filter.WidthRange[0] != 0 && filter.WidthRange[1] != 0 &&
-			// is out of range
-			(value.Width < filter.WidthRange[0] || value.Width > filter.WidthRange[1]) {
+		if !filter.WidthRange.Contains(value.Width) {
 			continue
 		}
 
-		if filter.WeightRange[0] != 0 && filter.WeightRange[1] != 0 &&
-			// is out of range
-			(value.Weight < filter.WeightRange[0] || value.Weight > filter.WeightRange[1]) {
+		if !filter.WeightRange.Contains(value.Weight) {
 			continue
 		}
 
 		// if all filters passed, add to the map
 		v[key] = value
 	}
+	span.SetAttributes(tracing.Int("vehicle.count", len(v)))
 
 	return v, nil
 
 }
 
-func (r *VehicleMap) Update(vehicle internal.Vehicle) (v internal.Vehicle, err error) {
+// Update updates an existent vehicle, if vehicle.Version is the current version of the vehicle,
+// returning also the state it replaced
+func (r *VehicleMap) Update(ctx context.Context, vehicle internal.Vehicle) (v, previous internal.Vehicle, err error) {
+	ctx, span := tracing.Start(ctx, "repository.VehicleMap.Update")
+	defer span.EndError(&err)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
 	// get the vehicle
 	v, ok := r.db[vehicle.Id]
-	if !ok {
-		return v, internal.ErrVehicleNotFound
+	if !ok || v.DeletedAt != nil {
+		return v, previous, internal.ErrVehicleNotFound
+	}
+
+	// check if the vehicle was updated since it was read
+	if v.Version != vehicle.Version {
+		return v, previous, internal.ErrVehicleConflict
 	}
 
 	// check if registration already exists
 	for _, v := range r.db {
-		if v.Id != vehicle.Id && v.Registration == vehicle.Registration {
-			return v, internal.ErrVehicleExistent
+		if v.Id != vehicle.Id && sameRegistration(v.Registration, vehicle.Registration) {
+			return v, previous, internal.ErrVehicleExistent
+		}
+	}
+
+	// check if vin already exists
+	for _, v := range r.db {
+		if v.Id != vehicle.Id && sameVin(v.Vin, vehicle.Vin) {
+			return v, previous, internal.ErrVinExistent
 		}
 	}
 
 	// update
+	previous = v
 	v.Brand = vehicle.Brand
 	v.Model = vehicle.Model
 	v.Registration = vehicle.Registration
+	v.Country = vehicle.Country
+	v.Vin = vehicle.Vin
 	v.Color = vehicle.Color
 	v.FabricationYear = vehicle.FabricationYear
 	v.Capacity = vehicle.Capacity
@@ -163,8 +302,191 @@ func (r *VehicleMap) Update(vehicle internal.Vehicle) (v internal.Vehicle, err e
 	v.Height = vehicle.Height
 	v.Length = vehicle.Length
 	v.Width = vehicle.Width
+	v.Version++
 
 	r.db[vehicle.Id] = v
+	r.index.remove(v.Id)
+	r.index.add(v)
+	r.touch()
+	r.record(v, false)
+	r.lg.DebugContext(ctx, "vehicle stored", "op", "update", "vehicle_id", v.Id, "version", v.Version, "revision", r.revision)
 
-	return v, nil
+	return v, previous, nil
+}
+
+// FindAllMatching returns a map of vehicles that satisfy the matcher
+func (r *VehicleMap) FindAllMatching(ctx context.Context, m internal.VehicleMatcher) (v map[int]internal.Vehicle, err error) {
+	ctx, span := tracing.Start(ctx, "repository.VehicleMap.FindAllMatching")
+	defer span.EndError(&err)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v = make(map[int]internal.Vehicle)
+
+	for key, value := range r.db {
+		if value.DeletedAt == nil && m.Match(value) {
+			v[key] = value
+		}
+	}
+	span.SetAttributes(tracing.Int("vehicle.count", len(v)))
+
+	return
+}
+
+// Search returns the vehicles that match the text of the query, sorted by relevance
+func (r *VehicleMap) Search(ctx context.Context, query string) (results []internal.VehicleSearchResult, err error) {
+	ctx, span := tracing.Start(ctx, "repository.VehicleMap.Search")
+	defer span.EndError(&err)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	results = []internal.VehicleSearchResult{}
+	for id, score := range r.index.search(query) {
+		results = append(results, internal.VehicleSearchResult{
+			Vehicle: r.db[id],
+			Score:   score,
+		})
+	}
+	sortSearchResults(results)
+	span.SetAttributes(tracing.Int("vehicle.count", len(results)))
+
+	return
+}
+
+// FindById returns the vehicle with the given id, even if it is deleted
+func (r *VehicleMap) FindById(ctx context.Context, id int) (v internal.Vehicle, err error) {
+	ctx, span := tracing.Start(ctx, "repository.VehicleMap.FindById")
+	defer span.EndError(&err)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v, ok := r.db[id]
+	if !ok {
+		return v, internal.ErrVehicleNotFound
+	}
+	return
+}
+
+// FindByVin returns the vehicle with the given VIN, compared normalized as the loaded VINs can be written in any way,
+// even if it is deleted
+func (r *VehicleMap) FindByVin(ctx context.Context, vin string) (v internal.Vehicle, err error) {
+	ctx, span := tracing.Start(ctx, "repository.VehicleMap.FindByVin")
+	defer span.EndError(&err)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, value := range r.db {
+		if sameVin(value.Vin, vin) {
+			return value, nil
+		}
+	}
+	return v, internal.ErrVehicleNotFound
+}
+
+// Delete soft deletes an existent vehicle, if version is its current version, returning the state it replaced
+func (r *VehicleMap) Delete(ctx context.Context, id int, version int) (previous internal.Vehicle, err error) {
+	ctx, span := tracing.Start(ctx, "repository.VehicleMap.Delete")
+	defer span.EndError(&err)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// get the vehicle
+	v, ok := r.db[id]
+	if !ok || v.DeletedAt != nil {
+		return previous, internal.ErrVehicleNotFound
+	}
+
+	// check if the vehicle was updated since it was read
+	if v.Version != version {
+		return previous, internal.ErrVehicleConflict
+	}
+
+	// mark as deleted
+	previous = v
+	r.touch()
+	deletedAt := r.modifiedAt
+	v.DeletedAt = &deletedAt
+	v.Version++
+	r.db[id] = v
+	r.index.remove(id)
+	r.record(v, true)
+	r.lg.DebugContext(ctx, "vehicle stored", "op", "delete", "vehicle_id", id, "version", v.Version, "revision", r.revision)
+
+	return
+}
+
+// Restore restores a deleted vehicle, if version is its current version
+func (r *VehicleMap) Restore(ctx context.Context, id int, version int) (v internal.Vehicle, err error) {
+	ctx, span := tracing.Start(ctx, "repository.VehicleMap.Restore")
+	defer span.EndError(&err)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// get the vehicle
+	v, ok := r.db[id]
+	if !ok {
+		return v, internal.ErrVehicleNotFound
+	}
+	if v.DeletedAt == nil {
+		return v, internal.ErrNotDeleted
+	}
+
+	// check if the vehicle was updated since it was read
+	if v.Version != version {
+		return v, internal.ErrVehicleConflict
+	}
+
+	// unmark as deleted
+	v.DeletedAt = nil
+	v.Version++
+	r.db[id] = v
+	r.index.add(v)
+	r.touch()
+	r.record(v, false)
+	r.lg.DebugContext(ctx, "vehicle stored", "op", "restore", "vehicle_id", id, "version", v.Version, "revision", r.revision)
+
+	return
+}
+
+// Purge permanently removes the vehicles deleted before a time, with their history
+func (r *VehicleMap) Purge(ctx context.Context, deletedBefore time.Time) (v []internal.Vehicle, err error) {
+	ctx, span := tracing.Start(ctx, "repository.VehicleMap.Purge")
+	defer span.EndError(&err)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v = []internal.Vehicle{}
+	for id, value := range r.db {
+		if value.DeletedAt == nil || !value.DeletedAt.Before(deletedBefore) {
+			continue
+		}
+		delete(r.db, id)
+		delete(r.history, id)
+		v = append(v, value)
+	}
+
+	if len(v) > 0 {
+		r.touch()
+		r.lg.DebugContext(ctx, "vehicles purged", "count", len(v), "revision", r.revision)
+	}
+	return
+}
+
+// Revision returns the current revision of the vehicles
+func (r *VehicleMap) Revision(ctx context.Context) (rev internal.Revision, err error) {
+	ctx, span := tracing.Start(ctx, "repository.VehicleMap.Revision")
+	defer span.EndError(&err)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rev = internal.Revision{Number: r.revision, ModifiedAt: r.modifiedAt}
+	return
 }
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestVehicleMap_FindByVin(t *testing.T) {
+	ctx := context.Background()
+	// the VINs of a data file, as written by hand
+	db := map[int]internal.Vehicle{
+		1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Honda", Registration: "A1", Vin: "1hgcm82633a004352"}},
+		2: {Id: 2, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Registration: "A2", Vin: "1M8GDM9A-XKP-042788"}},
+		3: {Id: 3, VehicleAttributes: internal.VehicleAttributes{Brand: "Fiat", Registration: "A3"}},
+	}
+
+	tests := []struct {
+		name    string
+		vin     string
+		wantId  int
+		wantErr error
+	}{
+		{name: "loaded in lowercase", vin: "1HGCM82633A004352", wantId: 1},
+		{name: "loaded with dashes", vin: "1M8GDM9AXKP042788", wantId: 2},
+		{name: "query not normalized", vin: " 1m8gdm9axkp042788", wantId: 2},
+		{name: "unknown", vin: "11111111111111111", wantErr: internal.ErrVehicleNotFound},
+		{name: "empty, not the vehicles without vin", vin: "", wantErr: internal.ErrVehicleNotFound},
+	}
+
+	for _, load := range []string{"new", "reload"} {
+		rp := NewVehicleMap(nil, nil)
+		if load == "new" {
+			rp = NewVehicleMap(db, nil)
+		} else {
+			rp.Load(ctx, db)
+		}
+
+		for _, tt := range tests {
+			t.Run(load+" "+tt.name, func(t *testing.T) {
+				v, err := rp.FindByVin(ctx, tt.vin)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("FindByVin() error = %v, want %v", err, tt.wantErr)
+				}
+				if err == nil && v.Id != tt.wantId {
+					t.Errorf("FindByVin() = %d, want %d", v.Id, tt.wantId)
+				}
+			})
+		}
+
+		// - the loaded VIN is a duplicate of the same VIN written in another way, as it is found by it
+		if _, err := rp.Add(ctx, internal.Vehicle{VehicleAttributes: internal.VehicleAttributes{Registration: "A4", Vin: "1HGCM82633A004352"}}); !errors.Is(err, internal.ErrVinExistent) {
+			t.Errorf("%s: Add() of a loaded VIN error = %v, want ErrVinExistent", load, err)
+		}
+	}
+}
+
+func TestVehicleMap_Update_Delete_Previous(t *testing.T) {
+	ctx := context.Background()
+	rp := NewVehicleMap(map[int]internal.Vehicle{
+		1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Registration: "A1", Color: "Red"}},
+	}, nil)
+
+	// - the update returns the state it replaced
+	v, previous, err := rp.Update(ctx, internal.Vehicle{Id: 1, Version: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Registration: "A1", Color: "Blue"}})
+	if err != nil || previous.Color != "Red" || previous.Version != 1 || v.Color != "Blue" || v.Version != 2 {
+		t.Fatalf("Update() = %+v, %+v, %v, want Red at version 1 replaced by Blue at version 2", v, previous, err)
+	}
+
+	// - a write based on the replaced version does not replace anything
+	_, previous, err = rp.Update(ctx, internal.Vehicle{Id: 1, Version: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Registration: "A1", Color: "Green"}})
+	if !errors.Is(err, internal.ErrVehicleConflict) || previous.Id != 0 {
+		t.Errorf("Update() of version 1 = %+v, %v, want ErrVehicleConflict", previous, err)
+	}
+	if previous, err = rp.Delete(ctx, 1, 1); !errors.Is(err, internal.ErrVehicleConflict) || previous.Id != 0 {
+		t.Errorf("Delete() of version 1 = %+v, %v, want ErrVehicleConflict", previous, err)
+	}
+
+	// - the delete returns the state it replaced
+	previous, err = rp.Delete(ctx, 1, 2)
+	if err != nil || previous.Color != "Blue" || previous.Version != 2 || previous.DeletedAt != nil {
+		t.Errorf("Delete() = %+v, %v, want Blue at version 2", previous, err)
+	}
+}
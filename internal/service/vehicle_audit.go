@@ -0,0 +1,102 @@
+package service
+
+import (
+	"app/internal"
+	"app/internal/filter"
+	"app/internal/tracing"
+	"context"
+	"fmt"
+	"time"
+)
+
+// audit records a change of a vehicle made by the actor of ctx.
+// before is nil for a creation and after is nil for a deletion
+func (s *VehicleDefault) audit(ctx context.Context, action internal.AuditAction, before, after *internal.Vehicle) (err error) {
+	event := internal.AuditEvent{
+		Timestamp: time.Now().UTC(),
+		Actor:     internal.ActorFromContext(ctx),
+		Action:    action,
+		Changes:   diffVehicles(before, after),
+	}
+	if after != nil {
+		event.VehicleId = after.Id
+	} else {
+		event.VehicleId = before.Id
+	}
+	s.lg.InfoContext(ctx, "vehicle changed",
+		"action", event.Action,
+		"vehicle_id", event.VehicleId,
+		"actor", event.Actor,
+	)
+
+	// audit disabled
+	if s.au == nil {
+		return
+	}
+
+	if err = s.au.Record(event); err != nil {
+		s.lg.ErrorContext(ctx, "audit event not recorded", "vehicle_id", event.VehicleId, "error", err)
+		return fmt.Errorf("%w: %v", internal.ErrAuditFailed, err)
+	}
+	return
+}
+
+// diffVehicles returns the fields whose values differ between before and after, by field name.
+// A nil vehicle has no values, so every field of the other one is a change
+func diffVehicles(before, after *internal.Vehicle) (changes map[string]internal.AuditChange) {
+	changes = make(map[string]internal.AuditChange)
+	for _, name := range filter.FieldNames() {
+		if name == "id" {
+			continue
+		}
+		field, _ := filter.LookupField(name)
+
+		var change internal.AuditChange
+		if before != nil {
+			change.Before = fieldValue(field, *before)
+		}
+		if after != nil {
+			change.After = fieldValue(field, *after)
+		}
+		if change.Before != change.After {
+			changes[name] = change
+		}
+	}
+	return
+}
+
+// fieldValue returns the value of a field of a vehicle, as a number or a string depending on its kind
+func fieldValue(field filter.Field, v internal.Vehicle) any {
+	if field.Kind == filter.KindNumber {
+		return field.Number(v)
+	}
+	return field.Value(v)
+}
+
+// History returns the audit events of a vehicle, oldest first
+func (s *VehicleDefault) History(ctx context.Context, id int) (e []internal.AuditEvent, err error) {
+	ctx, span := tracing.Start(ctx, "service.VehicleDefault.History")
+	defer span.EndError(&err)
+
+	if s.au == nil {
+		return nil, internal.ErrAuditDisabled
+	}
+
+	// call the sink
+	e, err = s.au.FindByVehicle(id)
+	return
+}
+
+// AuditSince returns the audit events from a time on, oldest first
+func (s *VehicleDefault) AuditSince(ctx context.Context, since time.Time) (e []internal.AuditEvent, err error) {
+	ctx, span := tracing.Start(ctx, "service.VehicleDefault.AuditSince")
+	defer span.EndError(&err)
+
+	if s.au == nil {
+		return nil, internal.ErrAuditDisabled
+	}
+
+	// call the sink
+	e, err = s.au.FindSince(since)
+	return
+}
@@ -0,0 +1,87 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/audit"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestVehicleDefault_Audit(t *testing.T) {
+	au := audit.NewSinkMemory()
+	sv := service.NewVehicleDefault(repository.NewVehicleMap(nil, nil), au, nil, nil)
+	ctx := internal.WithActor(context.Background(), "alice")
+
+	attrs := internal.VehicleAttributes{
+		Brand: "Ford", Model: "Fiesta", Registration: "AA100AA", Color: "Red", FabricationYear: 2010, Capacity: 5,
+		FuelType: "gasoline", Transmission: "manual", Weight: 1100,
+		Dimensions: internal.Dimensions{Length: 4.0, Width: 1.7, Height: 1.5},
+	}
+	v, err := sv.Add(ctx, internal.Vehicle{VehicleAttributes: attrs})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// - an update of two fields, by another actor
+	attrs.Color, attrs.Weight = "Blue", 1150
+	v, err = sv.Update(internal.WithActor(context.Background(), "bob"), internal.Vehicle{Id: v.Id, Version: v.Version, VehicleAttributes: attrs})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// - a delete without actor
+	if err = sv.Delete(context.Background(), v.Id, v.Version); err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := sv.History(ctx, v.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e) != 3 {
+		t.Fatalf("History() = %d events, want 3", len(e))
+	}
+
+	// every field of the vehicle, with its value
+	values := map[string]any{
+		"brand": "Ford", "model": "Fiesta", "registration": "AA100AA", "country": "", "vin": "", "color": "Red",
+		"year": 2010.0, "passengers": 5.0, "max_speed": 0.0, "fuel_type": "gasoline", "transmission": "manual",
+		"weight": 1100.0, "height": 1.5, "length": 4.0, "width": 1.7,
+	}
+	created := make(map[string]internal.AuditChange)
+	deleted := make(map[string]internal.AuditChange)
+	for name, value := range values {
+		created[name] = internal.AuditChange{After: value}
+		deleted[name] = internal.AuditChange{Before: value}
+	}
+	deleted["color"] = internal.AuditChange{Before: "Blue"}
+	deleted["weight"] = internal.AuditChange{Before: 1150.0}
+
+	tests := []struct {
+		name    string
+		action  internal.AuditAction
+		actor   string
+		changes map[string]internal.AuditChange
+	}{
+		{name: "add, every field", action: internal.AuditActionCreate, actor: "alice", changes: created},
+		{name: "update, only the changed fields", action: internal.AuditActionUpdate, actor: "bob", changes: map[string]internal.AuditChange{
+			"color":  {Before: "Red", After: "Blue"},
+			"weight": {Before: 1100.0, After: 1150.0},
+		}},
+		{name: "delete, every field of the updated state", action: internal.AuditActionDelete, actor: "anonymous", changes: deleted},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if e[i].Action != tt.action || e[i].Actor != tt.actor || e[i].VehicleId != v.Id {
+				t.Errorf("event = %s by %s of %d, want %s by %s of %d", e[i].Action, e[i].Actor, e[i].VehicleId, tt.action, tt.actor, v.Id)
+			}
+			if !reflect.DeepEqual(e[i].Changes, tt.changes) {
+				t.Errorf("changes = %v, want %v", e[i].Changes, tt.changes)
+			}
+		})
+	}
+}
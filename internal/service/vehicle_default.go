@@ -1,89 +1,326 @@
 package service
 
-import "app/internal"
+import (
+	"app/internal"
+	"app/internal/logging"
+	"app/internal/tracing"
+	"app/internal/vin"
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+)
 
-// NewVehicleDefault is a function that returns a new instance of VehicleDefault
-func NewVehicleDefault(rp internal.VehicleRepository) *VehicleDefault {
-	return &VehicleDefault{rp: rp}
+// NewVehicleDefault is a function that returns a new instance of VehicleDefault.
+// au can be nil to disable the audit of the changes, rv can be nil to accept any registration,
+// lg can be nil to use the default logger
+func NewVehicleDefault(rp internal.VehicleRepository, au internal.AuditSink, rv internal.RegistrationValidator, lg *slog.Logger) *VehicleDefault {
+	return &VehicleDefault{rp: rp, au: au, rv: rv, lg: logging.OrDefault(lg)}
 }
 
 // VehicleDefault is a struct that represents the default service for vehicles
 type VehicleDefault struct {
 	// rp is the repository that will be used by the service
 	rp internal.VehicleRepository
+	// au is the sink of the audit events of the changes, nil if the audit is disabled
+	au internal.AuditSink
+	// rv validates the registrations by country, nil if any registration is valid
+	rv internal.RegistrationValidator
+	// lg is the logger of the changes of the vehicles
+	lg *slog.Logger
 }
 
 // FindAll is a method that returns a map of all vehicles
-func (s *VehicleDefault) FindAll() (v map[int]internal.Vehicle, err error) {
-	v, err = s.rp.FindAll()
+func (s *VehicleDefault) FindAll(ctx context.Context) (v map[int]internal.Vehicle, err error) {
+	ctx, span := tracing.Start(ctx, "service.VehicleDefault.FindAll")
+	defer span.EndError(&err)
+
+	v, err = s.rp.FindAll(ctx)
 	return
 }
 
-// Add is a method that adds a new vehicle
-func (s *VehicleDefault) Add(newVehicle internal.Vehicle) (v internal.Vehicle, err error) {
+// Add is a method that adds a new vehicle, on behalf of the actor of ctx
+func (s *VehicleDefault) Add(ctx context.Context, newVehicle internal.Vehicle) (v internal.Vehicle, err error) {
+	ctx, span := tracing.Start(ctx, "service.VehicleDefault.Add")
+	defer span.EndError(&err)
 
-	// check if the fabrication year is valid
-	if newVehicle.FabricationYear < 1886 {
-		return v, &internal.ErrInvalidAttributes{Attr: "FabricationYear"}
+	// check if the attributes are valid
+	newVehicle.VehicleAttributes = normalize(newVehicle.VehicleAttributes)
+	if err = s.validate(newVehicle.VehicleAttributes); err != nil {
+		return
 	}
 
-	// some others validations
-	// ...
-
 	// add the vehicle
-	v, err = s.rp.Add(newVehicle)
+	v, err = s.rp.Add(ctx, newVehicle)
 	if err != nil {
 		return internal.Vehicle{}, err
 	}
 
+	// audit the change
+	err = s.audit(ctx, internal.AuditActionCreate, nil, &v)
 	return
 }
 
 // FindAllEqualTo returns a map of vehicles that passed the filters
-func (s *VehicleDefault) FindAllEqualTo(filter internal.EqualFilter) (v map[int]internal.Vehicle, err error) {
+func (s *VehicleDefault) FindAllEqualTo(ctx context.Context, filter internal.EqualFilter) (v map[int]internal.Vehicle, err error) {
+	ctx, span := tracing.Start(ctx, "service.VehicleDefault.FindAllEqualTo")
+	defer span.EndError(&err)
+
+	// check if the ranges are valid
+	if !filter.Valid() {
+		return nil, internal.ErrInvalidRange
+	}
+
 	// call the repo
-	v, err = s.rp.FindAllEqualTo(filter)
+	span.SetAttributes(tracing.String("filter.fields", strings.Join(filterFields(filter), ",")))
+	v, err = s.rp.FindAllEqualTo(ctx, filter)
+	span.SetAttributes(tracing.Int("vehicle.count", len(v)))
 	return
 }
 
-// Update updates an existent vehicle
-func (s *VehicleDefault) Update(vehicle internal.Vehicle) (v internal.Vehicle, err error) {
-	// check if the fabrication year is valid
-	if vehicle.FabricationYear < 1886 {
-		return v, &internal.ErrInvalidAttributes{Attr: "FabricationYear"}
+// filterFields returns the names of the fields a filter is set on, for the traces
+func filterFields(filter internal.EqualFilter) (fields []string) {
+	set := map[string]bool{
+		"brand":        filter.Brand != "",
+		"model":        filter.Model != "",
+		"color":        filter.Color != "",
+		"year":         filter.FabricationYear != nil || filter.FabricationYearRange.IsSet(),
+		"passengers":   filter.Capacity != nil,
+		"fuel_type":    filter.FuelType != "",
+		"transmission": filter.Transmission != "",
+		"length":       filter.LengthRange.IsSet(),
+		"width":        filter.WidthRange.IsSet(),
+		"weight":       filter.WeightRange.IsSet(),
+	}
+	for _, name := range []string{"brand", "model", "color", "year", "passengers", "fuel_type", "transmission", "length", "width", "weight"} {
+		if set[name] {
+			fields = append(fields, name)
+		}
 	}
+	return
+}
 
-	// some others validations
-	// ...
+// Update updates an existent vehicle, if vehicle.Version is the current version of the vehicle,
+// on behalf of the actor of ctx
+func (s *VehicleDefault) Update(ctx context.Context, vehicle internal.Vehicle) (v internal.Vehicle, err error) {
+	ctx, span := tracing.Start(ctx, "service.VehicleDefault.Update")
+	defer span.EndError(&err)
 
-	// call the repo
-	v, err = s.rp.Update(vehicle)
+	// check if the attributes are valid
+	vehicle.VehicleAttributes = normalize(vehicle.VehicleAttributes)
+	if err = s.validate(vehicle.VehicleAttributes); err != nil {
+		return
+	}
+
+	// call the repo, the state before is the one replaced under the lock of the write
+	v, before, err := s.rp.Update(ctx, vehicle)
 	if err != nil {
 		return v, err
 	}
 
+	// audit the change
+	err = s.audit(ctx, internal.AuditActionUpdate, &before, &v)
 	return
 }
 
-func (s *VehicleDefault) GetAvgCapacity(brand string) (avg float64, err error) {
-	// call the repo
-	brandVehicles, err := s.rp.FindAllEqualTo(internal.EqualFilter{
-		Brand: brand,
+// GetAvgCapacity returns the avg of the brands capacity
+func (s *VehicleDefault) GetAvgCapacity(ctx context.Context, brand string) (avg float64, err error) {
+	ctx, span := tracing.Start(ctx, "service.VehicleDefault.GetAvgCapacity")
+	defer span.EndError(&err)
+
+	// summarize the capacity of the brand
+	stats, err := s.GetStats(ctx, internal.VehicleStatsQuery{
+		Field: "passengers",
+		Filter: internal.EqualFilter{
+			Brand: brand,
+		},
 	})
 	if err != nil {
 		return 0, err
 	}
 
-	// if there are not vehicles, return error
-	if len(brandVehicles) == 0 {
-		return 0, internal.ErrVehiclesNotFound
+	avg = stats[""].Mean
+	return
+}
+
+// FindAllMatching returns a map of vehicles that satisfy the matcher
+func (s *VehicleDefault) FindAllMatching(ctx context.Context, m internal.VehicleMatcher) (v map[int]internal.Vehicle, err error) {
+	ctx, span := tracing.Start(ctx, "service.VehicleDefault.FindAllMatching")
+	defer span.EndError(&err)
+
+	// call the repo
+	v, err = s.rp.FindAllMatching(ctx, m)
+	span.SetAttributes(tracing.Int("vehicle.count", len(v)))
+	return
+}
+
+// Search returns up to limit vehicles that match the free text query, sorted by relevance
+func (s *VehicleDefault) Search(ctx context.Context, query string, limit int) (results []internal.VehicleSearchResult, err error) {
+	ctx, span := tracing.Start(ctx, "service.VehicleDefault.Search")
+	defer span.EndError(&err)
+
+	// check if the query has any text
+	if strings.TrimSpace(query) == "" {
+		return nil, internal.ErrEmptySearch
+	}
+
+	// call the repo
+	span.SetAttributes(tracing.String("search.query", query), tracing.Int("search.limit", limit))
+	results, err = s.rp.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return
+}
+
+// FindById returns the vehicle with the given id, a deleted one only if includeDeleted is true
+func (s *VehicleDefault) FindById(ctx context.Context, id int, includeDeleted bool) (v internal.Vehicle, err error) {
+	ctx, span := tracing.Start(ctx, "service.VehicleDefault.FindById")
+	defer span.EndError(&err)
+
+	// call the repo
+	v, err = s.rp.FindById(ctx, id)
+	if err != nil {
+		return
+	}
+
+	if v.DeletedAt != nil && !includeDeleted {
+		return internal.Vehicle{}, internal.ErrVehicleNotFound
+	}
+	return
+}
+
+// FindByVin returns the vehicle with the given VIN, a deleted one only if includeDeleted is true
+func (s *VehicleDefault) FindByVin(ctx context.Context, number string, includeDeleted bool) (v internal.Vehicle, err error) {
+	ctx, span := tracing.Start(ctx, "service.VehicleDefault.FindByVin")
+	defer span.EndError(&err)
+
+	// call the repo
+	v, err = s.rp.FindByVin(ctx, vin.Normalize(number))
+	if err != nil {
+		return
+	}
+
+	if v.DeletedAt != nil && !includeDeleted {
+		return internal.Vehicle{}, internal.ErrVehicleNotFound
+	}
+	return
+}
+
+// Delete soft deletes an existent vehicle, if version is its current version, on behalf of the actor of ctx
+func (s *VehicleDefault) Delete(ctx context.Context, id int, version int) (err error) {
+	ctx, span := tracing.Start(ctx, "service.VehicleDefault.Delete")
+	defer span.EndError(&err)
+
+	// call the repo, the state before is the one replaced under the lock of the write
+	before, err := s.rp.Delete(ctx, id, version)
+	if err != nil {
+		return
+	}
+
+	// audit the change
+	err = s.audit(ctx, internal.AuditActionDelete, &before, nil)
+	return
+}
+
+// Revision returns the current revision of the vehicles
+func (s *VehicleDefault) Revision(ctx context.Context) (rev internal.Revision, err error) {
+	ctx, span := tracing.Start(ctx, "service.VehicleDefault.Revision")
+	defer span.EndError(&err)
+
+	// call the repo
+	rev, err = s.rp.Revision(ctx)
+	return
+}
+
+// FindByIdAsOf returns the state of the vehicle with the given id at a point in time
+func (s *VehicleDefault) FindByIdAsOf(ctx context.Context, id int, asOf time.Time) (v internal.Vehicle, err error) {
+	ctx, span := tracing.Start(ctx, "service.VehicleDefault.FindByIdAsOf")
+	defer span.EndError(&err)
+
+	// call the repo
+	v, err = s.rp.FindByIdAsOf(ctx, id, asOf)
+	return
+}
+
+// Revert restores the attributes of a previous version of a vehicle, as a new version.
+// The restored attributes go through the same validations and audit as an update.
+// If expectedVersion is not 0 it must be the current version of the vehicle
+func (s *VehicleDefault) Revert(ctx context.Context, id int, version int, expectedVersion int) (v internal.Vehicle, err error) {
+	ctx, span := tracing.Start(ctx, "service.VehicleDefault.Revert")
+	defer span.EndError(&err)
+
+	// get the version to restore
+	previous, err := s.rp.FindVersion(ctx, id, version)
+	if err != nil {
+		return
+	}
+
+	// get the current version
+	current, err := s.rp.FindById(ctx, id)
+	if err != nil {
+		return
+	}
+	if expectedVersion != 0 && expectedVersion != current.Version {
+		return v, internal.ErrVehicleConflict
+	}
+
+	// update the vehicle with the previous attributes
+	previous.Version = current.Version
+	v, err = s.Update(ctx, previous)
+	return
+}
+
+// Restore restores a deleted vehicle, on behalf of the actor of ctx.
+// If expectedVersion is not 0 it must be the current version of the vehicle
+func (s *VehicleDefault) Restore(ctx context.Context, id int, expectedVersion int) (v internal.Vehicle, err error) {
+	ctx, span := tracing.Start(ctx, "service.VehicleDefault.Restore")
+	defer span.EndError(&err)
+
+	// get the current version
+	current, err := s.rp.FindById(ctx, id)
+	if err != nil {
+		return
+	}
+	if expectedVersion != 0 && expectedVersion != current.Version {
+		return v, internal.ErrVehicleConflict
+	}
+
+	// call the repo
+	v, err = s.rp.Restore(ctx, id, current.Version)
+	if err != nil {
+		return
+	}
+
+	// audit the change
+	err = s.audit(ctx, internal.AuditActionRestore, &current, &v)
+	return
+}
+
+// PurgeDeleted permanently removes the vehicles deleted longer than retention ago, on behalf of the actor of ctx
+func (s *VehicleDefault) PurgeDeleted(ctx context.Context, retention time.Duration) (purged int, err error) {
+	ctx, span := tracing.Start(ctx, "service.VehicleDefault.PurgeDeleted")
+	defer span.EndError(&err)
+
+	// call the repo
+	span.SetAttributes(tracing.Float64("retention.hours", retention.Hours()))
+	vehicles, err := s.rp.Purge(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return
 	}
 
-	totalCapacity := 0.0
-	for _, value := range brandVehicles {
-		totalCapacity += float64(value.Capacity)
+	// audit the changes
+	for _, v := range vehicles {
+		if err = s.audit(ctx, internal.AuditActionPurge, &v, nil); err != nil {
+			return
+		}
 	}
 
-	avg = totalCapacity / float64(len(brandVehicles))
+	purged = len(vehicles)
+	span.SetAttributes(tracing.Int("vehicle.count", purged))
 	return
 }
@@ -0,0 +1,136 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// newFleet returns a service over a repository with a small fleet, by id
+func newFleet() *service.VehicleDefault {
+	db := map[int]internal.Vehicle{
+		1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{
+			Brand: "Ford", Model: "Fiesta", Registration: "AA100AA", Color: "Red", FabricationYear: 2010, Capacity: 5,
+			FuelType: "gasoline", Transmission: "manual", Weight: 1100,
+			Dimensions: internal.Dimensions{Length: 4.0, Width: 1.7, Height: 1.5},
+		}},
+		2: {Id: 2, VehicleAttributes: internal.VehicleAttributes{
+			Brand: "Citroën", Model: "C3", Registration: "AA200AA", Color: "Blue", FabricationYear: 2015, Capacity: 5,
+			FuelType: "diesel", Transmission: "automatic", Weight: 1200,
+			Dimensions: internal.Dimensions{Length: 3.9, Width: 1.75, Height: 1.5},
+		}},
+		// - a vehicle with the unknown magnitudes at zero
+		3: {Id: 3, VehicleAttributes: internal.VehicleAttributes{
+			Brand: "Ford", Model: "Ranger", Registration: "AA300AA", Color: "Black", FabricationYear: 2020, Capacity: 0,
+			FuelType: "diesel", Transmission: "manual", Weight: 0,
+			Dimensions: internal.Dimensions{Length: 5.3, Width: 1.85, Height: 1.8},
+		}},
+	}
+	return service.NewVehicleDefault(repository.NewVehicleMap(db, nil), nil, nil, nil)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestVehicleDefault_FindAllEqualTo(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  internal.EqualFilter
+		ids     []int
+		wantErr error
+	}{
+		// - no filter
+		{name: "empty filter", filter: internal.EqualFilter{}, ids: []int{1, 2, 3}},
+
+		// - text fields, exact and ignoring case and accents
+		{name: "brand exact", filter: internal.EqualFilter{Brand: "Ford"}, ids: []int{1, 3}},
+		{name: "brand case-insensitive", filter: internal.EqualFilter{Brand: "fORD"}, ids: []int{1, 3}},
+		{name: "brand without accents", filter: internal.EqualFilter{Brand: "citroen"}, ids: []int{2}},
+		{name: "brand no match", filter: internal.EqualFilter{Brand: "Fiat"}, ids: []int{}},
+		{name: "brand is not a prefix match", filter: internal.EqualFilter{Brand: "For"}, ids: []int{}},
+		{name: "model exact", filter: internal.EqualFilter{Model: "C3"}, ids: []int{2}},
+		{name: "model case-insensitive", filter: internal.EqualFilter{Model: "FIESTA"}, ids: []int{1}},
+		{name: "color exact", filter: internal.EqualFilter{Color: "Black"}, ids: []int{3}},
+		{name: "color case-insensitive", filter: internal.EqualFilter{Color: "red"}, ids: []int{1}},
+		{name: "fuel type exact", filter: internal.EqualFilter{FuelType: "diesel"}, ids: []int{2, 3}},
+		{name: "fuel type case-insensitive", filter: internal.EqualFilter{FuelType: "Diesel"}, ids: []int{2, 3}},
+		{name: "transmission exact", filter: internal.EqualFilter{Transmission: "automatic"}, ids: []int{2}},
+		{name: "transmission case-insensitive", filter: internal.EqualFilter{Transmission: "MANUAL"}, ids: []int{1, 3}},
+
+		// - numeric fields, exact and zero-valued
+		{name: "year exact", filter: internal.EqualFilter{FabricationYear: ptr(2015)}, ids: []int{2}},
+		{name: "year no match", filter: internal.EqualFilter{FabricationYear: ptr(1999)}, ids: []int{}},
+		{name: "passengers exact", filter: internal.EqualFilter{Capacity: ptr(5)}, ids: []int{1, 2}},
+		{name: "passengers zero", filter: internal.EqualFilter{Capacity: ptr(0)}, ids: []int{3}},
+
+		// - year range
+		{name: "year range inclusive", filter: internal.EqualFilter{FabricationYearRange: internal.Range[int]{Min: ptr(2010), Max: ptr(2015)}}, ids: []int{1, 2}},
+		{name: "year range single value", filter: internal.EqualFilter{FabricationYearRange: internal.Range[int]{Min: ptr(2020), Max: ptr(2020)}}, ids: []int{3}},
+		{name: "year range open max", filter: internal.EqualFilter{FabricationYearRange: internal.Range[int]{Min: ptr(2015)}}, ids: []int{2, 3}},
+		{name: "year range open min", filter: internal.EqualFilter{FabricationYearRange: internal.Range[int]{Max: ptr(2010)}}, ids: []int{1}},
+		{name: "year range min greater than max", filter: internal.EqualFilter{FabricationYearRange: internal.Range[int]{Min: ptr(2016), Max: ptr(2015)}}, wantErr: internal.ErrInvalidRange},
+
+		// - length range
+		{name: "length range inclusive", filter: internal.EqualFilter{LengthRange: internal.Range[float64]{Min: ptr(3.9), Max: ptr(4.0)}}, ids: []int{1, 2}},
+		{name: "length range open max", filter: internal.EqualFilter{LengthRange: internal.Range[float64]{Min: ptr(5.0)}}, ids: []int{3}},
+		{name: "length range open min", filter: internal.EqualFilter{LengthRange: internal.Range[float64]{Max: ptr(3.95)}}, ids: []int{2}},
+		{name: "length range min greater than max", filter: internal.EqualFilter{LengthRange: internal.Range[float64]{Min: ptr(5.0), Max: ptr(4.0)}}, wantErr: internal.ErrInvalidRange},
+
+		// - width range
+		{name: "width range inclusive", filter: internal.EqualFilter{WidthRange: internal.Range[float64]{Min: ptr(1.7), Max: ptr(1.75)}}, ids: []int{1, 2}},
+		{name: "width range open max", filter: internal.EqualFilter{WidthRange: internal.Range[float64]{Min: ptr(1.8)}}, ids: []int{3}},
+		{name: "width range open min", filter: internal.EqualFilter{WidthRange: internal.Range[float64]{Max: ptr(1.7)}}, ids: []int{1}},
+		{name: "width range min greater than max", filter: internal.EqualFilter{WidthRange: internal.Range[float64]{Min: ptr(1.8), Max: ptr(1.7)}}, wantErr: internal.ErrInvalidRange},
+
+		// - weight range, with the zero values
+		{name: "weight range inclusive", filter: internal.EqualFilter{WeightRange: internal.Range[float64]{Min: ptr(1100.0), Max: ptr(1200.0)}}, ids: []int{1, 2}},
+		{name: "weight range open max", filter: internal.EqualFilter{WeightRange: internal.Range[float64]{Min: ptr(1150.0)}}, ids: []int{2}},
+		{name: "weight range open min", filter: internal.EqualFilter{WeightRange: internal.Range[float64]{Max: ptr(1100.0)}}, ids: []int{1, 3}},
+		{name: "weight range zero max", filter: internal.EqualFilter{WeightRange: internal.Range[float64]{Max: ptr(0.0)}}, ids: []int{3}},
+		{name: "weight range zero min", filter: internal.EqualFilter{WeightRange: internal.Range[float64]{Min: ptr(0.0)}}, ids: []int{1, 2, 3}},
+		{name: "weight range min greater than max", filter: internal.EqualFilter{WeightRange: internal.Range[float64]{Min: ptr(1.0), Max: ptr(0.0)}}, wantErr: internal.ErrInvalidRange},
+
+		// - combinations
+		{name: "brand and year range", filter: internal.EqualFilter{Brand: "ford", FabricationYearRange: internal.Range[int]{Min: ptr(2015)}}, ids: []int{3}},
+		{name: "fuel type and weight range", filter: internal.EqualFilter{FuelType: "diesel", WeightRange: internal.Range[float64]{Min: ptr(1.0)}}, ids: []int{2}},
+		{name: "passengers and transmission", filter: internal.EqualFilter{Capacity: ptr(5), Transmission: "manual"}, ids: []int{1}},
+		{name: "all the ranges", filter: internal.EqualFilter{
+			FabricationYearRange: internal.Range[int]{Min: ptr(2000), Max: ptr(2030)},
+			LengthRange:          internal.Range[float64]{Min: ptr(3.0), Max: ptr(6.0)},
+			WidthRange:           internal.Range[float64]{Min: ptr(1.0), Max: ptr(2.0)},
+			WeightRange:          internal.Range[float64]{Min: ptr(1000.0), Max: ptr(2000.0)},
+		}, ids: []int{1, 2}},
+		{name: "a valid range and an invalid one", filter: internal.EqualFilter{
+			FabricationYearRange: internal.Range[int]{Min: ptr(2000)},
+			WidthRange:           internal.Range[float64]{Min: ptr(2.0), Max: ptr(1.0)},
+		}, wantErr: internal.ErrInvalidRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sv := newFleet()
+
+			v, err := sv.FindAllEqualTo(context.Background(), tt.filter)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FindAllEqualTo() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			ids := []int{}
+			for id := range v {
+				ids = append(ids, id)
+			}
+			slices.Sort(ids)
+			if !slices.Equal(ids, tt.ids) {
+				t.Errorf("FindAllEqualTo() ids = %v, want %v", ids, tt.ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"app/internal"
+	"app/internal/tracing"
+	"context"
+	"sort"
+	"strconv"
+)
+
+// facetFields are the fields that can be counted, with the function that returns the value of a vehicle
+var facetFields = map[string]func(v internal.Vehicle) string{
+	"brand":        func(v internal.Vehicle) string { return v.Brand },
+	"color":        func(v internal.Vehicle) string { return v.Color },
+	"fuel_type":    func(v internal.Vehicle) string { return v.FuelType },
+	"transmission": func(v internal.Vehicle) string { return v.Transmission },
+	"decade":       func(v internal.Vehicle) string { return strconv.Itoa(v.FabricationYear/10*10) + "s" },
+}
+
+// GetFacets returns the number of vehicles per value of each field, for the vehicles that pass the filter.
+// The values of each field are sorted by count, and alphabetically on ties
+func (s *VehicleDefault) GetFacets(ctx context.Context, fields []string, filter internal.EqualFilter) (facets map[string][]internal.VehicleFacet, err error) {
+	ctx, span := tracing.Start(ctx, "service.VehicleDefault.GetFacets")
+	defer span.EndError(&err)
+
+	// validate the fields
+	if len(fields) == 0 {
+		return nil, &internal.ErrInvalidAttributes{Attr: "fields"}
+	}
+	for _, field := range fields {
+		if _, ok := facetFields[field]; !ok {
+			return nil, &internal.ErrInvalidAttributes{Attr: field}
+		}
+	}
+
+	// call the service, to validate the filter
+	vehicles, err := s.FindAllEqualTo(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// count the values of each field
+	facets = make(map[string][]internal.VehicleFacet)
+	for _, field := range fields {
+		value := facetFields[field]
+		counts := make(map[string]int)
+		for _, v := range vehicles {
+			counts[value(v)]++
+		}
+
+		list := make([]internal.VehicleFacet, 0, len(counts))
+		for v, count := range counts {
+			list = append(list, internal.VehicleFacet{Value: v, Count: count})
+		}
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Count != list[j].Count {
+				return list[i].Count > list[j].Count
+			}
+			return list[i].Value < list[j].Value
+		})
+		facets[field] = list
+	}
+
+	return
+}
@@ -0,0 +1,70 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestVehicleDefault_GetFacets(t *testing.T) {
+	db := map[int]internal.Vehicle{
+		1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Registration: "A1", Color: "Red", FabricationYear: 1999, FuelType: "gasoline"}},
+		2: {Id: 2, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Registration: "A2", Color: "Blue", FabricationYear: 2000, FuelType: "diesel"}},
+		3: {Id: 3, VehicleAttributes: internal.VehicleAttributes{Brand: "Fiat", Registration: "A3", Color: "Red", FabricationYear: 2009, FuelType: "gasoline"}},
+		4: {Id: 4, VehicleAttributes: internal.VehicleAttributes{Brand: "Audi", Registration: "A4", Color: "Black", FabricationYear: 2010, FuelType: "diesel"}},
+		5: {Id: 5, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Registration: "A5", Color: "Black", FabricationYear: 2015, FuelType: "diesel"}},
+	}
+	sv := service.NewVehicleDefault(repository.NewVehicleMap(db, nil), nil, nil, nil)
+
+	tests := []struct {
+		name     string
+		fields   []string
+		filter   internal.EqualFilter
+		want     map[string][]internal.VehicleFacet
+		wantAttr string
+		wantErr  error
+	}{
+		{name: "by count, then by value", fields: []string{"brand", "color"}, want: map[string][]internal.VehicleFacet{
+			"brand": {{Value: "Ford", Count: 3}, {Value: "Audi", Count: 1}, {Value: "Fiat", Count: 1}},
+			"color": {{Value: "Black", Count: 2}, {Value: "Red", Count: 2}, {Value: "Blue", Count: 1}},
+		}},
+		{name: "decades", fields: []string{"decade"}, want: map[string][]internal.VehicleFacet{
+			"decade": {{Value: "2000s", Count: 2}, {Value: "2010s", Count: 2}, {Value: "1990s", Count: 1}},
+		}},
+		{name: "filter narrows the counts", fields: []string{"brand", "fuel_type"}, filter: internal.EqualFilter{FuelType: "diesel"}, want: map[string][]internal.VehicleFacet{
+			"brand":     {{Value: "Ford", Count: 2}, {Value: "Audi", Count: 1}},
+			"fuel_type": {{Value: "diesel", Count: 3}},
+		}},
+		{name: "filter without vehicles", fields: []string{"color"}, filter: internal.EqualFilter{Brand: "GMC"}, want: map[string][]internal.VehicleFacet{
+			"color": {},
+		}},
+		{name: "no fields", fields: nil, wantAttr: "fields"},
+		{name: "field that can not be counted", fields: []string{"brand", "model"}, wantAttr: "model"},
+		{name: "invalid filter", fields: []string{"brand"}, filter: internal.EqualFilter{FabricationYearRange: internal.Range[int]{Min: ptr(2010), Max: ptr(2000)}}, wantErr: internal.ErrInvalidRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facets, err := sv.GetFacets(context.Background(), tt.fields, tt.filter)
+			var errInv *internal.ErrInvalidAttributes
+			switch {
+			case tt.wantAttr != "":
+				if !errors.As(err, &errInv) || errInv.Attr != tt.wantAttr {
+					t.Errorf("GetFacets() error = %v, want an invalid %s", err, tt.wantAttr)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("GetFacets() error = %v, want %v", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Errorf("GetFacets() error = %v", err)
+			case !reflect.DeepEqual(facets, tt.want):
+				t.Errorf("GetFacets() = %v, want %v", facets, tt.want)
+			}
+		})
+	}
+}
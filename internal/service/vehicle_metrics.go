@@ -0,0 +1,93 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NewVehicleMetrics is a function that returns a service that counts the writes of sv rejected, by reason,
+// in a counter registered in reg
+func NewVehicleMetrics(sv internal.VehicleService, reg prometheus.Registerer) *VehicleMetrics {
+	s := &VehicleMetrics{
+		VehicleService: sv,
+		rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "vehicle_writes_rejected_total",
+			Help: "Number of writes of vehicles rejected, by operation and reason.",
+		}, []string{"operation", "reason"}),
+	}
+	reg.MustRegister(s.rejected)
+	return s
+}
+
+// VehicleMetrics is a struct that decorates a service with the metrics of the writes, the reads are not measured
+type VehicleMetrics struct {
+	internal.VehicleService
+	// rejected counts the writes rejected by operation and reason
+	rejected *prometheus.CounterVec
+}
+
+// rejectReason returns the reason of an error of a write, empty if the write was not rejected.
+// A failed audit is not a rejection, the change was made
+func rejectReason(err error) string {
+	var errInv *internal.ErrInvalidAttributes
+	switch {
+	case err == nil, errors.Is(err, internal.ErrAuditFailed):
+		return ""
+	case errors.Is(err, internal.ErrVehicleExistent):
+		return "existent"
+	case errors.As(err, &errInv):
+		return "invalid_attributes"
+	case errors.Is(err, internal.ErrVehicleConflict):
+		return "conflict"
+	case errors.Is(err, internal.ErrVehicleNotFound), errors.Is(err, internal.ErrVersionNotFound):
+		return "not_found"
+	case errors.Is(err, internal.ErrNotDeleted):
+		return "not_deleted"
+	}
+	return "error"
+}
+
+// count counts the write if it was rejected
+func (s *VehicleMetrics) count(operation string, err error) {
+	if reason := rejectReason(err); reason != "" {
+		s.rejected.WithLabelValues(operation, reason).Inc()
+	}
+}
+
+// Add adds a new vehicle, counting the rejections
+func (s *VehicleMetrics) Add(ctx context.Context, newVehicle internal.Vehicle) (v internal.Vehicle, err error) {
+	v, err = s.VehicleService.Add(ctx, newVehicle)
+	s.count("add", err)
+	return
+}
+
+// Update updates an existent vehicle, counting the rejections
+func (s *VehicleMetrics) Update(ctx context.Context, vehicle internal.Vehicle) (v internal.Vehicle, err error) {
+	v, err = s.VehicleService.Update(ctx, vehicle)
+	s.count("update", err)
+	return
+}
+
+// Delete soft deletes an existent vehicle, counting the rejections
+func (s *VehicleMetrics) Delete(ctx context.Context, id int, version int) (err error) {
+	err = s.VehicleService.Delete(ctx, id, version)
+	s.count("delete", err)
+	return
+}
+
+// Revert restores the attributes of a previous version of a vehicle, counting the rejections
+func (s *VehicleMetrics) Revert(ctx context.Context, id int, version int, expectedVersion int) (v internal.Vehicle, err error) {
+	v, err = s.VehicleService.Revert(ctx, id, version, expectedVersion)
+	s.count("revert", err)
+	return
+}
+
+// Restore restores a deleted vehicle, counting the rejections
+func (s *VehicleMetrics) Restore(ctx context.Context, id int, expectedVersion int) (v internal.Vehicle, err error) {
+	v, err = s.VehicleService.Restore(ctx, id, expectedVersion)
+	s.count("restore", err)
+	return
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"app/internal"
+	"app/internal/filter"
+	"app/internal/tracing"
+	"context"
+	"math"
+	"sort"
+)
+
+// statsFields are the numeric fields that can be summarized
+var statsFields = map[string]bool{
+	"year":       true,
+	"passengers": true,
+	"max_speed":  true,
+	"weight":     true,
+	"height":     true,
+	"length":     true,
+	"width":      true,
+}
+
+// statsGroups are the fields that the statistics can be grouped by
+var statsGroups = map[string]bool{
+	"brand":        true,
+	"model":        true,
+	"fuel_type":    true,
+	"transmission": true,
+}
+
+// GetStats returns the count, min, max, mean, median and percentiles of a numeric field
+// of the vehicles that pass the filter, optionally grouped by a text field
+func (s *VehicleDefault) GetStats(ctx context.Context, query internal.VehicleStatsQuery) (stats map[string]internal.VehicleStats, err error) {
+	ctx, span := tracing.Start(ctx, "service.VehicleDefault.GetStats")
+	defer span.EndError(&err)
+
+	// validate the query
+	field, ok := filter.LookupField(query.Field)
+	if !ok || !statsFields[query.Field] {
+		return nil, &internal.ErrInvalidAttributes{Attr: query.Field}
+	}
+	var group filter.Field
+	if query.GroupBy != "" {
+		group, ok = filter.LookupField(query.GroupBy)
+		if !ok || !statsGroups[query.GroupBy] {
+			return nil, &internal.ErrInvalidAttributes{Attr: query.GroupBy}
+		}
+	}
+	for _, p := range query.Percentiles {
+		// - the negated check also rejects NaN, that fails every comparison
+		if !(p >= 0 && p <= 100) {
+			return nil, &internal.ErrInvalidAttributes{Attr: "percentiles"}
+		}
+	}
+
+	// call the service, to validate the filter
+	vehicles, err := s.FindAllEqualTo(ctx, query.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// if there are not vehicles, return error
+	if len(vehicles) == 0 {
+		return nil, internal.ErrVehiclesNotFound
+	}
+
+	// collect the values by group
+	values := make(map[string][]float64)
+	for _, v := range vehicles {
+		key := ""
+		if query.GroupBy != "" {
+			key = group.Value(v)
+		}
+		values[key] = append(values[key], field.Number(v))
+	}
+
+	// summarize each group
+	stats = make(map[string]internal.VehicleStats)
+	for key, groupValues := range values {
+		stats[key] = summarize(groupValues, query.Percentiles)
+	}
+	return
+}
+
+// summarize computes the statistics of a non-empty list of values
+func summarize(values []float64, percentiles []float64) (st internal.VehicleStats) {
+	sort.Float64s(values)
+
+	total := 0.0
+	for _, value := range values {
+		total += value
+	}
+
+	st.Count = len(values)
+	st.Min = values[0]
+	st.Max = values[len(values)-1]
+	st.Mean = total / float64(len(values))
+	st.Median = percentile(values, 50)
+	st.Percentiles = make(map[float64]float64)
+	for _, p := range percentiles {
+		st.Percentiles[p] = percentile(values, p)
+	}
+	return
+}
+
+// percentile returns the p-th percentile of sorted values, interpolating linearly between the closest ranks
+func percentile(sorted []float64, p float64) float64 {
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
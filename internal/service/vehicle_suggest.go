@@ -0,0 +1,120 @@
+package service
+
+import (
+	"app/internal"
+	"app/internal/filter"
+	"app/internal/tracing"
+	"app/internal/utilities"
+	"context"
+	"sort"
+	"strings"
+)
+
+// suggestFields are the fields that can be autocompleted
+var suggestFields = map[string]bool{
+	"brand":        true,
+	"model":        true,
+	"color":        true,
+	"fuel_type":    true,
+	"transmission": true,
+}
+
+// minFuzzyPrefix is the minimum length of a prefix to suggest values that approximately start with it,
+// shorter prefixes would match almost everything
+const minFuzzyPrefix = 3
+
+// suggestion is a candidate value for an autocompletion
+type suggestion struct {
+	// value is the most frequent spelling of the value
+	value string
+	// count is the number of vehicles with the value
+	count int
+	// spellings is the number of vehicles per spelling of the value
+	spellings map[string]int
+	// distance is the edit distance between the prefix and the value, 0 for exact prefixes
+	distance int
+}
+
+// Suggest returns up to limit distinct values of a text field that start with prefix, ignoring case and accents.
+// Values that start with a misspelled prefix are suggested after the exact ones.
+// Values are ordered by the number of vehicles that have them
+func (s *VehicleDefault) Suggest(ctx context.Context, field string, prefix string, limit int) (suggestions []string, err error) {
+	ctx, span := tracing.Start(ctx, "service.VehicleDefault.Suggest")
+	defer span.EndError(&err)
+
+	// check if the field can be autocompleted
+	f, ok := filter.LookupField(field)
+	if !ok || !suggestFields[field] {
+		return nil, &internal.ErrInvalidAttributes{Attr: field}
+	}
+
+	// call the repo
+	vehicles, err := s.rp.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// group the values by their normalized spelling
+	prefix = utilities.Normalize(prefix)
+	maxDistance := utilities.MaxFuzzyDistance(prefix)
+	candidates := make(map[string]*suggestion)
+	for _, v := range vehicles {
+		value := f.Value(v)
+		normalized := utilities.Normalize(value)
+		if normalized == "" {
+			continue
+		}
+
+		c, ok := candidates[normalized]
+		if !ok {
+			// compare against the start of the value, with the length of the prefix
+			head := []rune(normalized)
+			if len(head) > len([]rune(prefix)) {
+				head = head[:len([]rune(prefix))]
+			}
+			distance := 0
+			if !strings.HasPrefix(normalized, prefix) {
+				distance = utilities.Levenshtein(prefix, string(head))
+				if len([]rune(prefix)) < minFuzzyPrefix || distance > maxDistance {
+					continue
+				}
+			}
+
+			c = &suggestion{spellings: make(map[string]int), distance: distance}
+			candidates[normalized] = c
+		}
+		c.count++
+		c.spellings[value]++
+	}
+
+	// pick the most frequent spelling of each value
+	list := make([]*suggestion, 0, len(candidates))
+	for _, c := range candidates {
+		for spelling, count := range c.spellings {
+			if count > c.spellings[c.value] || (count == c.spellings[c.value] && spelling < c.value) {
+				c.value = spelling
+			}
+		}
+		list = append(list, c)
+	}
+
+	// sort by distance, then by count
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].distance != list[j].distance {
+			return list[i].distance < list[j].distance
+		}
+		if list[i].count != list[j].count {
+			return list[i].count > list[j].count
+		}
+		return list[i].value < list[j].value
+	})
+
+	suggestions = []string{}
+	for _, c := range list {
+		if limit > 0 && len(suggestions) == limit {
+			break
+		}
+		suggestions = append(suggestions, c.value)
+	}
+	return
+}
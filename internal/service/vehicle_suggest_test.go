@@ -0,0 +1,56 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestVehicleDefault_Suggest(t *testing.T) {
+	db := map[int]internal.Vehicle{
+		1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Model: "Focus", Registration: "A1"}},
+		2: {Id: 2, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Model: "Fiesta", Registration: "A2"}},
+		3: {Id: 3, VehicleAttributes: internal.VehicleAttributes{Brand: "FORD", Model: "Falcon", Registration: "A3"}},
+		4: {Id: 4, VehicleAttributes: internal.VehicleAttributes{Brand: "Fiat", Model: "Uno", Registration: "A4"}},
+		5: {Id: 5, VehicleAttributes: internal.VehicleAttributes{Brand: "Citroën", Model: "C3", Registration: "A5"}},
+		6: {Id: 6, VehicleAttributes: internal.VehicleAttributes{Brand: "Chevrolet", Model: "Corsa", Registration: "A6"}},
+		7: {Id: 7, VehicleAttributes: internal.VehicleAttributes{Brand: "Chery", Model: "Tiggo", Registration: "A7"}},
+		8: {Id: 8, VehicleAttributes: internal.VehicleAttributes{Brand: "Chery", Model: "QQ", Registration: "A8"}},
+	}
+	sv := service.NewVehicleDefault(repository.NewVehicleMap(db, nil), nil, nil, nil)
+
+	tests := []struct {
+		name    string
+		field   string
+		prefix  string
+		limit   int
+		want    []string
+		wantErr bool
+	}{
+		{name: "by count, the most frequent spelling", field: "brand", prefix: "f", want: []string{"Ford", "Fiat"}},
+		{name: "limit", field: "brand", prefix: "f", limit: 1, want: []string{"Ford"}},
+		{name: "accents ignored", field: "brand", prefix: "citro", want: []string{"Citroën"}},
+		{name: "misspelled prefix after the exact ones, even if more frequent", field: "brand", prefix: "chev", want: []string{"Chevrolet", "Chery"}},
+		{name: "short misspelled prefix", field: "brand", prefix: "x", want: []string{}},
+		{name: "ties by value", field: "model", prefix: "f", want: []string{"Falcon", "Fiesta", "Focus"}},
+		{name: "field that is not text", field: "year", prefix: "2", wantErr: true},
+		{name: "unknown field", field: "owner", prefix: "a", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suggestions, err := sv.Suggest(context.Background(), tt.field, tt.prefix, tt.limit)
+			var errInv *internal.ErrInvalidAttributes
+			if tt.wantErr != errors.As(err, &errInv) {
+				t.Fatalf("Suggest() error = %v, want an invalid field %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal(suggestions, tt.want) {
+				t.Errorf("Suggest(%s, %q) = %v, want %v", tt.field, tt.prefix, suggestions, tt.want)
+			}
+		})
+	}
+}
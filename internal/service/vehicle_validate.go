@@ -0,0 +1,45 @@
+package service
+
+import (
+	"app/internal"
+	"app/internal/vin"
+)
+
+// MinFabricationYear is the year of the first automobile, the fabrication years before it are invalid
+const MinFabricationYear = 1886
+
+// Validate returns the invalid attributes of a vehicle, by the rules the service checks on every write.
+// The attributes are named as the fields of internal.VehicleAttributes, the result is empty if the vehicle is valid
+func Validate(attrs internal.VehicleAttributes) (invalid []*internal.ErrInvalidAttributes) {
+	check := func(valid bool, attr string) {
+		if !valid {
+			invalid = append(invalid, &internal.ErrInvalidAttributes{Attr: attr})
+		}
+	}
+
+	check(attrs.FabricationYear >= MinFabricationYear, "FabricationYear")
+	// - the vin is optional, if known it must match the brand and the fabrication year
+	check(attrs.Vin == "" || vin.Check(vin.Normalize(attrs.Vin), attrs.Brand, attrs.FabricationYear) == nil, "Vin")
+	return
+}
+
+// normalize returns the attributes with the registration, the country and the vin normalized,
+// so the repository checks the uniqueness of the registrations and vins written in different ways
+func normalize(attrs internal.VehicleAttributes) internal.VehicleAttributes {
+	attrs.Registration = internal.NormalizeRegistration(attrs.Registration)
+	attrs.Country = internal.NormalizeCountry(attrs.Country)
+	attrs.Vin = vin.Normalize(attrs.Vin)
+	return attrs
+}
+
+// validate returns the first invalid attribute of a vehicle, nil if it is valid.
+// The registration is checked with the format of the country of the vehicle
+func (s *VehicleDefault) validate(attrs internal.VehicleAttributes) (err error) {
+	if invalid := Validate(attrs); len(invalid) > 0 {
+		return invalid[0]
+	}
+	if s.rv != nil {
+		return s.rv.Validate(attrs.Country, attrs.Registration)
+	}
+	return nil
+}
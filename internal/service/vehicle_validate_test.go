@@ -0,0 +1,132 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/registration"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVehicleDefault_Add_Validate(t *testing.T) {
+	// valid are the attributes of a vehicle that is not in the fleet
+	valid := internal.VehicleAttributes{
+		Brand: "Honda", Model: "Accord", Registration: "AA400AA", Vin: "1HGCM82633A004352", Color: "Blue", FabricationYear: 2003,
+		Capacity: 5, FuelType: "gasoline", Transmission: "automatic", Weight: 1400,
+		Dimensions: internal.Dimensions{Length: 4.8, Width: 1.8, Height: 1.45},
+	}
+
+	tests := []struct {
+		name     string
+		change   func(attrs *internal.VehicleAttributes)
+		wantAttr string
+	}{
+		{name: "valid", change: func(attrs *internal.VehicleAttributes) {}},
+		{name: "first fabrication year", change: func(attrs *internal.VehicleAttributes) { attrs.FabricationYear, attrs.Vin = 1886, "" }},
+		{name: "fabrication year before the first automobile", change: func(attrs *internal.VehicleAttributes) { attrs.FabricationYear, attrs.Vin = 1885, "" }, wantAttr: "FabricationYear"},
+		{name: "vin in lowercase", change: func(attrs *internal.VehicleAttributes) { attrs.Vin = "1hgcm82633a004352" }},
+		{name: "vin with a wrong check digit", change: func(attrs *internal.VehicleAttributes) { attrs.Vin = "1HGCM82633A004353" }, wantAttr: "Vin"},
+		{name: "vin of another brand", change: func(attrs *internal.VehicleAttributes) { attrs.Brand = "Ford" }, wantAttr: "Vin"},
+		{name: "vin of another year", change: func(attrs *internal.VehicleAttributes) { attrs.FabricationYear = 2010 }, wantAttr: "Vin"},
+
+		// - the values that are likely wrong are reported by the lint of the files, the service accepts them
+		{name: "blank brand", change: func(attrs *internal.VehicleAttributes) { attrs.Brand, attrs.Vin = " ", "" }},
+		{name: "blank model", change: func(attrs *internal.VehicleAttributes) { attrs.Model = "" }},
+		{name: "fabrication year after the next one", change: func(attrs *internal.VehicleAttributes) {
+			attrs.FabricationYear, attrs.Vin = time.Now().Year()+5, ""
+		}},
+		{name: "negative weight", change: func(attrs *internal.VehicleAttributes) { attrs.Weight = -1 }},
+		{name: "negative width", change: func(attrs *internal.VehicleAttributes) { attrs.Width = -1.8 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sv := newFleet()
+			attrs := valid
+			tt.change(&attrs)
+
+			_, err := sv.Add(context.Background(), internal.Vehicle{VehicleAttributes: attrs})
+			var errInv *internal.ErrInvalidAttributes
+			switch {
+			case tt.wantAttr == "" && err != nil:
+				t.Errorf("Add() error = %v, want nil", err)
+			case tt.wantAttr != "" && (!errors.As(err, &errInv) || errInv.Attr != tt.wantAttr):
+				t.Errorf("Add() error = %v, want an invalid %s", err, tt.wantAttr)
+			}
+		})
+	}
+}
+
+func TestVehicleDefault_Write_Registration(t *testing.T) {
+	rv, err := registration.NewValidator(registration.ConfigValidator{DefaultCountry: "AR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a fleet with an old and a Mercosur registration of Argentina
+	db := map[int]internal.Vehicle{
+		1: {Id: 1, Version: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Model: "Fiesta", Registration: "AA100AA", FabricationYear: 2010}},
+		2: {Id: 2, Version: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Model: "Ranger", Registration: "ABC123", FabricationYear: 2010}},
+	}
+
+	tests := []struct {
+		name string
+		// id is the vehicle to update, 0 to add one
+		id               int
+		registration     string
+		country          string
+		wantErr          error
+		wantAttr         string
+		wantRegistration string
+	}{
+		{name: "add normalized", registration: "ab 200-ab", wantRegistration: "AB200AB"},
+		{name: "add of the country of the vehicle", registration: "1234 bcd", country: "es", wantRegistration: "1234BCD"},
+		{name: "add invalid for the default country", registration: "0", wantAttr: "Registration"},
+		{name: "add invalid for the country of the vehicle", registration: "AB200AB", country: "ES", wantAttr: "Registration"},
+		{name: "add of an unknown country", registration: "AB200AB", country: "XX", wantAttr: "Country"},
+		{name: "add duplicated written in another way", registration: "aa-100 aa", wantErr: internal.ErrVehicleExistent},
+		{name: "update normalized", id: 1, registration: "aa 100 ab", wantRegistration: "AA100AB"},
+		{name: "update invalid", id: 1, registration: "9", wantAttr: "Registration"},
+		{name: "update duplicated written in another way", id: 1, registration: "abc-123", wantErr: internal.ErrVehicleExistent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fleet := make(map[int]internal.Vehicle)
+			for id, vh := range db {
+				fleet[id] = vh
+			}
+			sv := service.NewVehicleDefault(repository.NewVehicleMap(fleet, nil), nil, rv, nil)
+			vehicle := internal.Vehicle{Id: tt.id, Version: 1, VehicleAttributes: internal.VehicleAttributes{
+				Brand: "Fiat", Model: "Uno", Registration: tt.registration, Country: tt.country, FabricationYear: 2005,
+			}}
+
+			var (
+				v   internal.Vehicle
+				err error
+			)
+			if tt.id == 0 {
+				v, err = sv.Add(context.Background(), vehicle)
+			} else {
+				v, err = sv.Update(context.Background(), vehicle)
+			}
+			var errInv *internal.ErrInvalidAttributes
+			switch {
+			case tt.wantAttr != "":
+				if !errors.As(err, &errInv) || errInv.Attr != tt.wantAttr {
+					t.Errorf("error = %v, want an invalid %s", err, tt.wantAttr)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Errorf("error = %v, want nil", err)
+			case v.Registration != tt.wantRegistration:
+				t.Errorf("registration = %q, want %q", v.Registration, tt.wantRegistration)
+			}
+		})
+	}
+}
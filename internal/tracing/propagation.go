@@ -0,0 +1,22 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// propagator is the W3C Trace Context propagator, the header traceparent
+var propagator = propagation.TraceContext{}
+
+// Inject writes the span context of the current span of ctx in the header traceparent
+func Inject(ctx context.Context, header http.Header) {
+	propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// Extract returns a copy of ctx that carries the span context of the header traceparent, if it is valid,
+// as the parent of the next span started
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the name of the instrumentation scope of the spans of the application
+const tracerName = "app/internal/tracing"
+
+// ConfigProvider is a struct that represents the configuration for the tracer provider
+type ConfigProvider struct {
+	// Endpoint is the url of the OTLP/HTTP traces receiver, e.g. http://localhost:4318/v1/traces.
+	// If it is empty the spans are recorded, so the logs carry their ids, but not exported
+	Endpoint string
+	// ServiceName is the service.name of the resource of the spans
+	ServiceName string
+}
+
+// NewProvider is a function that returns a new tracer provider that sends the spans in batches to the OTLP/HTTP receiver.
+// It must be shut down to send the pending spans
+func NewProvider(cfg ConfigProvider) (tp *sdktrace.TracerProvider, err error) {
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName)))
+	if err != nil {
+		return
+	}
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+
+	if cfg.Endpoint != "" {
+		// the exporter does not connect until the first batch is sent
+		ex, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(ex))
+	}
+
+	return sdktrace.NewTracerProvider(opts...), nil
+}
+
+// SetDefault makes tp the provider of the spans started by Start and the middleware,
+// and the W3C Trace Context the propagator of otel
+func SetDefault(tp trace.TracerProvider) {
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagator)
+}
+
+// Attr is an attribute of a span
+type Attr = attribute.KeyValue
+
+// String returns a string attribute
+func String(key, value string) Attr { return attribute.String(key, value) }
+
+// Int returns an integer attribute
+func Int(key string, value int) Attr { return attribute.Int(key, value) }
+
+// Float64 returns a floating point attribute
+func Float64(key string, value float64) Attr { return attribute.Float64(key, value) }
+
+// Bool returns a boolean attribute
+func Bool(key string, value bool) Attr { return attribute.Bool(key, value) }
+
+// Span is an otel span with the helpers to record the errors of the operations
+type Span struct {
+	trace.Span
+}
+
+// Start starts a span, child of the span of ctx, and returns a copy of ctx that carries it.
+// The span must be ended with End or EndError
+func Start(ctx context.Context, name string, attrs ...Attr) (context.Context, *Span) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+	return ctx, &Span{Span: span}
+}
+
+// RecordError records err as an event of the span and marks the span as failed, if err is not nil
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.Span.RecordError(err)
+	s.Span.SetStatus(codes.Error, err.Error())
+}
+
+// EndError records the error pointed by err, if any, and finishes the span.
+// It is meant to be deferred by functions with a named error result: defer span.EndError(&err)
+func (s *Span) EndError(err *error) {
+	if err != nil {
+		s.RecordError(*err)
+	}
+	s.End()
+}
@@ -0,0 +1,160 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// record makes a provider that records the spans in memory the default, until the end of the test
+func record(t *testing.T) (sr *tracetest.SpanRecorder) {
+	t.Helper()
+	sr = tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	SetDefault(tp)
+	t.Cleanup(func() { tp.Shutdown(context.Background()) })
+	return
+}
+
+// attr returns the value of the attribute key of a span
+func attr(span sdktrace.ReadOnlySpan, key string) (value attribute.Value) {
+	for _, a := range span.Attributes() {
+		if string(a.Key) == key {
+			return a.Value
+		}
+	}
+	return
+}
+
+func TestMiddleware(t *testing.T) {
+	const parent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	tests := []struct {
+		name        string
+		traceparent string
+		path        string
+		// wantParent is true if the span must be a child of the header traceparent
+		wantParent bool
+		wantName   string
+		wantStatus codes.Code
+	}{
+		{name: "child of the traceparent", traceparent: parent, path: "/vehicles/1", wantParent: true, wantName: "GET /vehicles/{id}"},
+		{name: "without traceparent", path: "/vehicles/1", wantName: "GET /vehicles/{id}"},
+		// - the ids are lowercase hex in the W3C grammar
+		{name: "uppercase traceparent", traceparent: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-01", path: "/vehicles/1", wantName: "GET /vehicles/{id}"},
+		{name: "invalid version", traceparent: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", path: "/vehicles/1", wantName: "GET /vehicles/{id}"},
+		{name: "trace id all zeros", traceparent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", path: "/vehicles/1", wantName: "GET /vehicles/{id}"},
+		{name: "server error", traceparent: parent, path: "/vehicles/0", wantParent: true, wantName: "GET /vehicles/{id}", wantStatus: codes.Error},
+		{name: "unmatched route", path: "/other", wantName: "GET"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := record(t)
+			rt := chi.NewRouter()
+			rt.Use(Middleware)
+			rt.Get("/vehicles/{id}", func(w http.ResponseWriter, r *http.Request) {
+				_, span := Start(r.Context(), "service")
+				span.End()
+				if chi.URLParam(r, "id") == "0" {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			})
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			res := httptest.NewRecorder()
+			rt.ServeHTTP(res, req)
+
+			// server span
+			ended := sr.Ended()
+			server := ended[len(ended)-1]
+			if server.Name() != tt.wantName || server.SpanKind() != trace.SpanKindServer {
+				t.Errorf("span = %s %s, want %s server", server.Name(), server.SpanKind(), tt.wantName)
+			}
+			if server.Parent().IsValid() != tt.wantParent {
+				t.Errorf("span has parent %t, want %t", server.Parent().IsValid(), tt.wantParent)
+			}
+			if tt.wantParent && server.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+				t.Errorf("span trace id = %s, want the one of the traceparent", server.SpanContext().TraceID())
+			}
+			if server.Status().Code != tt.wantStatus {
+				t.Errorf("span status = %s, want %s", server.Status().Code, tt.wantStatus)
+			}
+			if got := attr(server, "http.response.status_code").AsInt64(); got != int64(res.Code) {
+				t.Errorf("http.response.status_code = %d, want %d", got, res.Code)
+			}
+
+			// - the response carries the server span
+			got := Extract(context.Background(), res.Header())
+			if sc := trace.SpanContextFromContext(got); !sc.Equal(server.SpanContext().WithRemote(true)) {
+				t.Errorf("traceparent of the response = %q, want the server span", res.Header().Get("traceparent"))
+			}
+
+			// - the spans of the handler are children of the server span
+			if len(ended) == 2 && ended[0].Parent().SpanID() != server.SpanContext().SpanID() {
+				t.Errorf("span %s is not a child of the server span", ended[0].Name())
+			}
+		})
+	}
+}
+
+func TestSpan_EndError(t *testing.T) {
+	sr := record(t)
+
+	operation := func(fail bool) (err error) {
+		_, span := Start(context.Background(), "operation", String("kind", "test"))
+		defer span.EndError(&err)
+		if fail {
+			err = errors.New("failed")
+		}
+		return
+	}
+	operation(false)
+	operation(true)
+
+	ended := sr.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("%d spans ended, want 2", len(ended))
+	}
+	if ended[0].Status().Code != codes.Unset || len(ended[0].Events()) != 0 {
+		t.Errorf("span without error = %v, want unset status", ended[0].Status())
+	}
+	if ended[1].Status() != (sdktrace.Status{Code: codes.Error, Description: "failed"}) || len(ended[1].Events()) != 1 {
+		t.Errorf("span with error = %v, want the error recorded", ended[1].Status())
+	}
+	if attr(ended[1], "kind").AsString() != "test" {
+		t.Errorf("span attributes = %v, want kind=test", ended[1].Attributes())
+	}
+}
+
+func TestInject(t *testing.T) {
+	record(t)
+
+	// - a context without span injects nothing
+	header := http.Header{}
+	Inject(context.Background(), header)
+	if len(header) != 0 {
+		t.Errorf("Inject() without span = %v, want no header", header)
+	}
+
+	ctx, span := Start(context.Background(), "client")
+	defer span.End()
+	Inject(ctx, header)
+	sc := span.SpanContext()
+	want := "00-" + sc.TraceID().String() + "-" + sc.SpanID().String() + "-01"
+	if got := header.Get("traceparent"); got != want {
+		t.Errorf("Inject() = %q, want %q", got, want)
+	}
+}
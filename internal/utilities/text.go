@@ -0,0 +1,94 @@
+package utilities
+
+import (
+	"strings"
+	"unicode"
+)
+
+// accents maps the accented latin letters to their base letter
+var accents = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ä': 'a', 'ã': 'a', 'å': 'a', 'ā': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e', 'ē': 'e', 'ė': 'e', 'ę': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i', 'ī': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'ö': 'o', 'õ': 'o', 'ø': 'o', 'ō': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u', 'ū': 'u',
+	'ý': 'y', 'ÿ': 'y',
+	'ñ': 'n', 'ń': 'n',
+	'ç': 'c', 'č': 'c', 'ć': 'c',
+	'š': 's', 'ś': 's', 'ş': 's',
+	'ž': 'z', 'ź': 'z', 'ż': 'z',
+	'ř': 'r', 'ł': 'l', 'đ': 'd', 'ğ': 'g',
+}
+
+// Normalize returns s in lower case, without accents and with its spaces collapsed,
+// so that values with inconsistent spelling like "Citroën" and " citroen" compare equal
+func Normalize(s string) string {
+	var sb strings.Builder
+	space := false
+	for _, r := range strings.TrimSpace(s) {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			sb.WriteByte(' ')
+			space = false
+		}
+
+		r = unicode.ToLower(r)
+		if base, ok := accents[r]; ok {
+			r = base
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// EqualNormalized returns true if a and b are equal once normalized
+func EqualNormalized(a, b string) bool {
+	return Normalize(a) == Normalize(b)
+}
+
+// Levenshtein returns the edit distance between a and b, that is the minimum
+// number of insertions, deletions or substitutions of runes to turn a into b
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	// previous and current rows of the distance matrix
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// MaxFuzzyDistance returns the maximum edit distance tolerated when s is searched in fuzzy mode:
+// one typo every four characters, and at least one
+func MaxFuzzyDistance(s string) int {
+	return max(1, len([]rune(s))/4)
+}
+
+// FuzzyEqual returns true if a and b are equal once normalized, or if their edit distance is
+// tolerated for a, e.g. "Mauv" matches "Mauve" and "Fuchsa" matches "Fuchsia",
+// but "Fuscia" does not match "Fuchsia": its distance is 3 and 6 runes tolerate 1
+func FuzzyEqual(a, b string) bool {
+	na, nb := Normalize(a), Normalize(b)
+	if na == nb {
+		return true
+	}
+	return Levenshtein(na, nb) <= MaxFuzzyDistance(na)
+}
@@ -0,0 +1,91 @@
+package utilities
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "", want: ""},
+		{in: "Ford", want: "ford"},
+		{in: "Citroën", want: "citroen"},
+		{in: "  Land   Rover ", want: "land rover"},
+		{in: "ŠKODA", want: "skoda"},
+		{in: "Año\tNuevo", want: "ano nuevo"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "", b: "", want: 0},
+		{a: "", b: "abc", want: 3},
+		{a: "mauve", b: "mauve", want: 0},
+		{a: "mauv", b: "mauve", want: 1},
+		{a: "fuchsa", b: "fuchsia", want: 1},
+		{a: "fuscia", b: "fuchsia", want: 3},
+		{a: "kitten", b: "sitting", want: 3},
+		{a: "ñandú", b: "nandu", want: 2},
+	}
+	for _, tt := range tests {
+		if got := Levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := Levenshtein(tt.b, tt.a); got != tt.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestMaxFuzzyDistance(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{in: "", want: 1},
+		{in: "vw", want: 1},
+		{in: "fuscia", want: 1},
+		{in: "chevrolet", want: 2},
+		{in: "mercedes-benz", want: 3},
+		{in: "citroën", want: 1},
+	}
+	for _, tt := range tests {
+		if got := MaxFuzzyDistance(tt.in); got != tt.want {
+			t.Errorf("MaxFuzzyDistance(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFuzzyEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		// - equal once normalized
+		{a: "Citroen", b: "Citroën", want: true},
+		{a: "land rover", b: "Land  Rover", want: true},
+		// - within the tolerated distance of a
+		{a: "Mauv", b: "Mauve", want: true},
+		{a: "Fuchsa", b: "Fuchsia", want: true},
+		{a: "Chevrolte", b: "Chevrolet", want: true},
+		{a: "Toyta", b: "Toyota", want: true},
+		// - beyond the tolerated distance of a
+		{a: "Fuscia", b: "Fuchsia", want: false},
+		{a: "Ford", b: "Fiat", want: false},
+		{a: "Chevy", b: "Chevrolet", want: false},
+		{a: "", b: "VW", want: false},
+	}
+	for _, tt := range tests {
+		if got := FuzzyEqual(tt.a, tt.b); got != tt.want {
+			t.Errorf("FuzzyEqual(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
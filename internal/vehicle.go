@@ -1,5 +1,7 @@
 package internal
 
+import "time"
+
 // Dimensions is a struct that represents a dimension in 3d
 type Dimensions struct {
 	// Height is the height of the dimension
@@ -18,6 +20,11 @@ type VehicleAttributes struct {
 	Model string
 	// Registration is the registration of the vehicle
 	Registration string
+	// Country is the ISO 3166 code of the country of the registration, e.g. AR or US-CA.
+	// Empty for the default country of the configuration
+	Country string
+	// Vin is the vehicle identification number of ISO 3779, empty if it is unknown
+	Vin string
 	// Color is the color of the vehicle
 	Color string
 	// FabricationYear is the fabrication year of the vehicle
@@ -40,6 +47,11 @@ type VehicleAttributes struct {
 type Vehicle struct {
 	// Id is the unique identifier of the vehicle
 	Id int
+	// Version is incremented on each update of the vehicle, to detect concurrent writes
+	Version int
+	// DeletedAt is the time the vehicle was deleted, nil if it is not deleted.
+	// Deleted vehicles are kept until they are purged, so they can be restored
+	DeletedAt *time.Time
 
 	// VehicleAttribue is the attributes of a vehicle
 	VehicleAttributes
@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// AuditAction is the kind of change made to a vehicle
+type AuditAction string
+
+const (
+	AuditActionCreate  AuditAction = "create"
+	AuditActionUpdate  AuditAction = "update"
+	AuditActionDelete  AuditAction = "delete"
+	AuditActionRestore AuditAction = "restore"
+	AuditActionPurge   AuditAction = "purge"
+)
+
+// AuditChange is the value of a field before and after a change
+type AuditChange struct {
+	// Before is the value before the change, nil when the vehicle was created
+	Before any
+	// After is the value after the change, nil when the vehicle was deleted
+	After any
+}
+
+// AuditEvent is a record of a change made to a vehicle
+type AuditEvent struct {
+	// Timestamp is the time of the change
+	Timestamp time.Time
+	// Actor is who made the change
+	Actor string
+	// Action is the kind of change
+	Action AuditAction
+	// VehicleId is the id of the vehicle changed
+	VehicleId int
+	// Changes are the fields that changed, by field name
+	Changes map[string]AuditChange
+}
+
+// AuditSink is an interface that represents the storage of the audit events
+type AuditSink interface {
+	// Record stores an event
+	Record(e AuditEvent) (err error)
+	// FindByVehicle returns the events of a vehicle, oldest first
+	FindByVehicle(id int) (e []AuditEvent, err error)
+	// FindSince returns the events from a time on, oldest first
+	FindSince(since time.Time) (e []AuditEvent, err error)
+}
+
+// actorKey is the key of the actor in a context
+type actorKey struct{}
+
+// WithActor returns a copy of ctx that carries the actor of the changes
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor carried by ctx, or "anonymous" if there is none
+func ActorFromContext(ctx context.Context) string {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	if !ok || actor == "" {
+		return "anonymous"
+	}
+	return actor
+}
+
+// errors definition
+var (
+	ErrAuditFailed = errors.New("audit event could not be recorded")
+)
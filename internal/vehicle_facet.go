@@ -0,0 +1,9 @@
+package internal
+
+// VehicleFacet is the number of vehicles that have a value of a field
+type VehicleFacet struct {
+	// Value is the value of the field, e.g. "Ford"
+	Value string
+	// Count is the number of vehicles with the value
+	Count int
+}
@@ -4,4 +4,10 @@ package internal
 type VehicleLoader interface {
 	// Load is a method that loads the vehicles
 	Load() (v map[int]Vehicle, err error)
-}
\ No newline at end of file
+}
+
+// VehicleSaver is an interface that represents the saver for vehicles, the counterpart of VehicleLoader
+type VehicleSaver interface {
+	// Save is a method that saves the vehicles, replacing the saved ones
+	Save(v map[int]Vehicle) (err error)
+}
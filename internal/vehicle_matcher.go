@@ -0,0 +1,7 @@
+package internal
+
+// VehicleMatcher is an interface that represents a condition that a vehicle can satisfy
+type VehicleMatcher interface {
+	// Match returns true if the vehicle satisfies the condition
+	Match(v Vehicle) bool
+}
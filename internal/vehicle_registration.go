@@ -0,0 +1,29 @@
+package internal
+
+import (
+	"strings"
+	"unicode"
+)
+
+// RegistrationValidator is an interface that represents the rules of the registrations of the countries
+type RegistrationValidator interface {
+	// Validate returns an *ErrInvalidAttributes of Registration if the normalized registration has not a format of the country,
+	// or of Country if the country is unknown. An empty country is the default one of the validator
+	Validate(country, registration string) (err error)
+}
+
+// NormalizeRegistration returns the registration in uppercase without spaces nor dashes,
+// so that the same registration written in different ways is the same
+func NormalizeRegistration(registration string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, registration)
+}
+
+// NormalizeCountry returns the code of a country in uppercase, e.g. AR or US-CA
+func NormalizeCountry(country string) string {
+	return strings.ToUpper(strings.TrimSpace(country))
+}
@@ -1,23 +1,49 @@
 package internal
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"time"
+)
 
 // VehicleRepository is an interface that represents a vehicle repository
 type VehicleRepository interface {
-	// FindAll is a method that returns a map of all vehicles
-	FindAll() (v map[int]Vehicle, err error)
+	// FindAll is a method that returns a map of all vehicles, except the deleted ones
+	FindAll(ctx context.Context) (v map[int]Vehicle, err error)
 	// Add adds a new vehicle to the repo
-	Add(newVehicle Vehicle) (v Vehicle, err error)
-	// FindAllEqualTo returns a map of vehicles that passed the filters
-	FindAllEqualTo(filter EqualFilter) (v map[int]Vehicle, err error)
-	// Update updates an existent vehicle
-	Update(vehicle Vehicle) (v Vehicle, err error)
+	Add(ctx context.Context, newVehicle Vehicle) (v Vehicle, err error)
+	// FindAllEqualTo returns a map of vehicles that passed the filters, the deleted ones only if the filter includes them
+	FindAllEqualTo(ctx context.Context, filter EqualFilter) (v map[int]Vehicle, err error)
+	// Update updates an existent vehicle, if vehicle.Version is the current version of the vehicle.
+	// previous is the state replaced by the update, read under the same lock as the write
+	Update(ctx context.Context, vehicle Vehicle) (v, previous Vehicle, err error)
 
 	// New methods
-
+	// FindAllMatching returns a map of vehicles that satisfy the matcher, except the deleted ones
+	FindAllMatching(ctx context.Context, m VehicleMatcher) (v map[int]Vehicle, err error)
+	// Search returns the vehicles that match the text of the query, sorted by relevance, except the deleted ones
+	Search(ctx context.Context, query string) (results []VehicleSearchResult, err error)
+	// FindById returns the vehicle with the given id, even if it is deleted
+	FindById(ctx context.Context, id int) (v Vehicle, err error)
+	// FindByVin returns the vehicle with the given normalized VIN, even if it is deleted
+	FindByVin(ctx context.Context, vin string) (v Vehicle, err error)
+	// Delete soft deletes an existent vehicle, if version is its current version.
+	// previous is the state replaced by the delete, read under the same lock as the write
+	Delete(ctx context.Context, id int, version int) (previous Vehicle, err error)
+	// Restore restores a deleted vehicle, if version is its current version
+	Restore(ctx context.Context, id int, version int) (v Vehicle, err error)
+	// Purge permanently removes the vehicles deleted before a time, returning them
+	Purge(ctx context.Context, deletedBefore time.Time) (v []Vehicle, err error)
+	// Revision returns the current revision of the vehicles
+	Revision(ctx context.Context) (rev Revision, err error)
+	// FindByIdAsOf returns the state of the vehicle with the given id at a point in time
+	FindByIdAsOf(ctx context.Context, id int, asOf time.Time) (v Vehicle, err error)
+	// FindVersion returns a version of the vehicle with the given id
+	FindVersion(ctx context.Context, id int, version int) (v Vehicle, err error)
 }
 
-// EqualFilter is a filter for query the repository
+// EqualFilter is a filter for query the repository.
+// A nil or empty field means that the vehicles are not filtered by that field.
 type EqualFilter struct {
 	// Brand is the brand of the vehicle
 	Brand string
@@ -26,26 +52,82 @@ type EqualFilter struct {
 	// Color is the color of the vehicle
 	Color string
 	// FabricationYear is the fabrication year of the vehicle
-	FabricationYear int
+	FabricationYear *int
 	// Capacity is the capacity of people of the vehicle
-	Capacity int
+	Capacity *int
 	// FuelType is the fuel type of the vehicle
 	FuelType string
 	// Transmission is the transmission of the vehicle
 	Transmission string
+	// Fuzzy enables the approximate matching of the text fields, tolerating misspellings.
+	// Text fields are always compared ignoring case and accents
+	Fuzzy bool
+	// IncludeDeleted includes the deleted vehicles in the results
+	IncludeDeleted bool
 
-	// FabricationYearRange is an array that contains a min and max value for FabricationYear
-	FabricationYearRange [2]int
-	// LengthRange is an array that contains a min and max value for Length
-	LengthRange [2]float64
-	// WidthRange is an array that contains a min and max value for Width
-	WidthRange [2]float64
-	// WeightRange is an array that contains a min and max value for Weight
-	WeightRange [2]float64
+	// FabricationYearRange is the range of values for FabricationYear
+	FabricationYearRange Range[int]
+	// LengthRange is the range of values for Length
+	LengthRange Range[float64]
+	// WidthRange is the range of values for Width
+	WidthRange Range[float64]
+	// WeightRange is the range of values for Weight
+	WeightRange Range[float64]
+}
+
+// Range is a closed range of values where any of the bounds can be omitted
+type Range[T int | float64] struct {
+	// Min is the lower bound of the range, nil means there is no lower bound
+	Min *T
+	// Max is the upper bound of the range, nil means there is no upper bound
+	Max *T
+}
+
+// Contains returns true if the value is inside the range
+func (r Range[T]) Contains(value T) bool {
+	if r.Min != nil && value < *r.Min {
+		return false
+	}
+	if r.Max != nil && value > *r.Max {
+		return false
+	}
+	return true
+}
+
+// Valid returns false if both bounds are set and the lower one is greater than the upper one
+func (r Range[T]) Valid() bool {
+	return r.Min == nil || r.Max == nil || *r.Min <= *r.Max
+}
+
+// IsSet returns true if any of the bounds of the range is set
+func (r Range[T]) IsSet() bool {
+	return r.Min != nil || r.Max != nil
+}
+
+// Valid returns false if any of the ranges of the filter is not valid
+func (f EqualFilter) Valid() bool {
+	return f.FabricationYearRange.Valid() && f.LengthRange.Valid() && f.WidthRange.Valid() && f.WeightRange.Valid()
 }
 
 // errors definition
 var (
+	ErrInvalidRange    = errors.New("range min is greater than max")
 	ErrVehicleExistent = errors.New("vehicle registration already exists")
 	ErrVehicleNotFound = errors.New("vehicle not found")
+	ErrVehicleConflict = errors.New("vehicle version does not match the current version")
+	ErrVersionNotFound = errors.New("vehicle version not found")
+	ErrNotDeleted      = errors.New("vehicle is not deleted")
+	// ErrVinExistent is also an ErrVehicleExistent, for the VIN instead of the registration
+	ErrVinExistent error = existentError("vehicle vin already exists")
 )
+
+// existentError is an error that is also ErrVehicleExistent, so that it is handled as it
+type existentError string
+
+func (e existentError) Error() string {
+	return string(e)
+}
+
+func (e existentError) Is(target error) bool {
+	return target == ErrVehicleExistent
+}
@@ -0,0 +1,11 @@
+package internal
+
+import "time"
+
+// Revision identifies a state of the vehicles, it changes on every modification
+type Revision struct {
+	// Number is incremented on every modification of the vehicles
+	Number uint64
+	// ModifiedAt is the time of the last modification
+	ModifiedAt time.Time
+}
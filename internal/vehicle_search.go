@@ -0,0 +1,9 @@
+package internal
+
+// VehicleSearchResult is a vehicle found by a full-text search
+type VehicleSearchResult struct {
+	// Vehicle is the vehicle found
+	Vehicle
+	// Score is the relevance of the vehicle for the search, the higher the better
+	Score float64
+}
@@ -1,24 +1,59 @@
 package internal
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 )
 
 // VehicleService is an interface that represents a vehicle service
 type VehicleService interface {
-	// FindAll is a method that returns a map of all vehicles
-	FindAll() (v map[int]Vehicle, err error)
-	// Add adds a new vehicle to the repo
-	Add(newVehicle Vehicle) (v Vehicle, err error)
+	// FindAll is a method that returns a map of all vehicles, except the deleted ones
+	FindAll(ctx context.Context) (v map[int]Vehicle, err error)
+	// Add adds a new vehicle to the repo, on behalf of the actor of ctx
+	Add(ctx context.Context, newVehicle Vehicle) (v Vehicle, err error)
 	// FindAllEqualTo returns a map of vehicles that passed the filters
-	FindAllEqualTo(filter EqualFilter) (v map[int]Vehicle, err error)
-	// Update updates an existent vehicle
-	Update(vehicle Vehicle) (v Vehicle, err error)
+	FindAllEqualTo(ctx context.Context, filter EqualFilter) (v map[int]Vehicle, err error)
+	// Update updates an existent vehicle, if vehicle.Version is the current version of the vehicle,
+	// on behalf of the actor of ctx
+	Update(ctx context.Context, vehicle Vehicle) (v Vehicle, err error)
 
 	// New methods
 	// GetAvgCapacity returns the avg of the brands capacity
-	GetAvgCapacity(brand string) (avg float64, err error)
+	GetAvgCapacity(ctx context.Context, brand string) (avg float64, err error)
+	// FindAllMatching returns a map of vehicles that satisfy the matcher
+	FindAllMatching(ctx context.Context, m VehicleMatcher) (v map[int]Vehicle, err error)
+	// Suggest returns up to limit distinct values of a text field that start with (or approximately start with) prefix
+	Suggest(ctx context.Context, field string, prefix string, limit int) (suggestions []string, err error)
+	// Search returns up to limit vehicles that match the free text query, sorted by relevance
+	Search(ctx context.Context, query string, limit int) (results []VehicleSearchResult, err error)
+	// GetStats returns the statistics of a numeric field by group, the group is "" if the query is not grouped
+	GetStats(ctx context.Context, query VehicleStatsQuery) (stats map[string]VehicleStats, err error)
+	// GetFacets returns the number of vehicles per value of each field, for the vehicles that pass the filter
+	GetFacets(ctx context.Context, fields []string, filter EqualFilter) (facets map[string][]VehicleFacet, err error)
+	// FindById returns the vehicle with the given id, a deleted one only if includeDeleted is true
+	FindById(ctx context.Context, id int, includeDeleted bool) (v Vehicle, err error)
+	// FindByVin returns the vehicle with the given VIN, a deleted one only if includeDeleted is true
+	FindByVin(ctx context.Context, vin string, includeDeleted bool) (v Vehicle, err error)
+	// Delete soft deletes an existent vehicle, if version is its current version, on behalf of the actor of ctx
+	Delete(ctx context.Context, id int, version int) (err error)
+	// Revision returns the current revision of the vehicles
+	Revision(ctx context.Context) (rev Revision, err error)
+	// History returns the audit events of a vehicle, oldest first
+	History(ctx context.Context, id int) (e []AuditEvent, err error)
+	// AuditSince returns the audit events from a time on, oldest first
+	AuditSince(ctx context.Context, since time.Time) (e []AuditEvent, err error)
+	// FindByIdAsOf returns the state of the vehicle with the given id at a point in time
+	FindByIdAsOf(ctx context.Context, id int, asOf time.Time) (v Vehicle, err error)
+	// Revert restores the attributes of a previous version of a vehicle, as a new version.
+	// If expectedVersion is not 0 it must be the current version of the vehicle
+	Revert(ctx context.Context, id int, version int, expectedVersion int) (v Vehicle, err error)
+	// Restore restores a deleted vehicle, on behalf of the actor of ctx.
+	// If expectedVersion is not 0 it must be the current version of the vehicle
+	Restore(ctx context.Context, id int, expectedVersion int) (v Vehicle, err error)
+	// PurgeDeleted permanently removes the vehicles deleted longer than retention ago, on behalf of the actor of ctx
+	PurgeDeleted(ctx context.Context, retention time.Duration) (purged int, err error)
 }
 
 // errors definition
@@ -32,4 +67,6 @@ func (e *ErrInvalidAttributes) Error() string {
 
 var (
 	ErrVehiclesNotFound = errors.New("vehicles not found")
+	ErrEmptySearch      = errors.New("search query is empty")
+	ErrAuditDisabled    = errors.New("audit is disabled")
 )
@@ -0,0 +1,29 @@
+package internal
+
+// VehicleStatsQuery is a query for statistics of a numeric field of the vehicles
+type VehicleStatsQuery struct {
+	// Field is the numeric field to summarize, e.g. "passengers" or "weight"
+	Field string
+	// GroupBy is the optional text field to group the vehicles by, e.g. "brand"
+	GroupBy string
+	// Percentiles are the percentiles to compute, between 0 and 100
+	Percentiles []float64
+	// Filter selects the vehicles to summarize
+	Filter EqualFilter
+}
+
+// VehicleStats is a summary of a numeric field of a group of vehicles
+type VehicleStats struct {
+	// Count is the number of vehicles in the group
+	Count int
+	// Min is the minimum value of the field
+	Min float64
+	// Max is the maximum value of the field
+	Max float64
+	// Mean is the average value of the field
+	Mean float64
+	// Median is the median value of the field
+	Median float64
+	// Percentiles are the requested percentiles of the field, by percentile
+	Percentiles map[float64]float64
+}
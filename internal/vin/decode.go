@@ -0,0 +1,124 @@
+package vin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// yearCodes are the codes of the model years in the position 10, from 1980 and repeated every 30 years
+const yearCodes = "ABCDEFGHJKLMNPRSTVWXY123456789"
+
+// Info is the information decoded from a VIN
+type Info struct {
+	// WMI is the world manufacturer identifier, the first 3 characters
+	WMI string
+	// Region is the region of the manufacturer, by the first character
+	Region string
+	// Manufacturer is the manufacturer of the WMI, empty if it is unknown
+	Manufacturer string
+	// Brands are the brands of the manufacturer made with the WMI, empty if it is unknown
+	Brands []string
+	// ModelYears are the possible model years by the position 10, the oldest first.
+	// The VINs of North America tell the cycle by the position 7, a digit until 2009 and a letter from 2010
+	ModelYears []int
+}
+
+// HasBrand returns true if the brand is made with the WMI, without distinguishing case
+func (i Info) HasBrand(brand string) bool {
+	for _, b := range i.Brands {
+		if strings.EqualFold(b, strings.TrimSpace(brand)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Decode validates a normalized VIN and returns its information
+func Decode(vin string) (info Info, err error) {
+	if err = Validate(vin); err != nil {
+		return
+	}
+
+	info.WMI = vin[:3]
+	info.Region = region(vin[0])
+	if m, ok := manufacturers[info.WMI]; ok {
+		info.Manufacturer = m.name
+		info.Brands = m.brands
+	}
+
+	// model year
+	code := strings.IndexByte(yearCodes, vin[9])
+	if code < 0 {
+		return Info{}, fmt.Errorf("%w %q at position 10, it is not a model year", ErrCharacter, vin[9])
+	}
+	switch {
+	case info.Region == "North America" && isDigit(vin[6]):
+		info.ModelYears = []int{1980 + code}
+	case info.Region == "North America":
+		info.ModelYears = []int{2010 + code}
+	default:
+		info.ModelYears = []int{1980 + code, 2010 + code}
+	}
+	return
+}
+
+// isDigit returns true if the character is a digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// region returns the region of the first character of a VIN
+func region(c byte) string {
+	switch {
+	case c >= '1' && c <= '5':
+		return "North America"
+	case c == '6' || c == '7':
+		return "Oceania"
+	case c == '8' || c == '9':
+		return "South America"
+	case c >= 'A' && c <= 'H':
+		return "Africa"
+	case c >= 'J' && c <= 'R':
+		return "Asia"
+	}
+	return "Europe"
+}
+
+// New returns a VIN of a manufacturer with its check digit: wmi are 3 characters, descriptor the 5 of the vehicle attributes,
+// plant the character of the assembly plant and serial the sequential number, of up to 6 digits.
+// In North America the position 7 of the descriptor is replaced to tell the cycle of the model year
+func New(wmi, descriptor string, modelYear int, plant byte, serial int) (vin string, err error) {
+	if len(wmi) != 3 || len(descriptor) != 5 {
+		return "", fmt.Errorf("%w: wmi %q and descriptor %q must have 3 and 5 characters", ErrLength, wmi, descriptor)
+	}
+	if modelYear < 1980 || modelYear >= 2040 {
+		return "", fmt.Errorf("vin: model year %d out of the range 1980-2039", modelYear)
+	}
+
+	b := []byte(wmi + descriptor + "0" + string(yearCodes[(modelYear-1980)%30]) + string(plant) + fmt.Sprintf("%06d", serial%1000000))
+	if region(b[0]) == "North America" {
+		if modelYear < 2010 && !isDigit(b[6]) {
+			b[6] = '0' + b[6]%10
+		}
+		if modelYear >= 2010 && isDigit(b[6]) {
+			b[6] = "ABCDEFGHJK"[b[6]-'0']
+		}
+	}
+
+	digit, err := CheckDigit(string(b))
+	if err != nil {
+		return
+	}
+	b[8] = digit
+	return string(b), nil
+}
+
+// WMI returns the first WMI of the manufacturers of a brand, without distinguishing case
+func WMI(brand string) (wmi string, ok bool) {
+	for _, m := range wmis {
+		if (Info{Brands: m.brands}).HasBrand(brand) {
+			return m.wmi, true
+		}
+	}
+	return "", false
+}
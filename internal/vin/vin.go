@@ -0,0 +1,118 @@
+// Package vin validates, decodes and builds the vehicle identification numbers of ISO 3779,
+// with the check digit in the position 9 as in North America
+package vin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Length is the number of characters of a VIN
+const Length = 17
+
+var (
+	// ErrLength is returned when a VIN has not 17 characters
+	ErrLength = errors.New("vin: must have 17 characters")
+	// ErrCharacter is returned when a VIN has a character that is not a digit nor a letter other than I, O and Q
+	ErrCharacter = errors.New("vin: invalid character")
+	// ErrCheckDigit is returned when the check digit of a VIN does not match its other characters
+	ErrCheckDigit = errors.New("vin: invalid check digit")
+	// ErrBrand is returned when the brand of a vehicle is not made by the manufacturer of its VIN
+	ErrBrand = errors.New("vin: brand does not match the manufacturer")
+	// ErrYear is returned when the fabrication year of a vehicle does not match the model year of its VIN
+	ErrYear = errors.New("vin: fabrication year does not match the model year")
+)
+
+// values are the values of the characters to compute the check digit, the letters I, O and Q are not used
+var values = map[rune]int{
+	'A': 1, 'B': 2, 'C': 3, 'D': 4, 'E': 5, 'F': 6, 'G': 7, 'H': 8,
+	'J': 1, 'K': 2, 'L': 3, 'M': 4, 'N': 5, 'P': 7, 'R': 9,
+	'S': 2, 'T': 3, 'U': 4, 'V': 5, 'W': 6, 'X': 7, 'Y': 8, 'Z': 9,
+}
+
+// weights are the weights of each position to compute the check digit, the one of the check digit is 0
+var weights = [Length]int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+
+// Normalize returns the VIN in uppercase without spaces nor dashes
+func Normalize(vin string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, vin)
+}
+
+// value returns the value of a character of a VIN
+func value(r rune) (v int, ok bool) {
+	if r >= '0' && r <= '9' {
+		return int(r - '0'), true
+	}
+	v, ok = values[r]
+	return
+}
+
+// CheckDigit returns the check digit of a VIN, the characters of the position 9 are ignored
+func CheckDigit(vin string) (digit byte, err error) {
+	if len(vin) != Length {
+		return 0, ErrLength
+	}
+
+	sum := 0
+	for i, r := range vin {
+		v, ok := value(r)
+		if !ok {
+			return 0, fmt.Errorf("%w %q at position %d", ErrCharacter, r, i+1)
+		}
+		sum += v * weights[i]
+	}
+
+	if sum%11 == 10 {
+		return 'X', nil
+	}
+	return byte('0' + sum%11), nil
+}
+
+// Validate returns an error if the normalized VIN has not 17 valid characters or its check digit is wrong
+func Validate(vin string) (err error) {
+	digit, err := CheckDigit(vin)
+	if err != nil {
+		return
+	}
+	if vin[8] != digit {
+		return fmt.Errorf("%w: %c, expected %c", ErrCheckDigit, vin[8], digit)
+	}
+	return
+}
+
+// Check validates the normalized VIN of a vehicle and cross-checks it with its brand and fabrication year:
+// the brand must be made by the manufacturer of the WMI, if it is known,
+// and the fabrication year must be the model year or the year before it
+func Check(vin, brand string, year int) (err error) {
+	info, err := Decode(vin)
+	if err != nil {
+		return
+	}
+
+	if len(info.Brands) > 0 && !info.HasBrand(brand) {
+		return fmt.Errorf("%w: %s makes %s, not %s", ErrBrand, info.Manufacturer, strings.Join(info.Brands, ", "), brand)
+	}
+
+	for _, modelYear := range info.ModelYears {
+		if year == modelYear || year == modelYear-1 {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %d is not the model year %s", ErrYear, year, joinYears(info.ModelYears))
+}
+
+// joinYears returns the years separated by or
+func joinYears(years []int) string {
+	s := make([]string, len(years))
+	for i, y := range years {
+		s[i] = fmt.Sprint(y)
+	}
+	return strings.Join(s, " or ")
+}
@@ -0,0 +1,173 @@
+package vin
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// withCheckDigit returns the VIN with its check digit in the position 9
+func withCheckDigit(t *testing.T, vin string) string {
+	t.Helper()
+	digit, err := CheckDigit(vin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vin[:8] + string(digit) + vin[9:]
+}
+
+func TestNormalize(t *testing.T) {
+	if got := Normalize(" 1hgcm-8263 3a004352\t"); got != "1HGCM82633A004352" {
+		t.Errorf("Normalize() = %q", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		vin     string
+		wantErr error
+	}{
+		{name: "valid", vin: "1HGCM82633A004352"},
+		{name: "check digit X", vin: "1M8GDM9AXKP042788"},
+		{name: "all ones", vin: "11111111111111111"},
+		{name: "wrong check digit", vin: "1HGCM82643A004352", wantErr: ErrCheckDigit},
+		{name: "check digit of another character", vin: "1HGCM82633A004353", wantErr: ErrCheckDigit},
+		{name: "too short", vin: "1HGCM82633A00435", wantErr: ErrLength},
+		{name: "too long", vin: "1HGCM82633A0043521", wantErr: ErrLength},
+		{name: "letter I", vin: "1HGCM82633A0I4352", wantErr: ErrCharacter},
+		{name: "letter O", vin: "1HGCM82633A0O4352", wantErr: ErrCharacter},
+		{name: "letter Q", vin: "1HGCM82633A0Q4352", wantErr: ErrCharacter},
+		{name: "not normalized", vin: "1hgcm82633a004352", wantErr: ErrCharacter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.vin); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name         string
+		vin          string
+		region       string
+		manufacturer string
+		modelYears   []int
+		wantErr      error
+	}{
+		{name: "north america, cycle of 1980", vin: "1HGCM82633A004352", region: "North America", manufacturer: "Honda", modelYears: []int{2003}},
+		{name: "north america, cycle of 2010", vin: withCheckDigit(t, "1FAHP3F20CL000001"), region: "North America", manufacturer: "Ford", modelYears: []int{2012}},
+		{name: "europe, both cycles", vin: withCheckDigit(t, "WVWZZZ1K05W000001"), region: "Europe", manufacturer: "Volkswagen", modelYears: []int{2005, 2035}},
+		{name: "south america", vin: withCheckDigit(t, "8AFAB12305K000001"), region: "South America", manufacturer: "Ford Argentina", modelYears: []int{2005, 2035}},
+		{name: "unknown manufacturer", vin: "11111111111111111", region: "North America", modelYears: []int{2001}},
+		{name: "model year U", vin: withCheckDigit(t, "1HGCM8263UA004352"), wantErr: ErrCharacter},
+		{name: "model year 0", vin: withCheckDigit(t, "1HGCM82630A004352"), wantErr: ErrCharacter},
+		{name: "invalid", vin: "1HGCM82643A004352", wantErr: ErrCheckDigit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := Decode(tt.vin)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode() = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if info.WMI != tt.vin[:3] || info.Region != tt.region || info.Manufacturer != tt.manufacturer {
+				t.Errorf("Decode() = %+v, want the region %s and the manufacturer %q", info, tt.region, tt.manufacturer)
+			}
+			if !slices.Equal(info.ModelYears, tt.modelYears) {
+				t.Errorf("Decode() model years = %v, want %v", info.ModelYears, tt.modelYears)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		vin     string
+		brand   string
+		year    int
+		wantErr error
+	}{
+		{name: "brand and model year", vin: "1HGCM82633A004352", brand: "Honda", year: 2003},
+		{name: "brand in lowercase", vin: "1HGCM82633A004352", brand: " honda ", year: 2003},
+		{name: "year before the model year", vin: "1HGCM82633A004352", brand: "Honda", year: 2002},
+		{name: "year after the model year", vin: "1HGCM82633A004352", brand: "Honda", year: 2004, wantErr: ErrYear},
+		{name: "year of the other cycle", vin: "1HGCM82633A004352", brand: "Honda", year: 2033, wantErr: ErrYear},
+		{name: "brand of another manufacturer", vin: "1HGCM82633A004352", brand: "Ford", year: 2003, wantErr: ErrBrand},
+		{name: "any brand of an unknown manufacturer", vin: "11111111111111111", brand: "Zastava", year: 2001},
+		{name: "europe, either cycle", vin: withCheckDigit(t, "WVWZZZ1K05W000001"), brand: "Volkswagen", year: 2035},
+		{name: "invalid", vin: "1HGCM82643A004352", brand: "Honda", year: 2003, wantErr: ErrCheckDigit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Check(tt.vin, tt.brand, tt.year); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Check() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	// - the VINs of every year and region are valid and decode to the year, with the serial number
+	for _, wmi := range []string{"1HG", "JHM", "WVW", "8AF"} {
+		for year := 1980; year < 2040; year++ {
+			number, err := New(wmi, "AB12C", year, 'P', 1234567)
+			if err != nil {
+				t.Fatalf("New(%s, %d) = %v", wmi, year, err)
+			}
+			info, err := Decode(number)
+			if err != nil || !slices.Contains(info.ModelYears, year) {
+				t.Errorf("Decode(New(%s, %d)) = %s, %v, %v", wmi, year, number, info.ModelYears, err)
+			}
+			if number[:3] != wmi || number[10] != 'P' || number[11:] != "234567" {
+				t.Errorf("New(%s, %d) = %s, want the wmi, the plant and the last 6 digits of the serial", wmi, year, number)
+			}
+		}
+	}
+
+	// - the VIN of a real vehicle, and the cycle of North America told by the position 7
+	old, _ := New("1HG", "CM826", 2003, 'A', 4352)
+	recent, _ := New("1HG", "CM826", 2033, 'A', 4352)
+	if old != "1HGCM82633A004352" || recent[6] != 'C' {
+		t.Errorf("New() = %s and %s, want 1HGCM82633A004352 and a letter in the position 7", old, recent)
+	}
+
+	// - invalid arguments
+	tests := []struct {
+		name       string
+		wmi        string
+		descriptor string
+		year       int
+	}{
+		{name: "short wmi", wmi: "1H", descriptor: "CM826", year: 2003},
+		{name: "long descriptor", wmi: "1HG", descriptor: "CM8263", year: 2003},
+		{name: "year before 1980", wmi: "1HG", descriptor: "CM826", year: 1979},
+		{name: "year after 2039", wmi: "1HG", descriptor: "CM826", year: 2040},
+		{name: "invalid character", wmi: "1HG", descriptor: "CM82I", year: 2003},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if number, err := New(tt.wmi, tt.descriptor, tt.year, 'A', 1); err == nil {
+				t.Errorf("New() = %s, want an error", number)
+			}
+		})
+	}
+}
+
+func TestWMI(t *testing.T) {
+	if wmi, ok := WMI("honda"); !ok || wmi != "JHM" {
+		t.Errorf("WMI(honda) = %s, %t, want the first WMI of Honda", wmi, ok)
+	}
+	if wmi, ok := WMI("Zastava"); ok {
+		t.Errorf("WMI(Zastava) = %s, want none", wmi)
+	}
+}
@@ -0,0 +1,156 @@
+package vin
+
+// manufacturer is a manufacturer of a WMI and the brands it makes with it
+type manufacturer struct {
+	wmi    string
+	name   string
+	brands []string
+}
+
+// wmis are the known WMIs, the first one of each brand is the main one
+var wmis = []manufacturer{
+	// General Motors
+	{"1G1", "General Motors", []string{"Chevrolet"}},
+	{"1GC", "General Motors", []string{"Chevrolet"}},
+	{"1GN", "General Motors", []string{"Chevrolet"}},
+	{"2G1", "General Motors", []string{"Chevrolet"}},
+	{"3G1", "General Motors", []string{"Chevrolet"}},
+	{"1G4", "General Motors", []string{"Buick"}},
+	{"2G4", "General Motors", []string{"Buick"}},
+	{"1G6", "General Motors", []string{"Cadillac"}},
+	{"1GY", "General Motors", []string{"Cadillac"}},
+	{"1GT", "General Motors", []string{"GMC"}},
+	{"1GK", "General Motors", []string{"GMC"}},
+	{"2GT", "General Motors", []string{"GMC"}},
+	{"1G2", "General Motors", []string{"Pontiac"}},
+	{"2G2", "General Motors", []string{"Pontiac"}},
+	{"1G3", "General Motors", []string{"Oldsmobile"}},
+	{"1G8", "General Motors", []string{"Saturn"}},
+	{"5GR", "General Motors", []string{"Hummer"}},
+	{"5GT", "General Motors", []string{"Hummer"}},
+	{"8AG", "General Motors Argentina", []string{"Chevrolet"}},
+	{"9BG", "General Motors Brazil", []string{"Chevrolet"}},
+	// Ford
+	{"1FA", "Ford", []string{"Ford"}},
+	{"1FM", "Ford", []string{"Ford"}},
+	{"1FT", "Ford", []string{"Ford"}},
+	{"1FD", "Ford", []string{"Ford"}},
+	{"2FA", "Ford Canada", []string{"Ford"}},
+	{"3FA", "Ford Mexico", []string{"Ford"}},
+	{"WF0", "Ford Germany", []string{"Ford"}},
+	{"8AF", "Ford Argentina", []string{"Ford"}},
+	{"9BF", "Ford Brazil", []string{"Ford"}},
+	{"1ME", "Ford", []string{"Mercury"}},
+	{"2ME", "Ford Canada", []string{"Mercury"}},
+	{"4M2", "Ford", []string{"Mercury"}},
+	{"1LN", "Ford", []string{"Lincoln"}},
+	// Chrysler
+	{"1B3", "Chrysler", []string{"Dodge"}},
+	{"2B3", "Chrysler Canada", []string{"Dodge"}},
+	{"1B7", "Chrysler", []string{"Dodge"}},
+	{"1D7", "Chrysler", []string{"Dodge"}},
+	{"1C3", "Chrysler", []string{"Chrysler"}},
+	{"1C4", "Chrysler", []string{"Chrysler", "Dodge", "Jeep"}},
+	{"1J4", "Chrysler", []string{"Jeep"}},
+	{"1J8", "Chrysler", []string{"Jeep"}},
+	{"1P3", "Chrysler", []string{"Plymouth"}},
+	{"2P4", "Chrysler Canada", []string{"Plymouth"}},
+	{"2E3", "Chrysler Canada", []string{"Eagle"}},
+	// Asia
+	{"JT2", "Toyota", []string{"Toyota"}},
+	{"JTD", "Toyota", []string{"Toyota"}},
+	{"JTE", "Toyota", []string{"Toyota"}},
+	{"JTM", "Toyota", []string{"Toyota"}},
+	{"4T1", "Toyota", []string{"Toyota"}},
+	{"5TD", "Toyota", []string{"Toyota"}},
+	{"5TF", "Toyota", []string{"Toyota"}},
+	{"8AJ", "Toyota Argentina", []string{"Toyota"}},
+	{"JTH", "Toyota", []string{"Lexus"}},
+	{"JTJ", "Toyota", []string{"Lexus"}},
+	{"2T2", "Toyota Canada", []string{"Lexus"}},
+	{"JHM", "Honda", []string{"Honda"}},
+	{"1HG", "Honda", []string{"Honda"}},
+	{"2HG", "Honda Canada", []string{"Honda"}},
+	{"5FN", "Honda", []string{"Honda"}},
+	{"JH4", "Honda", []string{"Acura"}},
+	{"19U", "Honda", []string{"Acura"}},
+	{"2HN", "Honda Canada", []string{"Acura"}},
+	{"JN1", "Nissan", []string{"Nissan"}},
+	{"JN8", "Nissan", []string{"Nissan"}},
+	{"1N4", "Nissan", []string{"Nissan"}},
+	{"JNK", "Nissan", []string{"Infiniti"}},
+	{"JNR", "Nissan", []string{"Infiniti"}},
+	{"JM1", "Mazda", []string{"Mazda"}},
+	{"JM3", "Mazda", []string{"Mazda"}},
+	{"JA3", "Mitsubishi", []string{"Mitsubishi"}},
+	{"JA4", "Mitsubishi", []string{"Mitsubishi"}},
+	{"4A3", "Mitsubishi", []string{"Mitsubishi"}},
+	{"JF1", "Subaru", []string{"Subaru"}},
+	{"JF2", "Subaru", []string{"Subaru"}},
+	{"4S3", "Subaru", []string{"Subaru"}},
+	{"JS2", "Suzuki", []string{"Suzuki"}},
+	{"JS3", "Suzuki", []string{"Suzuki"}},
+	{"JAA", "Isuzu", []string{"Isuzu"}},
+	{"JAC", "Isuzu", []string{"Isuzu"}},
+	{"4S2", "Isuzu", []string{"Isuzu"}},
+	{"KMH", "Hyundai", []string{"Hyundai"}},
+	{"KM8", "Hyundai", []string{"Hyundai"}},
+	{"5NP", "Hyundai", []string{"Hyundai"}},
+	{"KNA", "Kia", []string{"Kia"}},
+	{"KND", "Kia", []string{"Kia"}},
+	{"5XY", "Kia", []string{"Kia"}},
+	// Europe
+	{"WVW", "Volkswagen", []string{"Volkswagen"}},
+	{"WVG", "Volkswagen", []string{"Volkswagen"}},
+	{"WV1", "Volkswagen", []string{"Volkswagen"}},
+	{"3VW", "Volkswagen Mexico", []string{"Volkswagen"}},
+	{"8AW", "Volkswagen Argentina", []string{"Volkswagen"}},
+	{"9BW", "Volkswagen Brazil", []string{"Volkswagen"}},
+	{"WAU", "Audi", []string{"Audi"}},
+	{"WA1", "Audi", []string{"Audi"}},
+	{"WUA", "Audi", []string{"Audi"}},
+	{"WP0", "Porsche", []string{"Porsche"}},
+	{"WP1", "Porsche", []string{"Porsche"}},
+	{"WBA", "BMW", []string{"BMW"}},
+	{"WBS", "BMW", []string{"BMW"}},
+	{"5UX", "BMW", []string{"BMW"}},
+	{"4US", "BMW", []string{"BMW"}},
+	{"WDB", "Mercedes-Benz", []string{"Mercedes-Benz"}},
+	{"WDD", "Mercedes-Benz", []string{"Mercedes-Benz"}},
+	{"WDC", "Mercedes-Benz", []string{"Mercedes-Benz"}},
+	{"W1K", "Mercedes-Benz", []string{"Mercedes-Benz"}},
+	{"WD3", "Mercedes-Benz", []string{"Mercedes-Benz"}},
+	{"4JG", "Mercedes-Benz", []string{"Mercedes-Benz"}},
+	{"8AB", "Mercedes-Benz Argentina", []string{"Mercedes-Benz"}},
+	{"ZFA", "Fiat", []string{"Fiat"}},
+	{"8AP", "Fiat Argentina", []string{"Fiat"}},
+	{"9BD", "Fiat Brazil", []string{"Fiat"}},
+	{"VF1", "Renault", []string{"Renault"}},
+	{"8A1", "Renault Argentina", []string{"Renault"}},
+	{"VF3", "Peugeot", []string{"Peugeot"}},
+	{"8AD", "Peugeot Argentina", []string{"Peugeot"}},
+	{"ZFF", "Ferrari", []string{"Ferrari"}},
+	{"ZHW", "Lamborghini", []string{"Lamborghini"}},
+	{"ZAM", "Maserati", []string{"Maserati"}},
+	{"ZN6", "Maserati", []string{"Maserati"}},
+	{"SCF", "Aston Martin", []string{"Aston Martin"}},
+	{"SCB", "Bentley", []string{"Bentley"}},
+	{"SCA", "Rolls-Royce", []string{"Rolls-Royce"}},
+	{"SAL", "Land Rover", []string{"Land Rover"}},
+	{"SAJ", "Jaguar", []string{"Jaguar"}},
+	{"YS3", "Saab", []string{"Saab"}},
+	{"YV1", "Volvo", []string{"Volvo"}},
+	{"YV4", "Volvo", []string{"Volvo"}},
+	// Tesla
+	{"5YJ", "Tesla", []string{"Tesla"}},
+	{"7SA", "Tesla", []string{"Tesla"}},
+}
+
+// manufacturers are the known WMIs by WMI
+var manufacturers = func() map[string]manufacturer {
+	m := make(map[string]manufacturer, len(wmis))
+	for _, w := range wmis {
+		m[w.wmi] = w
+	}
+	return m
+}()